@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -15,6 +17,11 @@ type IdleCommandOptions struct {
 		Name string `long:"name" env:"NAME" description:"idling resource name to idle"`
 	} `positional-args:"yes" required:"1"`
 	Namespace string `long:"namespace" env:"NAMESPACE" short:"n" description:"IdlingResource namespace"`
+	Group     bool   `long:"group" short:"g" description:"the name is the name of an IdlingGroup"`
+
+	For   time.Duration `long:"for" description:"idle for the given duration, e.g. 2h, then return to the scheduled state"`
+	Until string        `long:"until" description:"idle until the given RFC 3339 time or date, e.g. 2021-10-11, then return to the scheduled state"`
+	Force bool          `long:"force" description:"override the current state even if it has not expired yet"`
 }
 
 // Idle executes the kidlectl idle command with given args
@@ -26,10 +33,25 @@ func Idle(opts IdleCommandOptions) {
 	}
 	logf.Log.V(0).Info("idling", "namespace", kidle.Namespace, "name", opts.Args.Name)
 
-	done, err := kidle.ApplyDesiredIdleState(true, &types.NamespacedName{
+	expiration, err := pkg.ParseExpiration(opts.For, opts.Until, time.Now())
+	if err != nil {
+		logf.Log.Error(err, "invalid expiration")
+		os.Exit(1)
+	}
+
+	apply := kidle.ApplyDesiredIdleState
+	if opts.Group {
+		apply = kidle.ApplyDesiredGroupIdleState
+	}
+	done, err := apply(true, pkg.IdleStateOptions{Expiration: expiration, Force: opts.Force}, &types.NamespacedName{
 		Namespace: kidle.Namespace,
 		Name:      opts.Args.Name,
 	})
+	var notExpired *pkg.NotExpiredError
+	if errors.As(err, &notExpired) {
+		logf.Log.V(0).Info("skipped until the expiration of the current state", "namespace", kidle.Namespace, "name", opts.Args.Name, "expiration", notExpired.Expiration)
+		return
+	}
 	if err != nil {
 		logf.Log.Error(err, "unable to idle")
 		os.Exit(3)
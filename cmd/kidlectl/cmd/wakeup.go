@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -15,6 +18,14 @@ type WakeupCommandOptions struct {
 		Name string `long:"name" env:"NAME" description:"idling resource name to wakeup"`
 	} `positional-args:"yes" required:"1"`
 	Namespace string `long:"namespace" env:"NAMESPACE" short:"n" description:"IdlingResource namespace"`
+	Group     bool   `long:"group" short:"g" description:"the name is the name of an IdlingGroup"`
+
+	For   time.Duration `long:"for" description:"wake up for the given duration, e.g. 2h, then return to the scheduled state"`
+	Until string        `long:"until" description:"wake up until the given RFC 3339 time or date, e.g. 2021-10-11, then return to the scheduled state"`
+	Force bool          `long:"force" description:"override the current state even if it has not expired yet"`
+
+	Wait    bool          `long:"wait" description:"wait until all the replicas of the workloads are ready"`
+	Timeout time.Duration `long:"timeout" default:"10m" description:"the maximum duration to wait for the workloads to be ready"`
 }
 
 // Wakeup executes the kidlectl wakeup command with given args
@@ -26,10 +37,26 @@ func Wakeup(opts WakeupCommandOptions) {
 	}
 	logf.Log.V(0).Info("waking up", "namespace", kidle.Namespace, "name", opts.Args.Name)
 
-	done, err := kidle.ApplyDesiredIdleState(false, &types.NamespacedName{
+	expiration, err := pkg.ParseExpiration(opts.For, opts.Until, time.Now())
+	if err != nil {
+		logf.Log.Error(err, "invalid expiration")
+		os.Exit(1)
+	}
+
+	apply := kidle.ApplyDesiredIdleState
+	if opts.Group {
+		apply = kidle.ApplyDesiredGroupIdleState
+	}
+	req := &types.NamespacedName{
 		Namespace: kidle.Namespace,
 		Name:      opts.Args.Name,
-	})
+	}
+	done, err := apply(false, pkg.IdleStateOptions{Expiration: expiration, Force: opts.Force}, req)
+	var notExpired *pkg.NotExpiredError
+	if errors.As(err, &notExpired) {
+		logf.Log.V(0).Info("skipped until the expiration of the current state", "namespace", kidle.Namespace, "name", opts.Args.Name, "expiration", notExpired.Expiration)
+		return
+	}
 	if err != nil {
 		logf.Log.Error(err, "unable to wake up")
 		os.Exit(3)
@@ -40,4 +67,16 @@ func Wakeup(opts WakeupCommandOptions) {
 	} else {
 		logf.Log.V(0).Info("already woke up", "namespace", kidle.Namespace, "name", opts.Args.Name)
 	}
+
+	if opts.Wait {
+		logf.Log.V(0).Info("waiting for the workloads to be ready", "namespace", kidle.Namespace, "name", opts.Args.Name, "timeout", opts.Timeout)
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		err := kidle.WaitForWakeup(ctx, opts.Group, req, 2*time.Second)
+		cancel()
+		if err != nil {
+			logf.Log.Error(err, "the workloads are not ready")
+			os.Exit(4)
+		}
+		logf.Log.V(0).Info("ready", "namespace", kidle.Namespace, "name", opts.Args.Name)
+	}
 }
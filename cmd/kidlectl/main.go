@@ -12,8 +12,8 @@ import (
 // Options are the cli main options for go-flags
 type Options struct {
 	Kubeconfig string                    `long:"kubeconfig" env:"KUBECONFIG" description:"path to Kubernetes config file"`
-	IdleCmd    cmd.IdleCommandOptions    `command:"idle" alias:"i" description:"idle the referenced object of an IdlingResource"`
-	WakeUpCmd  cmd.WakeupCommandOptions  `command:"wakeup" alias:"w" description:"wakeup the referenced object of an IdlingResource"`
+	IdleCmd    cmd.IdleCommandOptions    `command:"idle" alias:"i" description:"idle the referenced object of an IdlingResource or the workloads of an IdlingGroup"`
+	WakeUpCmd  cmd.WakeupCommandOptions  `command:"wakeup" alias:"w" description:"wakeup the referenced object of an IdlingResource or the workloads of an IdlingGroup"`
 	CreateCmd  cmd.CreateCommandOptions  `command:"create" alias:"c" description:"create an IdlingResource"`
 	VersionCmd cmd.VersionCommandOptions `command:"version" description:"show the kidle version information"`
 }
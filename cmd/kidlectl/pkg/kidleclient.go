@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -22,7 +25,7 @@ import (
 // KidleClient defines the client
 type KidleClient struct {
 	client.Client
-	DiscoveryClient *discovery.DiscoveryClient
+	DiscoveryClient discovery.DiscoveryInterface
 	Namespace       string
 }
 
@@ -148,8 +151,76 @@ func getAllowedPrefixes(resourceList *metav1.APIResourceList, allowedPrefixes []
 	return allowedPrefixes, nil
 }
 
+// IdleStateOptions are the options of a change of the idle state
+type IdleStateOptions struct {
+	// Expiration is the time when the idle state expires, nil if it does not expire
+	Expiration *time.Time
+
+	// Force changes the idle state even if the current idle state has not expired yet
+	Force bool
+}
+
+// NotExpiredError is returned when the current idle state has not expired yet
+type NotExpiredError struct {
+	Expiration time.Time
+}
+
+func (e *NotExpiredError) Error() string {
+	return fmt.Sprintf("the idle state expires at %s", e.Expiration.Format(time.RFC3339))
+}
+
+// ParseExpiration returns the expiration time of an idle state lasting for the given duration or until the given time.
+// The time is either a RFC 3339 time or a date in the local time zone.
+// It returns nil if neither the duration nor the time are set.
+func ParseExpiration(duration time.Duration, until string, now time.Time) (*time.Time, error) {
+	switch {
+	case duration != 0 && until != "":
+		return nil, fmt.Errorf("the duration and the time of the expiration are exclusive")
+	case duration < 0:
+		return nil, fmt.Errorf("invalid negative duration %s", duration)
+	case duration > 0:
+		expiration := now.Add(duration)
+		return &expiration, nil
+	case until == "":
+		return nil, nil
+	}
+
+	expiration, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		if expiration, err = time.ParseInLocation("2006-01-02", until, time.Local); err != nil {
+			return nil, fmt.Errorf("invalid time %q, expected a RFC 3339 time or a date: %v", until, err)
+		}
+	}
+	if !expiration.After(now) {
+		return nil, fmt.Errorf("the time %s is in the past", until)
+	}
+	return &expiration, nil
+}
+
+// applyIdleState applies the desired idle state and its expiration on the spec of an IdlingResource or an IdlingGroup.
+// A change without expiration, e.g. by a cron strategy, does not override an idle state which has not expired yet.
+// It returns true if the spec has been changed.
+func applyIdleState(specIdle *bool, specExpiration **metav1.Time, idle bool, opts IdleStateOptions) (bool, error) {
+	if opts.Expiration == nil {
+		// nothing to do if current state == desired state
+		if *specIdle == idle {
+			return false, nil
+		}
+		if current := *specExpiration; !opts.Force && current != nil && time.Now().Before(current.Time) {
+			return false, &NotExpiredError{Expiration: current.Time}
+		}
+	}
+
+	*specIdle = idle
+	*specExpiration = nil
+	if opts.Expiration != nil {
+		*specExpiration = &metav1.Time{Time: *opts.Expiration}
+	}
+	return true, nil
+}
+
 // ApplyDesiredIdleState make sure that the referenced object has the proper idling state
-func (k *KidleClient) ApplyDesiredIdleState(idle bool, req *client.ObjectKey) (bool, error) {
+func (k *KidleClient) ApplyDesiredIdleState(idle bool, opts IdleStateOptions, req *client.ObjectKey) (bool, error) {
 
 	ctx := context.Background()
 
@@ -160,13 +231,11 @@ func (k *KidleClient) ApplyDesiredIdleState(idle bool, req *client.ObjectKey) (b
 		return false, fmt.Errorf("unable to get idlingresource: %v", err)
 	}
 
-	// nothing to do if current state == desired state
-	if ir.Spec.Idle == idle {
-		return false, nil
-	}
-
 	// update idle flag to desired state
-	ir.Spec.Idle = idle
+	changed, err := applyIdleState(&ir.Spec.Idle, &ir.Spec.IdleExpirationTime, idle, opts)
+	if !changed || err != nil {
+		return false, err
+	}
 
 	err = k.Update(ctx, &ir)
 	if err != nil {
@@ -175,6 +244,62 @@ func (k *KidleClient) ApplyDesiredIdleState(idle bool, req *client.ObjectKey) (b
 	return true, nil
 }
 
+// ApplyDesiredGroupIdleState make sure that the workloads of an IdlingGroup have the proper idling state
+func (k *KidleClient) ApplyDesiredGroupIdleState(idle bool, opts IdleStateOptions, req *client.ObjectKey) (bool, error) {
+
+	ctx := context.Background()
+
+	// get the IdlingGroup from the req
+	group := kidlev1beta1.IdlingGroup{}
+	err := k.Get(ctx, *req, &group)
+	if err != nil {
+		return false, fmt.Errorf("unable to get idlinggroup: %v", err)
+	}
+
+	// update idle flag to desired state
+	changed, err := applyIdleState(&group.Spec.Idle, &group.Spec.IdleExpirationTime, idle, opts)
+	if !changed || err != nil {
+		return false, err
+	}
+
+	err = k.Update(ctx, &group)
+	if err != nil {
+		return false, fmt.Errorf("unable to update idlinggroup: %v", err)
+	}
+	return true, nil
+}
+
+// WaitForWakeup waits until the workloads of an IdlingResource, or of an IdlingGroup, are waked up and ready.
+// It returns an error if the operator reports that the wakeup failed or if the context is done before.
+func (k *KidleClient) WaitForWakeup(ctx context.Context, group bool, req *client.ObjectKey, interval time.Duration) error {
+	return wait.PollImmediateUntil(interval, func() (bool, error) {
+		if group {
+			ig := kidlev1beta1.IdlingGroup{}
+			if err := k.Get(ctx, *req, &ig); err != nil {
+				return false, fmt.Errorf("unable to get idlinggroup: %v", err)
+			}
+			return isWakeupDone(ig.Generation, ig.Status.ObservedGeneration, ig.Status.Phase, ig.Status.Conditions)
+		}
+
+		ir := kidlev1beta1.IdlingResource{}
+		if err := k.Get(ctx, *req, &ir); err != nil {
+			return false, fmt.Errorf("unable to get idlingresource: %v", err)
+		}
+		return isWakeupDone(ir.Generation, ir.Status.ObservedGeneration, ir.Status.Phase, ir.Status.Conditions)
+	}, ctx.Done())
+}
+
+// isWakeupDone returns true once the operator reports the workloads as active and ready for the current generation
+func isWakeupDone(generation int64, observedGeneration int64, phase kidlev1beta1.IdlingResourcePhase, conditions []metav1.Condition) (bool, error) {
+	if observedGeneration < generation {
+		return false, nil
+	}
+	if failed := meta.FindStatusCondition(conditions, kidlev1beta1.ConditionWakeupFailed); failed != nil && failed.Status == metav1.ConditionTrue {
+		return false, fmt.Errorf("the wakeup failed: %s", failed.Message)
+	}
+	return phase == kidlev1beta1.PhaseActive && meta.IsStatusConditionTrue(conditions, kidlev1beta1.ConditionReady), nil
+}
+
 // CreateIdlingResource creates an IdlingResource with the given values
 func (k *KidleClient) CreateIdlingResource(idle bool, ref string, req *client.ObjectKey) (bool, error) {
 	ctx := context.Background()
@@ -195,6 +320,10 @@ func (k *KidleClient) CreateIdlingResource(idle bool, ref string, req *client.Ob
 	if !ok {
 		return false, fmt.Errorf(fmt.Sprintf("invalid resource kind; got `%s` expected one of: %v", kind, printAllowedResources(resources)))
 	}
+	gvk, err := resolveKind(k.DiscoveryClient, kind)
+	if err != nil {
+		return false, fmt.Errorf("unable to resolve resource kind `%s`; %s", kind, err.Error())
+	}
 
 	// build the IdlingResource
 	ir := kidlev1beta1.IdlingResource{
@@ -207,9 +336,9 @@ func (k *KidleClient) CreateIdlingResource(idle bool, ref string, req *client.Ob
 		},
 		Spec: kidlev1beta1.IdlingResourceSpec{
 			IdlingResourceRef: kidlev1beta1.CrossVersionObjectReference{
-				Kind:       values[0],
+				Kind:       gvk.Kind,
 				Name:       values[1],
-				APIVersion: "apps/v1",
+				APIVersion: gvk.GroupVersion().String(),
 			},
 			Idle: idle,
 		},
@@ -223,6 +352,29 @@ func (k *KidleClient) CreateIdlingResource(idle bool, ref string, req *client.Ob
 	return true, nil
 }
 
+// resolveKind returns the group, version and kind of an allowed resource given by its name, singular name or short name.
+// The version preferred by the cluster is used, e.g. batch/v1 for the cronjobs from Kubernetes 1.21
+func resolveKind(d discovery.DiscoveryInterface, resource string) (schema.GroupVersionKind, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(d)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDiscoveryRESTMapper(groupResources), d)
+
+	gvrs, err := mapper.ResourcesFor(schema.GroupVersionResource{Resource: resource})
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	for _, gvr := range gvrs {
+		for _, allowed := range AllowedGVK {
+			if gvr.Group == allowed.Group && gvr.Version == allowed.Version && gvr.Resource == allowed.Kind {
+				return mapper.KindFor(gvr)
+			}
+		}
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("no allowed resource matches %s", resource)
+}
+
 // print the allowed resources in alphabetical order
 func printAllowedResources(resources map[string]bool) string {
 	var keys []string
@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"context"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CreateIdlingResource", func() {
+	var k *KidleClient
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(kidlev1beta1.AddToScheme(s)).To(Succeed())
+		k = &KidleClient{
+			Client: fake.NewClientBuilder().WithScheme(s).Build(),
+			DiscoveryClient: &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+				{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+					{Name: "deployments", Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}},
+					{Name: "statefulsets", Kind: "StatefulSet", Namespaced: true, ShortNames: []string{"sts"}},
+				}},
+				{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{
+					{Name: "cronjobs", Kind: "CronJob", Namespaced: true, ShortNames: []string{"cj"}},
+				}},
+				{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{
+					{Name: "cronjobs", Kind: "CronJob", Namespaced: true, ShortNames: []string{"cj"}},
+				}},
+			}}},
+			Namespace: "default",
+		}
+	})
+
+	DescribeTable("resolves the kind and apiVersion of the reference",
+		func(ref string, kind string, apiVersion string) {
+			key := client.ObjectKey{Namespace: "default", Name: "ir"}
+			Expect(k.CreateIdlingResource(true, ref, &key)).To(BeTrue())
+
+			ir := &kidlev1beta1.IdlingResource{}
+			Expect(k.Get(context.Background(), key, ir)).To(Succeed())
+			Expect(ir.Spec.IdlingResourceRef).To(Equal(kidlev1beta1.CrossVersionObjectReference{
+				Kind:       kind,
+				Name:       "foo",
+				APIVersion: apiVersion,
+			}))
+		},
+		Entry("short name", "deploy/foo", "Deployment", "apps/v1"),
+		Entry("singular name", "statefulset/foo", "StatefulSet", "apps/v1"),
+		Entry("plural name of the preferred version", "cronjobs/foo", "CronJob", "batch/v1"),
+	)
+
+	It("rejects a resource which can't be idled", func() {
+		_, err := k.CreateIdlingResource(true, "pods/foo", &client.ObjectKey{Namespace: "default", Name: "ir"})
+		Expect(err).To(MatchError(ContainSubstring("invalid resource kind")))
+	})
+})
@@ -19,20 +19,32 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
+
+	// Embed the IANA time zone database to evaluate the time zones of the cron strategies
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/kidle-dev/kidle/pkg/activator"
+	kidlev1 "github.com/kidle-dev/kidle/pkg/api/v1"
 	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
 	"github.com/kidle-dev/kidle/pkg/controllers"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"github.com/kidle-dev/kidle/pkg/webhooks"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -45,6 +57,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(kidlev1beta1.AddToScheme(scheme))
+	utilruntime.Must(kidlev1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -53,12 +66,24 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var kidlectlImage string
+	var prometheusAddress string
+	var activatorService string
+	var activatorPorts string
+	var pricingConfigMap string
+	var notificationNamespace string
+	var operatorNamespace string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&kidlectlImage, "kidlectl-image", "kidledev/kidlectl:main", "Kidlectl image name and tag.")
+	flag.StringVar(&prometheusAddress, "prometheus-address", "", "The default Prometheus address used by the inactive strategies.")
+	flag.StringVar(&activatorService, "activator-service", "", "The namespace/name of the activator service used by the on call strategies.")
+	flag.StringVar(&activatorPorts, "activator-ports", activator.DefaultPortRange.String(), "The first-last range of the ports of the activator allocated to the Services routed by the on call strategies.")
+	flag.StringVar(&pricingConfigMap, "pricing-configmap", "", "The namespace/name of the ConfigMap of the prices used to estimate the cost saved by the idlings.")
+	flag.StringVar(&notificationNamespace, "notification-namespace", "", "The namespace of the NotificationSinks notified of the transitions of all the namespaces.")
+	flag.StringVar(&operatorNamespace, "operator-namespace", os.Getenv("POD_NAMESPACE"), "The namespace of the operator, never idled by the ClusterIdlingPolicies. Defaults to the POD_NAMESPACE environment variable.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -67,6 +92,32 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	var activatorKey types.NamespacedName
+	if activatorService != "" {
+		parts := strings.SplitN(activatorService, "/", 2)
+		if len(parts) != 2 {
+			setupLog.Error(nil, "invalid activator service, expected namespace/name", "activator-service", activatorService)
+			os.Exit(1)
+		}
+		activatorKey = types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+	}
+
+	ports, err := activator.ParsePortRange(activatorPorts)
+	if err != nil {
+		setupLog.Error(err, "invalid activator ports", "activator-ports", activatorPorts)
+		os.Exit(1)
+	}
+
+	var pricing types.NamespacedName
+	if pricingConfigMap != "" {
+		parts := strings.SplitN(pricingConfigMap, "/", 2)
+		if len(parts) != 2 {
+			setupLog.Error(nil, "invalid pricing configmap, expected namespace/name", "pricing-configmap", pricingConfigMap)
+			os.Exit(1)
+		}
+		pricing = types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -80,16 +131,112 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The CronJobs are idled and created with the API version served by the cluster
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create the discovery client")
+		os.Exit(1)
+	}
+	cronJobVersion, err := k8s.CronJobGroupVersion(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to discover the API version of the CronJobs")
+		os.Exit(1)
+	}
+	setupLog.Info("using the CronJobs API version", "apiVersion", cronJobVersion.String())
+	cronJobTimeZone, err := k8s.CronJobTimeZoneSupported(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to discover the time zone support of the CronJobs")
+		os.Exit(1)
+	}
+	idler.MustRegister(idler.CronJobRegistration(cronJobVersion))
+
+	// The HorizontalPodAutoscalers are patched with the API version served by the cluster
+	idler.AutoscalerGroupVersion, err = k8s.HorizontalPodAutoscalerGroupVersion(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to discover the API version of the HorizontalPodAutoscalers")
+		os.Exit(1)
+	}
+	setupLog.Info("using the HorizontalPodAutoscalers API version", "apiVersion", idler.AutoscalerGroupVersion.String())
+
+	// The KEDA ScaledObjects are only idled when KEDA is installed
+	kedaInstalled, err := k8s.IsServed(discoveryClient, idler.ScaledObjectGroupVersionResource)
+	if err != nil {
+		setupLog.Error(err, "unable to discover the KEDA ScaledObjects")
+		os.Exit(1)
+	}
+	if kedaInstalled {
+		setupLog.Info("idling the KEDA ScaledObjects")
+		idler.MustRegister(idler.ScaledObjectRegistration())
+	}
+
+	// The KubeVirt VirtualMachines are only idled when KubeVirt is installed
+	kubevirtInstalled, err := k8s.IsServed(discoveryClient, idler.VirtualMachineGroupVersionResource)
+	if err != nil {
+		setupLog.Error(err, "unable to discover the KubeVirt VirtualMachines")
+		os.Exit(1)
+	}
+	if kubevirtInstalled {
+		setupLog.Info("idling the KubeVirt VirtualMachines")
+		idler.MustRegister(idler.VirtualMachineRegistration())
+	}
+
+	scaleClient, err := idler.NewScalesGetter(mgr.GetConfig(), mgr.GetRESTMapper())
+	if err != nil {
+		setupLog.Error(err, "unable to create the scale client")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.IdlingResourceReconciler{
-		Client:        mgr.GetClient(),
-		Log:           ctrl.Log.WithName("controllers").WithName("IdlingResource"),
-		Scheme:        mgr.GetScheme(),
-		EventRecorder: mgr.GetEventRecorderFor("idlingresource-controller"),
-		KidlectlImage: kidlectlImage,
+		Client:                mgr.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("IdlingResource"),
+		Scheme:                mgr.GetScheme(),
+		EventRecorder:         mgr.GetEventRecorderFor("idlingresource-controller"),
+		KidlectlImage:         kidlectlImage,
+		PrometheusAddress:     prometheusAddress,
+		ActivatorService:      activatorKey,
+		ActivatorPorts:        ports,
+		PricingConfigMap:      pricing,
+		ScaleClient:           scaleClient,
+		CronJobVersion:        cronJobVersion,
+		CronJobTimeZone:       cronJobTimeZone,
+		NotificationNamespace: notificationNamespace,
+		APIReader:             mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "IdlingResource")
 		os.Exit(1)
 	}
+	if err = (&controllers.IdlingGroupReconciler{
+		Client:          mgr.GetClient(),
+		Log:             ctrl.Log.WithName("controllers").WithName("IdlingGroup"),
+		Scheme:          mgr.GetScheme(),
+		EventRecorder:   mgr.GetEventRecorderFor("idlinggroup-controller"),
+		KidlectlImage:   kidlectlImage,
+		CronJobVersion:  cronJobVersion,
+		CronJobTimeZone: cronJobTimeZone,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "IdlingGroup")
+		os.Exit(1)
+	}
+	if err = (&controllers.ClusterIdlingPolicyReconciler{
+		Client:            mgr.GetClient(),
+		Log:               ctrl.Log.WithName("controllers").WithName("ClusterIdlingPolicy"),
+		Scheme:            mgr.GetScheme(),
+		EventRecorder:     mgr.GetEventRecorderFor("clusteridlingpolicy-controller"),
+		OperatorNamespace: operatorNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterIdlingPolicy")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		mgr.GetWebhookServer().Register(webhooks.ValidateIdlingResourcePath, &webhook.Admission{
+			Handler: &webhooks.IdlingResourceValidator{Client: mgr.GetClient()},
+		})
+		// Serves the conversion webhook between the versions of IdlingResource
+		if err = ctrl.NewWebhookManagedBy(mgr).For(&kidlev1beta1.IdlingResource{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "IdlingResource")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
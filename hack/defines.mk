@@ -11,6 +11,7 @@ SHELL := /bin/bash
 # Image URL to use all building/pushing image targets
 IMG_OPERATOR ?= kidledev/kidle-operator
 IMG_KIDLECTL ?= kidledev/kidlectl
+IMG_ACTIVATOR ?= kidledev/kidle-activator
 
 # Defines some commons environment variables
 PROJECT_DIR := $(shell dirname $(shell dirname $(abspath $(lastword $(MAKEFILE_LIST)))))
@@ -111,6 +112,7 @@ help: ## Display this help.
 env: ## Display Makefile environment variables
 	@echo IMG_OPERATOR=$(IMG_OPERATOR)
 	@echo IMG_KIDLECTL=$(IMG_KIDLECTL)
+	@echo IMG_ACTIVATOR=$(IMG_ACTIVATOR)
 	@echo TAG=$(TAG)
 	@echo GOBIN=$(GOBIN)
 	@echo GOARCH=$(GOARCH)
@@ -0,0 +1,344 @@
+package activator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultTimeout is the default time to wait for a workload to wake up
+	DefaultTimeout = 2 * time.Minute
+
+	// DefaultPollInterval is the default interval between two readiness checks of a workload
+	DefaultPollInterval = 500 * time.Millisecond
+)
+
+var (
+	// DefaultPortRange is the default range of the ports of the activator allocated to the routed services
+	DefaultPortRange = PortRange{First: 8100, Last: 8199}
+
+	// ErrServiceNotFound is returned when no routed service is allocated the port of the request
+	ErrServiceNotFound = errors.New("service not found")
+
+	// ErrTimeout is returned when the workload is not ready before the timeout
+	ErrTimeout = errors.New("timeout waiting for the workload to wake up")
+)
+
+// PortRange is a range of ports of the activator. Each service routed to the activator is allocated a port of the range,
+// so that the activator finds the service from the destination of the requests, whatever their host.
+type PortRange struct {
+	First int32
+	Last  int32
+}
+
+// ParsePortRange parses a range of ports written first-last
+func ParsePortRange(s string) (PortRange, error) {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return PortRange{}, fmt.Errorf("invalid port range %q, expected first-last", s)
+	}
+	first, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %v", s, err)
+	}
+	last, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %v", s, err)
+	}
+	if first <= 0 || last > 65535 || first > last {
+		return PortRange{}, fmt.Errorf("invalid port range %q", s)
+	}
+	return PortRange{First: int32(first), Last: int32(last)}, nil
+}
+
+// Contains returns true if the port belongs to the range
+func (p PortRange) Contains(port int32) bool {
+	return port >= p.First && port <= p.Last
+}
+
+func (p PortRange) String() string {
+	return fmt.Sprintf("%d-%d", p.First, p.Last)
+}
+
+// Activator holds the requests sent to idled workloads, wakes them up
+// then forwards the requests once the workloads are ready.
+type Activator struct {
+	client.Client
+	Log          logr.Logger
+	PollInterval time.Duration
+
+	mu      sync.Mutex
+	wakeups map[types.NamespacedName]*wakeup
+	// routes are the last services resolved on each port, the requests may still reach
+	// the activator for a while once a service is restored and its port released
+	routes map[int32]types.NamespacedName
+}
+
+// wakeup is a wakeup in progress shared by the concurrent requests on a service
+type wakeup struct {
+	done    chan struct{}
+	targets []*url.URL
+	err     error
+}
+
+// NewActivator creates an Activator
+func NewActivator(c client.Client, log logr.Logger) *Activator {
+	return &Activator{
+		Client:       c,
+		Log:          log,
+		PollInterval: DefaultPollInterval,
+		wakeups:      map[types.NamespacedName]*wakeup{},
+		routes:       map[int32]types.NamespacedName{},
+	}
+}
+
+// ServeHTTP wakes up the workload behind the requested service and forwards the request to it.
+// The service is the one allocated the port of the activator receiving the request.
+func (a *Activator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := a.Log.WithValues("host", r.Host)
+
+	port, err := localPort(r)
+	if err != nil {
+		log.Error(err, "unable to find the destination port")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	svc, err := a.resolveService(r.Context(), port)
+	if err != nil {
+		log.Error(err, "unable to resolve service")
+		if errors.Is(err, ErrServiceNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+		return
+	}
+
+	targets, err := a.wakeup(r.Context(), svc)
+	if err != nil {
+		log.Error(err, "unable to wake up", "service", client.ObjectKeyFromObject(svc))
+		if errors.Is(err, ErrTimeout) {
+			http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+		return
+	}
+
+	// Balance the requests across the ready endpoints
+	target := targets[rand.Intn(len(targets))]
+	log.V(1).Info("forwarding request", "target", target)
+	httputil.NewSingleHostReverseProxy(target).ServeHTTP(w, r)
+}
+
+// localPort returns the port of the activator receiving a request
+func localPort(r *http.Request) (int32, error) {
+	addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		return 0, fmt.Errorf("unknown local address")
+	}
+	_, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return 0, fmt.Errorf("invalid local address %s: %v", addr, err)
+	}
+	p, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid local address %s: %v", addr, err)
+	}
+	return int32(p), nil
+}
+
+// resolveService finds the routed service allocated a port of the activator
+func (a *Activator) resolveService(ctx context.Context, port int32) (*corev1.Service, error) {
+	services := &corev1.ServiceList{}
+	if err := a.List(ctx, services); err != nil {
+		return nil, fmt.Errorf("unable to list services: %v", err)
+	}
+	var found *corev1.Service
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if value, _ := k8s.GetAnnotation(svc, kidlev1beta1.MetadataActivatorPort); value != strconv.Itoa(int(port)) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("port %d is allocated to %s and %s", port, client.ObjectKeyFromObject(found), client.ObjectKeyFromObject(svc))
+		}
+		found = svc
+	}
+	if found != nil {
+		a.mu.Lock()
+		a.routes[port] = client.ObjectKeyFromObject(found)
+		a.mu.Unlock()
+		return found, nil
+	}
+
+	// The port may have been released by a service restored in the meantime
+	a.mu.Lock()
+	key, routed := a.routes[port]
+	a.mu.Unlock()
+	if !routed {
+		return nil, fmt.Errorf("%w: no service routed on port %d", ErrServiceNotFound, port)
+	}
+	svc := &corev1.Service{}
+	if err := a.Get(ctx, key, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: no service routed on port %d", ErrServiceNotFound, port)
+		}
+		return nil, fmt.Errorf("unable to get service %s: %v", key, err)
+	}
+	return svc, nil
+}
+
+// wakeup wakes up the workload behind the service and returns the addresses of its ready endpoints.
+// Concurrent requests on the same service share the same wakeup.
+func (a *Activator) wakeup(ctx context.Context, svc *corev1.Service) ([]*url.URL, error) {
+	key := client.ObjectKeyFromObject(svc)
+
+	for {
+		a.mu.Lock()
+		w, found := a.wakeups[key]
+		if !found {
+			w = &wakeup{done: make(chan struct{})}
+			a.wakeups[key] = w
+			go func() {
+				w.targets, w.err = a.doWakeup(ctx, svc)
+				a.mu.Lock()
+				delete(a.wakeups, key)
+				a.mu.Unlock()
+				close(w.done)
+			}()
+		}
+		a.mu.Unlock()
+
+		select {
+		case <-w.done:
+			// The wakeup is bound to the request which started it,
+			// the requests still waiting start a new one if it was canceled
+			if errors.Is(w.err, context.Canceled) && ctx.Err() == nil {
+				continue
+			}
+			return w.targets, w.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// doWakeup unsets the idle flag of the IdlingResource then waits for a ready endpoint.
+// It gives up when the context of the request is done.
+func (a *Activator) doWakeup(ctx context.Context, svc *corev1.Service) ([]*url.URL, error) {
+	ref, found := k8s.GetAnnotation(svc, kidlev1beta1.MetadataIdlingResourceReference)
+	if !found {
+		// The service is not routed anymore, the workload may already be awake
+		return a.waitForEndpoint(ctx, svc, "", DefaultTimeout)
+	}
+
+	apiCtx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	key := types.NamespacedName{Namespace: svc.Namespace, Name: ref}
+	ir := &kidlev1beta1.IdlingResource{}
+	if err := a.Get(apiCtx, key, ir); err != nil {
+		return nil, fmt.Errorf("unable to get idling resource %s: %v", key, err)
+	}
+
+	port, timeout := "", DefaultTimeout
+	if strategy := ir.Spec.WakeupStrategy; strategy != nil && strategy.OnCallStrategy != nil {
+		port = strategy.OnCallStrategy.Port
+		if t := strategy.OnCallStrategy.Timeout; t != nil && t.Duration > 0 {
+			timeout = t.Duration
+		}
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := a.Get(apiCtx, key, ir); err != nil {
+			return err
+		}
+		if !ir.Spec.Idle {
+			return nil
+		}
+		ir.Spec.Idle = false
+		return a.Update(apiCtx, ir)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to wake up idling resource %s: %v", key, err)
+	}
+	a.Log.Info("waking up", "idlingresource", key)
+
+	return a.waitForEndpoint(ctx, svc, port, timeout)
+}
+
+// waitForEndpoint waits for the service to be restored with a ready endpoint on the given port
+func (a *Activator) waitForEndpoint(ctx context.Context, svc *corev1.Service, port string, timeout time.Duration) ([]*url.URL, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var targets []*url.URL
+	err := wait.PollImmediateUntil(a.PollInterval, func() (bool, error) {
+		current := &corev1.Service{}
+		if err := a.Get(ctx, client.ObjectKeyFromObject(svc), current); err != nil {
+			return false, nil
+		}
+		if k8s.HasAnnotation(current, kidlev1beta1.MetadataPreviousSelector) {
+			return false, nil
+		}
+
+		endpoints := &corev1.Endpoints{}
+		if err := a.Get(ctx, client.ObjectKeyFromObject(svc), endpoints); err != nil {
+			return false, nil
+		}
+		svcPort, err := k8s.FindServicePort(current, port)
+		if err != nil {
+			return false, err
+		}
+		targets = findReadyEndpoints(svcPort, endpoints)
+		return len(targets) > 0, nil
+	}, ctx.Done())
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, ctx.Err()
+		}
+		return nil, ErrTimeout
+	}
+	return targets, err
+}
+
+// findReadyEndpoints returns the addresses of all the ready endpoints serving the service port
+func findReadyEndpoints(svcPort *corev1.ServicePort, endpoints *corev1.Endpoints) []*url.URL {
+	var targets []*url.URL
+	for _, subset := range endpoints.Subsets {
+		for _, port := range subset.Ports {
+			if port.Name != svcPort.Name {
+				continue
+			}
+			for _, address := range subset.Addresses {
+				targets = append(targets, &url.URL{
+					Scheme: "http",
+					Host:   net.JoinHostPort(address.IP, strconv.Itoa(int(port.Port))),
+				})
+			}
+		}
+	}
+	return targets
+}
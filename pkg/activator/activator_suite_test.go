@@ -0,0 +1,13 @@
+package activator_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestActivator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Activator Suite")
+}
@@ -0,0 +1,239 @@
+package activator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// newRoutedService returns a service routed to the port of the activator by the idling resource
+func newRoutedService(key types.NamespacedName, ir string, port int) *corev1.Service {
+	selector, _ := json.Marshal(map[string]string{"app": key.Name})
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Annotations: map[string]string{
+				kidlev1beta1.MetadataIdlingResourceReference: ir,
+				kidlev1beta1.MetadataPreviousSelector:        string(selector),
+				kidlev1beta1.MetadataActivatorPort:           strconv.Itoa(port),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+	}
+}
+
+// newOnCallIdlingResource returns an idled IdlingResource with an on call strategy
+func newOnCallIdlingResource(key types.NamespacedName, service string) *kidlev1beta1.IdlingResource {
+	return &kidlev1beta1.IdlingResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Spec: kidlev1beta1.IdlingResourceSpec{
+			IdlingResourceRef: kidlev1beta1.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       service,
+				APIVersion: "apps/v1",
+			},
+			Idle: true,
+			WakeupStrategy: &kidlev1beta1.WakeupStrategy{
+				OnCallStrategy: &kidlev1beta1.OnCallStrategy{
+					ServiceName: service,
+					Timeout:     &metav1.Duration{Duration: 2 * time.Second},
+				},
+			},
+		},
+	}
+}
+
+// restoreService simulates the operator restoring the service on a ready backend
+func restoreService(ctx context.Context, c client.Client, key types.NamespacedName, backend *httptest.Server) error {
+	host, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	if err != nil {
+		return err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return err
+	}
+
+	svc := &corev1.Service{}
+	if err := c.Get(ctx, key, svc); err != nil {
+		return err
+	}
+	svc.Annotations = nil
+	svc.Spec.Selector = map[string]string{"app": key.Name}
+	if err := c.Update(ctx, svc); err != nil {
+		return err
+	}
+
+	return c.Create(ctx, &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: host}},
+			Ports:     []corev1.EndpointPort{{Name: "http", Port: int32(p)}},
+		}},
+	})
+}
+
+var _ = Describe("Activator", func() {
+	var (
+		ctx      = context.Background()
+		irKey    = types.NamespacedName{Name: "ir-app", Namespace: "default"}
+		svcKey   = types.NamespacedName{Name: "app", Namespace: "default"}
+		backend  *httptest.Server
+		c        client.Client
+		activate *Activator
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(kidlev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newRoutedService(svcKey, irKey.Name, 8100), newOnCallIdlingResource(irKey, svcKey.Name)).
+			Build()
+
+		activate = NewActivator(c, zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		activate.PollInterval = 50 * time.Millisecond
+
+		backend = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "awake")
+		}))
+	})
+
+	AfterEach(func() {
+		backend.Close()
+	})
+
+	// serveContext sends a request bound to the context to the port of the activator and returns the response
+	serveContext := func(ctx context.Context, port int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://app.default/", nil)
+		req = req.WithContext(context.WithValue(ctx, http.LocalAddrContextKey, &net.TCPAddr{Port: port}))
+		rec := httptest.NewRecorder()
+		activate.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// serve sends a request received on the port of the activator and returns the response
+	serve := func(port int) *httptest.ResponseRecorder {
+		return serveContext(ctx, port)
+	}
+
+	It("wakes up the workload and forwards the requests", func() {
+		go func() {
+			defer GinkgoRecover()
+			Eventually(func() (bool, error) {
+				ir := &kidlev1beta1.IdlingResource{}
+				if err := c.Get(ctx, irKey, ir); err != nil {
+					return false, err
+				}
+				return ir.Spec.Idle, nil
+			}, time.Second, 50*time.Millisecond).Should(BeFalse())
+			Expect(restoreService(ctx, c, svcKey, backend)).To(Succeed())
+		}()
+
+		var wg sync.WaitGroup
+		responses := make([]*httptest.ResponseRecorder, 3)
+		for i := range responses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i] = serve(8100)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, rec := range responses {
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(Equal("awake"))
+		}
+	})
+
+	It("keeps waking up the workload when the request which started the wakeup is canceled", func() {
+		reqCtx, cancel := context.WithCancel(ctx)
+		canceled := make(chan *httptest.ResponseRecorder)
+		go func() {
+			canceled <- serveContext(reqCtx, 8100)
+		}()
+		Eventually(func() (bool, error) {
+			ir := &kidlev1beta1.IdlingResource{}
+			if err := c.Get(ctx, irKey, ir); err != nil {
+				return false, err
+			}
+			return ir.Spec.Idle, nil
+		}, time.Second, 50*time.Millisecond).Should(BeFalse())
+
+		waiting := make(chan *httptest.ResponseRecorder)
+		go func() {
+			waiting <- serve(8100)
+		}()
+		cancel()
+		Expect((<-canceled).Code).To(Equal(http.StatusBadGateway))
+
+		Expect(restoreService(ctx, c, svcKey, backend)).To(Succeed())
+		rec := <-waiting
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(Equal("awake"))
+	})
+
+	It("fails when the workload is not ready before the timeout", func() {
+		rec := serve(8100)
+		Expect(rec.Code).To(Equal(http.StatusGatewayTimeout))
+
+		ir := &kidlev1beta1.IdlingResource{}
+		Expect(c.Get(ctx, irKey, ir)).To(Succeed())
+		Expect(ir.Spec.Idle).To(BeFalse())
+	})
+
+	It("balances the requests across the ready endpoints", func() {
+		endpoints := &corev1.Endpoints{
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 9090}},
+			}, {
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.3"}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.4"}},
+				Ports:             []corev1.EndpointPort{{Name: "http", Port: 8081}},
+			}},
+		}
+		Expect(findReadyEndpoints(&corev1.ServicePort{Name: "http"}, endpoints)).To(ConsistOf(
+			&url.URL{Scheme: "http", Host: "10.0.0.1:8080"},
+			&url.URL{Scheme: "http", Host: "10.0.0.2:8080"},
+			&url.URL{Scheme: "http", Host: "10.0.0.3:8081"},
+		))
+	})
+
+	It("fails when no service is routed on the port", func() {
+		Expect(serve(8101).Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("fails when several services are routed on the port", func() {
+		Expect(c.Create(ctx, newRoutedService(types.NamespacedName{Name: "other", Namespace: "other"}, irKey.Name, 8100))).To(Succeed())
+		Expect(serve(8100).Code).To(Equal(http.StatusBadGateway))
+	})
+})
@@ -0,0 +1,215 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this IdlingResource to the hub version (v1beta1).
+func (src *IdlingResource) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*kidlev1beta1.IdlingResource)
+	if !ok {
+		return fmt.Errorf("unsupported conversion to %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.IdlingResourceRef = kidlev1beta1.CrossVersionObjectReference{
+		APIVersion: src.Spec.WorkloadRef.APIVersion,
+		Kind:       src.Spec.WorkloadRef.Kind,
+		Name:       src.Spec.WorkloadRef.Name,
+	}
+	dst.Spec.Idle = src.Spec.Idle
+	dst.Spec.IdleExpirationTime = src.Spec.IdleExpirationTime
+	dst.Spec.DriftPolicy = kidlev1beta1.DriftPolicy(src.Spec.DriftPolicy)
+	dst.Spec.DependsOn = src.Spec.DependsOn
+	dst.Spec.WakeupTimeout = src.Spec.WakeupTimeout
+
+	// The strategies are only set when one of their fields is set
+	dst.Spec.IdlingStrategy = nil
+	if src.Spec.IdleSchedule != nil || src.Spec.Inactivity != nil {
+		dst.Spec.IdlingStrategy = &kidlev1beta1.IdlingStrategy{
+			CronStrategy:     convertScheduleToCronStrategy(src.Spec.IdleSchedule),
+			InactiveStrategy: convertInactivityToInactiveStrategy(src.Spec.Inactivity),
+		}
+	}
+	dst.Spec.WakeupStrategy = nil
+	if src.Spec.WakeupSchedule != nil || src.Spec.OnCall != nil || src.Spec.HolidayCalendar != nil {
+		dst.Spec.WakeupStrategy = &kidlev1beta1.WakeupStrategy{
+			CronStrategy:    convertScheduleToCronStrategy(src.Spec.WakeupSchedule),
+			OnCallStrategy:  convertOnCallToOnCallStrategy(src.Spec.OnCall),
+			HolidayCalendar: (*kidlev1beta1.HolidayCalendarReference)(src.Spec.HolidayCalendar),
+		}
+	}
+
+	dst.Status = kidlev1beta1.IdlingResourceStatus{
+		Phase:              kidlev1beta1.IdlingResourcePhase(src.Status.Phase),
+		Conditions:         src.Status.Conditions,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		LastIdleTime:       src.Status.LastIdleTime,
+		LastWakeupTime:     src.Status.LastWakeupTime,
+		PreviousReplicas:   src.Status.PreviousReplicas,
+		InactiveSince:      src.Status.InactiveSince,
+		BlockedBy:          src.Status.BlockedBy,
+	}
+	if src.Status.LastDrift != nil {
+		dst.Status.LastDrift = &kidlev1beta1.DriftStatus{
+			Policy:             kidlev1beta1.DriftPolicy(src.Status.LastDrift.Policy),
+			DetectionTime:      src.Status.LastDrift.DetectionTime,
+			ObservedGeneration: src.Status.LastDrift.ObservedGeneration,
+			Message:            src.Status.LastDrift.Message,
+		}
+	}
+	dst.Status.Savings = convertSavingsToHubSavings(src.Status.Savings)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta1) to this version.
+// The empty strategies of v1beta1, without any strategy set, have no equivalent in v1 and are dropped.
+func (dst *IdlingResource) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*kidlev1beta1.IdlingResource)
+	if !ok {
+		return fmt.Errorf("unsupported conversion from %T", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = IdlingResourceSpec{
+		WorkloadRef: WorkloadReference{
+			APIVersion: src.Spec.IdlingResourceRef.APIVersion,
+			Kind:       src.Spec.IdlingResourceRef.Kind,
+			Name:       src.Spec.IdlingResourceRef.Name,
+		},
+		Idle:               src.Spec.Idle,
+		IdleExpirationTime: src.Spec.IdleExpirationTime,
+		DriftPolicy:        DriftPolicy(src.Spec.DriftPolicy),
+		DependsOn:          src.Spec.DependsOn,
+		WakeupTimeout:      src.Spec.WakeupTimeout,
+	}
+	if s := src.Spec.IdlingStrategy; s != nil {
+		dst.Spec.IdleSchedule = convertCronStrategyToSchedule(s.CronStrategy)
+		dst.Spec.Inactivity = convertInactiveStrategyToInactivity(s.InactiveStrategy)
+	}
+	if s := src.Spec.WakeupStrategy; s != nil {
+		dst.Spec.WakeupSchedule = convertCronStrategyToSchedule(s.CronStrategy)
+		dst.Spec.OnCall = convertOnCallStrategyToOnCall(s.OnCallStrategy)
+		dst.Spec.HolidayCalendar = (*HolidayCalendarReference)(s.HolidayCalendar)
+	}
+
+	dst.Status = IdlingResourceStatus{
+		Phase:              IdlingResourcePhase(src.Status.Phase),
+		Conditions:         src.Status.Conditions,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		LastIdleTime:       src.Status.LastIdleTime,
+		LastWakeupTime:     src.Status.LastWakeupTime,
+		PreviousReplicas:   src.Status.PreviousReplicas,
+		InactiveSince:      src.Status.InactiveSince,
+		BlockedBy:          src.Status.BlockedBy,
+	}
+	if src.Status.LastDrift != nil {
+		dst.Status.LastDrift = &DriftStatus{
+			Policy:             DriftPolicy(src.Status.LastDrift.Policy),
+			DetectionTime:      src.Status.LastDrift.DetectionTime,
+			ObservedGeneration: src.Status.LastDrift.ObservedGeneration,
+			Message:            src.Status.LastDrift.Message,
+		}
+	}
+	dst.Status.Savings = convertHubSavingsToSavings(src.Status.Savings)
+	return nil
+}
+
+func convertScheduleToCronStrategy(in *Schedule) *kidlev1beta1.CronStrategy {
+	if in == nil {
+		return nil
+	}
+	return &kidlev1beta1.CronStrategy{Schedule: in.Cron, TimeZone: in.TimeZone}
+}
+
+func convertCronStrategyToSchedule(in *kidlev1beta1.CronStrategy) *Schedule {
+	if in == nil {
+		return nil
+	}
+	return &Schedule{Cron: in.Schedule, TimeZone: in.TimeZone}
+}
+
+func convertInactivityToInactiveStrategy(in *Inactivity) *kidlev1beta1.InactiveStrategy {
+	if in == nil {
+		return nil
+	}
+	return &kidlev1beta1.InactiveStrategy{
+		PrometheusAddress: in.PrometheusAddress,
+		Query:             in.Query,
+		Threshold:         in.Threshold,
+		Duration:          in.Duration,
+		Interval:          in.Interval,
+	}
+}
+
+func convertInactiveStrategyToInactivity(in *kidlev1beta1.InactiveStrategy) *Inactivity {
+	if in == nil {
+		return nil
+	}
+	return &Inactivity{
+		PrometheusAddress: in.PrometheusAddress,
+		Query:             in.Query,
+		Threshold:         in.Threshold,
+		Duration:          in.Duration,
+		Interval:          in.Interval,
+	}
+}
+
+func convertOnCallToOnCallStrategy(in *OnCall) *kidlev1beta1.OnCallStrategy {
+	if in == nil {
+		return nil
+	}
+	return &kidlev1beta1.OnCallStrategy{ServiceName: in.ServiceName, Port: in.Port, Timeout: in.Timeout}
+}
+
+func convertOnCallStrategyToOnCall(in *kidlev1beta1.OnCallStrategy) *OnCall {
+	if in == nil {
+		return nil
+	}
+	return &OnCall{ServiceName: in.ServiceName, Port: in.Port, Timeout: in.Timeout}
+}
+
+func convertSavingsToHubSavings(in *Savings) *kidlev1beta1.Savings {
+	if in == nil {
+		return nil
+	}
+	return &kidlev1beta1.Savings{
+		IdledCPU:      in.IdledCPU,
+		IdledMemory:   in.IdledMemory,
+		CPUCoreHours:  in.CPUCoreHours,
+		MemoryGBHours: in.MemoryGBHours,
+		Cost:          in.Cost,
+	}
+}
+
+func convertHubSavingsToSavings(in *kidlev1beta1.Savings) *Savings {
+	if in == nil {
+		return nil
+	}
+	return &Savings{
+		IdledCPU:      in.IdledCPU,
+		IdledMemory:   in.IdledMemory,
+		CPUCoreHours:  in.CPUCoreHours,
+		MemoryGBHours: in.MemoryGBHours,
+		Cost:          in.Cost,
+	}
+}
@@ -0,0 +1,189 @@
+package v1
+
+import (
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+var _ = Describe("IdlingResource conversion", func() {
+	now := metav1.NewTime(time.Date(2021, 10, 4, 7, 30, 0, 0, time.UTC))
+	meta := metav1.ObjectMeta{
+		Name:        "podinfo",
+		Namespace:   "default",
+		Labels:      map[string]string{"app": "podinfo"},
+		Annotations: map[string]string{"team": "a"},
+		Generation:  3,
+		Finalizers:  []string{kidlev1beta1.IdlingResourceFinalizerName},
+	}
+	conditions := []metav1.Condition{{
+		Type:               kidlev1beta1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 3,
+		LastTransitionTime: now,
+		Reason:             "Reconciled",
+		Message:            "The workload is idled",
+	}}
+
+	It("registers the versions as convertible", func() {
+		scheme := runtime.NewScheme()
+		Expect(kidlev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(AddToScheme(scheme)).To(Succeed())
+		Expect(conversion.IsConvertible(scheme, &kidlev1beta1.IdlingResource{})).To(BeTrue())
+	})
+
+	DescribeTable("round-trips from v1 to v1beta1",
+		func(spec IdlingResourceSpec) {
+			original := &IdlingResource{
+				ObjectMeta: *meta.DeepCopy(),
+				Spec:       spec,
+				Status: IdlingResourceStatus{
+					Phase:              PhaseIdle,
+					Conditions:         conditions,
+					ObservedGeneration: 3,
+					LastIdleTime:       &now,
+					LastWakeupTime:     &now,
+					PreviousReplicas:   pointer.Int32(2),
+					InactiveSince:      &now,
+					LastDrift: &DriftStatus{
+						Policy:             DriftPolicyNotify,
+						DetectionTime:      now,
+						ObservedGeneration: 2,
+						Message:            "Deployment podinfo rescaled to 3 while idled",
+					},
+					BlockedBy: []string{"postgres"},
+					Savings: &Savings{
+						IdledCPU:      resource.NewMilliQuantity(500, resource.DecimalSI),
+						IdledMemory:   resource.NewQuantity(256*1024*1024, resource.BinarySI),
+						CPUCoreHours:  "12.500",
+						MemoryGBHours: "25.000",
+						Cost:          "0.62",
+					},
+				},
+			}
+
+			hub := &kidlev1beta1.IdlingResource{}
+			Expect(original.DeepCopy().ConvertTo(hub)).To(Succeed())
+			converted := &IdlingResource{}
+			Expect(converted.ConvertFrom(hub)).To(Succeed())
+			Expect(converted).To(Equal(original))
+		},
+		Entry("with a manual idling", IdlingResourceSpec{
+			WorkloadRef:        WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"},
+			Idle:               true,
+			IdleExpirationTime: &now,
+		}),
+		Entry("with all the strategies", IdlingResourceSpec{
+			WorkloadRef:     WorkloadReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "podinfo"},
+			IdleSchedule:    &Schedule{Cron: "0 20 * * 1-5", TimeZone: "Europe/Paris"},
+			WakeupSchedule:  &Schedule{Cron: "30 7 * * 1-5", TimeZone: "America/New_York"},
+			HolidayCalendar: &HolidayCalendarReference{Name: "holidays", Namespace: "kidle-system", Key: "fr.ics"},
+			Inactivity: &Inactivity{
+				PrometheusAddress: "http://prometheus.monitoring:9090",
+				Query:             "sum(rate(http_requests_total[5m]))",
+				Threshold:         resource.MustParse("0.5"),
+				Duration:          metav1.Duration{Duration: time.Hour},
+				Interval:          &metav1.Duration{Duration: time.Minute},
+			},
+			OnCall:        &OnCall{ServiceName: "podinfo", Port: "http", Timeout: &metav1.Duration{Duration: 2 * time.Minute}},
+			DriftPolicy:   DriftPolicyAdopt,
+			DependsOn:     []string{"postgres", "cache"},
+			WakeupTimeout: &metav1.Duration{Duration: 15 * time.Minute},
+		}),
+		Entry("with a holiday calendar only", IdlingResourceSpec{
+			WorkloadRef:     WorkloadReference{Kind: "CronJob", Name: "backup"},
+			HolidayCalendar: &HolidayCalendarReference{Name: "holidays"},
+		}),
+	)
+
+	DescribeTable("round-trips from v1beta1 to v1",
+		func(spec kidlev1beta1.IdlingResourceSpec) {
+			original := &kidlev1beta1.IdlingResource{
+				ObjectMeta: *meta.DeepCopy(),
+				Spec:       spec,
+				Status: kidlev1beta1.IdlingResourceStatus{
+					Phase:              kidlev1beta1.PhaseWakingUp,
+					Conditions:         conditions,
+					ObservedGeneration: 3,
+					LastIdleTime:       &now,
+					PreviousReplicas:   pointer.Int32(3),
+					LastDrift: &kidlev1beta1.DriftStatus{
+						Policy:        kidlev1beta1.DriftPolicyAdopt,
+						DetectionTime: now,
+					},
+					BlockedBy: []string{"front"},
+					Savings: &kidlev1beta1.Savings{
+						CPUCoreHours:  "3.000",
+						MemoryGBHours: "6.000",
+					},
+				},
+			}
+
+			spoke := &IdlingResource{}
+			Expect(spoke.ConvertFrom(original.DeepCopy())).To(Succeed())
+			converted := &kidlev1beta1.IdlingResource{}
+			Expect(spoke.ConvertTo(converted)).To(Succeed())
+			Expect(converted).To(Equal(original))
+		},
+		Entry("with a manual idling", kidlev1beta1.IdlingResourceSpec{
+			IdlingResourceRef:  kidlev1beta1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"},
+			Idle:               true,
+			IdleExpirationTime: &now,
+			DriftPolicy:        kidlev1beta1.DriftPolicyNotify,
+			DependsOn:          []string{"postgres"},
+		}),
+		Entry("with the cron strategies", kidlev1beta1.IdlingResourceSpec{
+			IdlingResourceRef: kidlev1beta1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"},
+			IdlingStrategy: &kidlev1beta1.IdlingStrategy{
+				CronStrategy: &kidlev1beta1.CronStrategy{Schedule: "0 20 * * 1-5", TimeZone: "Europe/Paris"},
+			},
+			WakeupStrategy: &kidlev1beta1.WakeupStrategy{
+				CronStrategy:    &kidlev1beta1.CronStrategy{Schedule: "30 7 * * 1-5"},
+				HolidayCalendar: &kidlev1beta1.HolidayCalendarReference{Name: "holidays", Key: "fr.ics"},
+			},
+		}),
+		Entry("with the inactive and on call strategies", kidlev1beta1.IdlingResourceSpec{
+			IdlingResourceRef: kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: "podinfo"},
+			IdlingStrategy: &kidlev1beta1.IdlingStrategy{
+				InactiveStrategy: &kidlev1beta1.InactiveStrategy{
+					Query:     "sum(rate(http_requests_total[5m]))",
+					Threshold: resource.MustParse("1"),
+					Duration:  metav1.Duration{Duration: 30 * time.Minute},
+				},
+			},
+			WakeupStrategy: &kidlev1beta1.WakeupStrategy{
+				OnCallStrategy: &kidlev1beta1.OnCallStrategy{ServiceName: "podinfo"},
+			},
+		}),
+	)
+
+	It("converts the v1beta1 fields to the v1 fields", func() {
+		hub := &kidlev1beta1.IdlingResource{
+			Spec: kidlev1beta1.IdlingResourceSpec{
+				IdlingResourceRef: kidlev1beta1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"},
+				IdlingStrategy: &kidlev1beta1.IdlingStrategy{
+					CronStrategy: &kidlev1beta1.CronStrategy{Schedule: "0 20 * * 1-5"},
+				},
+				WakeupStrategy: &kidlev1beta1.WakeupStrategy{
+					OnCallStrategy: &kidlev1beta1.OnCallStrategy{ServiceName: "podinfo"},
+				},
+			},
+		}
+
+		spoke := &IdlingResource{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.Spec).To(Equal(IdlingResourceSpec{
+			WorkloadRef:  WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"},
+			IdleSchedule: &Schedule{Cron: "0 20 * * 1-5"},
+			OnCall:       &OnCall{ServiceName: "podinfo"},
+		}))
+	})
+})
@@ -0,0 +1,300 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IdlingResourceSpec defines the desired state of IdlingResource
+type IdlingResourceSpec struct {
+	// The reference to the workload to idle
+	WorkloadRef WorkloadReference `json:"workloadRef"`
+
+	// The desired state of idling. Defaults to false.
+	// +optional
+	Idle bool `json:"idle,omitempty"`
+
+	// The time when the idle flag expires. The idle flag is then set to the state dictated by the schedules,
+	// or to its opposite state without schedules.
+	// +optional
+	IdleExpirationTime *metav1.Time `json:"idleExpirationTime,omitempty"`
+
+	// The schedule idling the workload
+	// +optional
+	IdleSchedule *Schedule `json:"idleSchedule,omitempty"`
+
+	// The schedule waking up the workload
+	// +optional
+	WakeupSchedule *Schedule `json:"wakeupSchedule,omitempty"`
+
+	// The holidays during which the wakeups of the wakeup schedule are skipped
+	// +optional
+	HolidayCalendar *HolidayCalendarReference `json:"holidayCalendar,omitempty"`
+
+	// Idles the workload when it is inactive
+	// +optional
+	Inactivity *Inactivity `json:"inactivity,omitempty"`
+
+	// Wakes up the workload on the first request sent to its Service
+	// +optional
+	OnCall *OnCall `json:"onCall,omitempty"`
+
+	// The policy applied when the idled workload is rescaled by someone else. Defaults to Enforce.
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// The names of the IdlingResources of the namespace this one depends on.
+	// The workload is waked up once the workloads of its dependencies are ready,
+	// and a dependency is idled once the workloads depending on it are idled.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// The maximum duration for all the replicas of the workload to be ready after a wakeup.
+	// The WakeupFailed condition is raised once it is exceeded. Defaults to 10m.
+	// +optional
+	WakeupTimeout *metav1.Duration `json:"wakeupTimeout,omitempty"`
+}
+
+// DriftPolicy is the reaction of the operator to an idled workload rescaled by someone else.
+// +kubebuilder:validation:Enum=Enforce;Adopt;Notify
+type DriftPolicy string
+
+const (
+	// DriftPolicyEnforce idles the workload again
+	DriftPolicyEnforce DriftPolicy = "Enforce"
+	// DriftPolicyAdopt saves the new replicas as the previous replicas and considers the workload as waked up
+	DriftPolicyAdopt DriftPolicy = "Adopt"
+	// DriftPolicyNotify only emits a warning event, the workload is left as is until the next idling
+	DriftPolicyNotify DriftPolicy = "Notify"
+)
+
+// WorkloadReference identifies the workload to idle.
+type WorkloadReference struct {
+	// API version of the workload
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the workload, e.g. Deployment
+	Kind string `json:"kind"`
+
+	// Name of the workload
+	Name string `json:"name"`
+}
+
+// Schedule is a time in Cron format.
+type Schedule struct {
+	// The schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.
+	Cron string `json:"cron"`
+
+	// The IANA time zone of the schedule, e.g. Europe/Paris. Defaults to UTC.
+	// The daylight saving time transitions of the time zone are taken into account.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// HolidayCalendarReference references an iCalendar (.ics) document stored in a ConfigMap.
+// The dates of the calendar are evaluated in the time zone of the wakeup schedule.
+type HolidayCalendarReference struct {
+	// The name of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The namespace of the ConfigMap, set it to share a calendar between namespaces.
+	// Defaults to the namespace of the object.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The key of the iCalendar document in the ConfigMap. Defaults to holidays.ics.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// Inactivity idles the workload when a Prometheus query stays under a threshold for a given duration.
+type Inactivity struct {
+	// The address of the Prometheus server, e.g. http://prometheus.monitoring:9090.
+	// Defaults to the address given to the operator.
+	// +optional
+	PrometheusAddress string `json:"prometheusAddress,omitempty"`
+
+	// The PromQL query measuring the activity of the workload.
+	// The values of a vector result are summed up, an empty result is considered as 0.
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query"`
+
+	// The activity threshold under which the workload is considered inactive.
+	Threshold resource.Quantity `json:"threshold"`
+
+	// The inactivity duration after which the workload is idled.
+	Duration metav1.Duration `json:"duration"`
+
+	// The interval between two queries. Defaults to 1m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// OnCall wakes up the workload on the first request sent to its Service.
+// While the workload is idled, the Service traffic is routed to the kidle activator.
+type OnCall struct {
+	// The name of the Service exposing the workload.
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName"`
+
+	// The name of the Service port routed to the activator. Defaults to the first port of the Service.
+	// +optional
+	Port string `json:"port,omitempty"`
+
+	// The maximum time a request is held by the activator while the workload wakes up. Defaults to 2m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// IdlingResourcePhase is a label for the idling state of an IdlingResource at the current time.
+// +kubebuilder:validation:Enum=Active;Idling;Idle;WakingUp;Error
+type IdlingResourcePhase string
+
+const (
+	// PhaseActive means that the referenced workload is running
+	PhaseActive IdlingResourcePhase = "Active"
+	// PhaseIdling means that the referenced workload is being idled
+	PhaseIdling IdlingResourcePhase = "Idling"
+	// PhaseIdle means that the referenced workload is idled
+	PhaseIdle IdlingResourcePhase = "Idle"
+	// PhaseWakingUp means that the referenced workload is being waked up, its replicas are not ready yet
+	PhaseWakingUp IdlingResourcePhase = "WakingUp"
+	// PhaseError means that the last reconciliation of the IdlingResource failed
+	PhaseError IdlingResourcePhase = "Error"
+)
+
+// IdlingResourceStatus defines the observed state of IdlingResource
+type IdlingResourceStatus struct {
+	// The current phase of the IdlingResource
+	// +optional
+	Phase IdlingResourcePhase `json:"phase,omitempty"`
+
+	// The latest available observations of the IdlingResource state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The last time the workload has been idled
+	// +optional
+	LastIdleTime *metav1.Time `json:"lastIdleTime,omitempty"`
+
+	// The last time the workload has been waked up
+	// +optional
+	LastWakeupTime *metav1.Time `json:"lastWakeupTime,omitempty"`
+
+	// The replicas saved before idling, restored on wakeup
+	// +optional
+	PreviousReplicas *int32 `json:"previousReplicas,omitempty"`
+
+	// The time since when the workload is considered inactive
+	// +optional
+	InactiveSince *metav1.Time `json:"inactiveSince,omitempty"`
+
+	// The last rescaling of the idled workload by someone else
+	// +optional
+	LastDrift *DriftStatus `json:"lastDrift,omitempty"`
+
+	// The IdlingResources blocking the wakeup (dependencies not ready yet) or the idling (dependents still running)
+	// +optional
+	BlockedBy []string `json:"blockedBy,omitempty"`
+
+	// The resources saved by idling the workload
+	// +optional
+	Savings *Savings `json:"savings,omitempty"`
+}
+
+// Savings are the resource requests of the pods removed by the idlings, multiplied by the idle durations.
+// The totals are updated on each wakeup.
+type Savings struct {
+	// The CPU requests of the pods removed by the current idling
+	// +optional
+	IdledCPU *resource.Quantity `json:"idledCPU,omitempty"`
+
+	// The memory requests of the pods removed by the current idling
+	// +optional
+	IdledMemory *resource.Quantity `json:"idledMemory,omitempty"`
+
+	// The CPU saved by the past idlings, in core-hours
+	// +optional
+	CPUCoreHours string `json:"cpuCoreHours,omitempty"`
+
+	// The memory saved by the past idlings, in GB-hours
+	// +optional
+	MemoryGBHours string `json:"memoryGBHours,omitempty"`
+
+	// The estimated cost saved by the past idlings, with the prices of the pricing ConfigMap of the operator
+	// +optional
+	Cost string `json:"cost,omitempty"`
+}
+
+// DriftStatus describes a rescaling of the idled workload and how the drift policy handled it
+type DriftStatus struct {
+	// The drift policy applied to the drift
+	Policy DriftPolicy `json:"policy"`
+
+	// The time the drift has been detected
+	DetectionTime metav1.Time `json:"detectionTime"`
+
+	// The generation of the IdlingResource when the drift has been detected
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// A human readable message describing the drift
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:resource:shortName=ir
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Idle",type="boolean",JSONPath=".spec.idle"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.workloadRef.kind"
+// +kubebuilder:printcolumn:name="Workload",type="string",JSONPath=".spec.workloadRef.name"
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.previousReplicas",priority=1
+// +kubebuilder:printcolumn:name="LastIdle",type="date",JSONPath=".status.lastIdleTime",priority=1
+// +kubebuilder:printcolumn:name="LastWakeup",type="date",JSONPath=".status.lastWakeupTime",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// IdlingResource is the Schema for the idlingresources API
+type IdlingResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IdlingResourceSpec   `json:"spec,omitempty"`
+	Status IdlingResourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IdlingResourceList contains a list of IdlingResource
+type IdlingResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IdlingResource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IdlingResource{}, &IdlingResourceList{})
+}
@@ -0,0 +1,322 @@
+// +build !ignore_autogenerated
+
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
+	*out = *in
+	in.DetectionTime.DeepCopyInto(&out.DetectionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HolidayCalendarReference) DeepCopyInto(out *HolidayCalendarReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HolidayCalendarReference.
+func (in *HolidayCalendarReference) DeepCopy() *HolidayCalendarReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HolidayCalendarReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingResource) DeepCopyInto(out *IdlingResource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingResource.
+func (in *IdlingResource) DeepCopy() *IdlingResource {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IdlingResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingResourceList) DeepCopyInto(out *IdlingResourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IdlingResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingResourceList.
+func (in *IdlingResourceList) DeepCopy() *IdlingResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingResourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IdlingResourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingResourceSpec) DeepCopyInto(out *IdlingResourceSpec) {
+	*out = *in
+	out.WorkloadRef = in.WorkloadRef
+	if in.IdleExpirationTime != nil {
+		in, out := &in.IdleExpirationTime, &out.IdleExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.IdleSchedule != nil {
+		in, out := &in.IdleSchedule, &out.IdleSchedule
+		*out = new(Schedule)
+		**out = **in
+	}
+	if in.WakeupSchedule != nil {
+		in, out := &in.WakeupSchedule, &out.WakeupSchedule
+		*out = new(Schedule)
+		**out = **in
+	}
+	if in.HolidayCalendar != nil {
+		in, out := &in.HolidayCalendar, &out.HolidayCalendar
+		*out = new(HolidayCalendarReference)
+		**out = **in
+	}
+	if in.Inactivity != nil {
+		in, out := &in.Inactivity, &out.Inactivity
+		*out = new(Inactivity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnCall != nil {
+		in, out := &in.OnCall, &out.OnCall
+		*out = new(OnCall)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WakeupTimeout != nil {
+		in, out := &in.WakeupTimeout, &out.WakeupTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingResourceSpec.
+func (in *IdlingResourceSpec) DeepCopy() *IdlingResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingResourceStatus) DeepCopyInto(out *IdlingResourceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastIdleTime != nil {
+		in, out := &in.LastIdleTime, &out.LastIdleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastWakeupTime != nil {
+		in, out := &in.LastWakeupTime, &out.LastWakeupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousReplicas != nil {
+		in, out := &in.PreviousReplicas, &out.PreviousReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InactiveSince != nil {
+		in, out := &in.InactiveSince, &out.InactiveSince
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDrift != nil {
+		in, out := &in.LastDrift, &out.LastDrift
+		*out = new(DriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Savings != nil {
+		in, out := &in.Savings, &out.Savings
+		*out = new(Savings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingResourceStatus.
+func (in *IdlingResourceStatus) DeepCopy() *IdlingResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Inactivity) DeepCopyInto(out *Inactivity) {
+	*out = *in
+	out.Threshold = in.Threshold.DeepCopy()
+	out.Duration = in.Duration
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Inactivity.
+func (in *Inactivity) DeepCopy() *Inactivity {
+	if in == nil {
+		return nil
+	}
+	out := new(Inactivity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OnCall) DeepCopyInto(out *OnCall) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnCall.
+func (in *OnCall) DeepCopy() *OnCall {
+	if in == nil {
+		return nil
+	}
+	out := new(OnCall)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Savings) DeepCopyInto(out *Savings) {
+	*out = *in
+	if in.IdledCPU != nil {
+		in, out := &in.IdledCPU, &out.IdledCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.IdledMemory != nil {
+		in, out := &in.IdledMemory, &out.IdledMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Savings.
+func (in *Savings) DeepCopy() *Savings {
+	if in == nil {
+		return nil
+	}
+	out := new(Savings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Schedule) DeepCopyInto(out *Schedule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schedule.
+func (in *Schedule) DeepCopy() *Schedule {
+	if in == nil {
+		return nil
+	}
+	out := new(Schedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadReference) DeepCopyInto(out *WorkloadReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadReference.
+func (in *WorkloadReference) DeepCopy() *WorkloadReference {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,128 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ClusterIdlingPolicies = "clusteridlingpolicies"
+
+	// LabelClusterIdlingPolicy is set on the IdlingGroups generated by a ClusterIdlingPolicy, its value is the policy name
+	LabelClusterIdlingPolicy = "kidle.kidle.dev/cluster-idling-policy"
+)
+
+// ClusterIdlingPolicySpec defines the desired state of ClusterIdlingPolicy
+type ClusterIdlingPolicySpec struct {
+	// The label selector of the namespaces to idle.
+	// All the namespaces are selected if omitted, except the kube-system, kube-public and kube-node-lease namespaces
+	// and the namespace of the operator, which are never selected.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// The label selector of the workloads to idle in the selected namespaces.
+	// All the workloads of the namespaces are selected if omitted.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Only the cron strategy is supported by a ClusterIdlingPolicy.
+	// +optional
+	IdlingStrategy *IdlingStrategy `json:"idlingStrategy,omitempty"`
+
+	// Only the cron strategy is supported by a ClusterIdlingPolicy.
+	// +optional
+	WakeupStrategy *WakeupStrategy `json:"wakeupStrategy,omitempty"`
+
+	// The maximum duration for all the replicas of the workloads to be ready after a wakeup. Defaults to 10m.
+	// +optional
+	WakeupTimeout *metav1.Duration `json:"wakeupTimeout,omitempty"`
+}
+
+// ClusterIdlingPolicyStatus defines the observed state of ClusterIdlingPolicy
+type ClusterIdlingPolicyStatus struct {
+	// The latest available observations of the ClusterIdlingPolicy state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The namespaces where an IdlingGroup is generated by the policy
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// The selected namespaces where an IdlingGroup created by the users overrides the policy
+	// +optional
+	OverriddenNamespaces []string `json:"overriddenNamespaces,omitempty"`
+
+	// The selected namespaces which are also selected by another policy taking precedence,
+	// i.e. a policy whose name comes first in alphabetical order
+	// +optional
+	ConflictingNamespaces []string `json:"conflictingNamespaces,omitempty"`
+}
+
+// +kubebuilder:resource:scope=Cluster,shortName=cip
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="IdleSchedule",type="string",JSONPath=".spec.idlingStrategy.cronStrategy.schedule"
+// +kubebuilder:printcolumn:name="WakeupSchedule",type="string",JSONPath=".spec.wakeupStrategy.cronStrategy.schedule"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterIdlingPolicy is the Schema for the clusteridlingpolicies API.
+// It generates an IdlingGroup in each selected namespace.
+type ClusterIdlingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterIdlingPolicySpec   `json:"spec,omitempty"`
+	Status ClusterIdlingPolicyStatus `json:"status,omitempty"`
+}
+
+// IdlingGroupSpecFor returns the spec of the IdlingGroups generated by the policy.
+// The idle flag is driven by the cron strategies of the generated IdlingGroup.
+func (p *ClusterIdlingPolicy) IdlingGroupSpecFor(idle bool) IdlingGroupSpec {
+	spec := IdlingGroupSpec{
+		Selector:      p.Spec.Selector.DeepCopy(),
+		Idle:          idle,
+		WakeupTimeout: p.Spec.WakeupTimeout.DeepCopy(),
+	}
+	if p.Spec.IdlingStrategy != nil {
+		spec.IdlingStrategy = &IdlingStrategy{CronStrategy: p.Spec.IdlingStrategy.CronStrategy.DeepCopy()}
+	}
+	if p.Spec.WakeupStrategy != nil {
+		spec.WakeupStrategy = &WakeupStrategy{
+			CronStrategy:    p.Spec.WakeupStrategy.CronStrategy.DeepCopy(),
+			HolidayCalendar: p.Spec.WakeupStrategy.HolidayCalendar.DeepCopy(),
+		}
+	}
+	return spec
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterIdlingPolicyList contains a list of ClusterIdlingPolicy
+type ClusterIdlingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterIdlingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterIdlingPolicy{}, &ClusterIdlingPolicyList{})
+}
@@ -0,0 +1,215 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	"github.com/kidle-dev/kidle/pkg/utils/array"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	IdlingGroups = "idlinggroups"
+
+	// IdlingGroupFinalizerName is the name of the idlinggroup finalizer
+	IdlingGroupFinalizerName = "idlinggroup.finalizers.kidle.kidle.dev"
+
+	// LabelIdlingGroup is set on the workloads managed by an IdlingGroup, its value is the IdlingGroup name
+	LabelIdlingGroup = "kidle.kidle.dev/idling-group"
+
+	// MetadataExclude excludes a workload from the IdlingGroups when set to "true"
+	MetadataExclude = "kidle.kidle.dev/exclude"
+
+	// MetadataDependsOn lists the workloads of the group a workload depends on, as comma separated Kind/name references,
+	// e.g. StatefulSet/postgres,Deployment/cache
+	MetadataDependsOn = "kidle.kidle.dev/depends-on"
+)
+
+// IdlingGroupSpec defines the desired state of IdlingGroup
+type IdlingGroupSpec struct {
+	// The label selector of the Deployments, StatefulSets and CronJobs to idle together.
+	// All the workloads of the namespace are selected if omitted.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// The desired state of idling of the selected workloads. Defaults to false.
+	// +kubebuilder:default:false
+	Idle bool `json:"idle"`
+
+	// The time when the idle flag expires. The idle flag is then set to the state dictated by the cron strategies,
+	// or to its opposite state without cron strategies.
+	// +optional
+	IdleExpirationTime *metav1.Time `json:"idleExpirationTime,omitempty"`
+
+	// Only the cron strategy is supported by an IdlingGroup.
+	// +optional
+	IdlingStrategy *IdlingStrategy `json:"idlingStrategy,omitempty"`
+
+	// Only the cron strategy is supported by an IdlingGroup.
+	// +optional
+	WakeupStrategy *WakeupStrategy `json:"wakeupStrategy,omitempty"`
+
+	// The maximum duration for all the replicas of the workloads to be ready after a wakeup.
+	// The WakeupFailed condition is raised once it is exceeded. Defaults to 10m.
+	// +optional
+	WakeupTimeout *metav1.Duration `json:"wakeupTimeout,omitempty"`
+}
+
+// IdlingGroupStatus defines the observed state of IdlingGroup
+type IdlingGroupStatus struct {
+	// The current phase of the IdlingGroup, aggregated from its workloads
+	// +optional
+	Phase IdlingResourcePhase `json:"phase,omitempty"`
+
+	// The latest available observations of the IdlingGroup state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The last time the workloads have been idled
+	// +optional
+	LastIdleTime *metav1.Time `json:"lastIdleTime,omitempty"`
+
+	// The last time the workloads have been waked up
+	// +optional
+	LastWakeupTime *metav1.Time `json:"lastWakeupTime,omitempty"`
+
+	// The number of workloads managed by the IdlingGroup
+	// +optional
+	Workloads int32 `json:"workloads"`
+
+	// The result of the reconciliation of each selected workload
+	// +optional
+	WorkloadStatuses []IdlingGroupWorkloadStatus `json:"workloadStatuses,omitempty"`
+}
+
+// IdlingGroupWorkloadState is the state of a workload selected by an IdlingGroup
+// +kubebuilder:validation:Enum=Active;Idling;Idle;WakingUp;Waiting;Excluded;Conflict;Error
+type IdlingGroupWorkloadState string
+
+const (
+	// WorkloadActive means that the workload is running
+	WorkloadActive IdlingGroupWorkloadState = "Active"
+	// WorkloadIdling means that the workload is being idled
+	WorkloadIdling IdlingGroupWorkloadState = "Idling"
+	// WorkloadIdle means that the workload is idled
+	WorkloadIdle IdlingGroupWorkloadState = "Idle"
+	// WorkloadWakingUp means that the workload is being waked up, its replicas are not ready yet
+	WorkloadWakingUp IdlingGroupWorkloadState = "WakingUp"
+	// WorkloadWaiting means that the workload waits for its dependencies before its wakeup, or for its dependents before its idling
+	WorkloadWaiting IdlingGroupWorkloadState = "Waiting"
+	// WorkloadExcluded means that the workload opted out with the kidle.kidle.dev/exclude annotation
+	WorkloadExcluded IdlingGroupWorkloadState = "Excluded"
+	// WorkloadConflict means that the workload is already managed by an IdlingResource or another IdlingGroup
+	WorkloadConflict IdlingGroupWorkloadState = "Conflict"
+	// WorkloadError means that the reconciliation of the workload failed
+	WorkloadError IdlingGroupWorkloadState = "Error"
+)
+
+// IdlingGroupWorkloadStatus is the result of the reconciliation of a workload selected by an IdlingGroup
+type IdlingGroupWorkloadStatus struct {
+	// The reference of the workload
+	Ref CrossVersionObjectReference `json:"ref"`
+
+	// The state of the workload
+	State IdlingGroupWorkloadState `json:"state"`
+
+	// The replicas saved before idling, restored on wakeup
+	// +optional
+	PreviousReplicas *int32 `json:"previousReplicas,omitempty"`
+
+	// The workloads of the group blocking the wakeup or the idling of the workload, as Kind/name references
+	// +optional
+	BlockedBy []string `json:"blockedBy,omitempty"`
+
+	// A human readable message about the state of the workload
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:resource:shortName=ig
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Idle",type="boolean",JSONPath=".spec.idle"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Workloads",type="integer",JSONPath=".status.workloads"
+// +kubebuilder:printcolumn:name="LastIdle",type="date",JSONPath=".status.lastIdleTime",priority=1
+// +kubebuilder:printcolumn:name="LastWakeup",type="date",JSONPath=".status.lastWakeupTime",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// IdlingGroup is the Schema for the idlinggroups API
+type IdlingGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IdlingGroupSpec   `json:"spec,omitempty"`
+	Status IdlingGroupStatus `json:"status,omitempty"`
+}
+
+// IsBeingDeleted returns true if a deletion timestamp is set
+func (g *IdlingGroup) IsBeingDeleted() bool {
+	return !g.ObjectMeta.DeletionTimestamp.IsZero()
+}
+
+// HasFinalizer returns true if the item has the specified finalizer
+func (g *IdlingGroup) HasFinalizer(finalizerName string) bool {
+	return array.ContainsString(g.Finalizers, finalizerName)
+}
+
+// IsExcluded returns true if the workload opted out of the IdlingGroups
+func IsExcluded(workload metav1.Object) bool {
+	return workload.GetAnnotations()[MetadataExclude] == "true"
+}
+
+// GetWakeupTimeout returns the wakeup timeout of the IdlingGroup, 10m by default
+func (g *IdlingGroup) GetWakeupTimeout() time.Duration {
+	if g.Spec.WakeupTimeout == nil {
+		return DefaultWakeupTimeout
+	}
+	return g.Spec.WakeupTimeout.Duration
+}
+
+// IdlingResourceFor returns the desired state of a workload of the group as an IdlingResource
+func (g *IdlingGroup) IdlingResourceFor(ref CrossVersionObjectReference) *IdlingResource {
+	return &IdlingResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      g.Name,
+			Namespace: g.Namespace,
+		},
+		Spec: IdlingResourceSpec{
+			IdlingResourceRef: ref,
+			Idle:              g.Spec.Idle,
+		},
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// IdlingGroupList contains a list of IdlingGroup
+type IdlingGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IdlingGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IdlingGroup{}, &IdlingGroupList{})
+}
@@ -15,7 +15,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	"time"
+
 	"github.com/kidle-dev/kidle/pkg/utils/array"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +36,46 @@ const (
 
 	// TODO
 	MetadataExpectedState = "kidle.kidle.dev/expected-state"
+
+	// MetadataCronJobAPIVersion is the API version of the CronJobs created for the cron strategies, e.g. batch/v1.
+	// The CronJobs created with another API version are migrated to the API version served by the cluster.
+	MetadataCronJobAPIVersion = "kidle.kidle.dev/cronjob-api-version"
+
+	// MetadataAutoscaler is the name of the HorizontalPodAutoscaler of the workload neutralised while idled
+	MetadataAutoscaler = "kidle.kidle.dev/autoscaler"
+
+	// MetadataAutoscalerMinReplicas is the min replicas of the HorizontalPodAutoscaler saved while idled, empty if unset
+	MetadataAutoscalerMinReplicas = "kidle.kidle.dev/autoscaler-min-replicas"
+
+	// MetadataAutoscalerMaxReplicas is the max replicas of the HorizontalPodAutoscaler saved while idled
+	MetadataAutoscalerMaxReplicas = "kidle.kidle.dev/autoscaler-max-replicas"
+
+	// MetadataPreviousNodeSelector is the nodeSelector of a DaemonSet saved while idled, encoded in JSON
+	MetadataPreviousNodeSelector = "kidle.kidle.dev/previous-node-selector"
+
+	// MetadataPreviousPausedReplicas is the paused-replicas annotation of a KEDA ScaledObject paused by its user, saved while idled
+	MetadataPreviousPausedReplicas = "kidle.kidle.dev/previous-paused-replicas"
+
+	// MetadataPreviousRunStrategy is the runStrategy of a KubeVirt VirtualMachine saved while idled
+	MetadataPreviousRunStrategy = "kidle.kidle.dev/previous-run-strategy"
+
+	// IdledNodeSelector is the node label selected by the idled DaemonSets, no node is expected to have it
+	IdledNodeSelector = "kidle.kidle.dev/idled"
+
+	// MetadataPreviousSelector is the Service selector saved while its traffic is routed to the activator
+	MetadataPreviousSelector = "kidle.kidle.dev/previous-selector"
+
+	// MetadataActivatorPort is the port of the activator allocated to a Service while its traffic is routed to the activator
+	MetadataActivatorPort = "kidle.kidle.dev/activator-port"
+
+	// MetadataLastSkippedWakeup is the last scheduled wakeup skipped during a holiday, set on the wakeup CronJob
+	MetadataLastSkippedWakeup = "kidle.kidle.dev/last-skipped-wakeup"
+
+	// DefaultHolidayCalendarKey is the default key of the iCalendar document in the holiday calendar ConfigMap
+	DefaultHolidayCalendarKey = "holidays.ics"
+
+	// DefaultWakeupTimeout is the default maximum duration for a waked up workload to be ready
+	DefaultWakeupTimeout = 10 * time.Minute
 )
 
 // IdlingResourceSpec defines the desired state of IdlingResource
@@ -47,13 +90,46 @@ type IdlingResourceSpec struct {
 	// +kubebuilder:default:false
 	Idle bool `json:"idle"`
 
+	// The time when the idle flag expires. The idle flag is then set to the state dictated by the cron strategies,
+	// or to its opposite state without cron strategies.
+	// +optional
+	IdleExpirationTime *metav1.Time `json:"idleExpirationTime,omitempty"`
+
 	// +optional
 	IdlingStrategy *IdlingStrategy `json:"idlingStrategy,omitempty"`
 
 	// +optional
 	WakeupStrategy *WakeupStrategy `json:"wakeupStrategy,omitempty"`
+
+	// The policy applied when the idled workload is rescaled by someone else. Defaults to Enforce.
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// The names of the IdlingResources of the namespace this one depends on.
+	// The workload is waked up once the workloads of its dependencies are ready,
+	// and a dependency is idled once the workloads depending on it are idled.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// The maximum duration for all the replicas of the workload to be ready after a wakeup.
+	// The WakeupFailed condition is raised once it is exceeded. Defaults to 10m.
+	// +optional
+	WakeupTimeout *metav1.Duration `json:"wakeupTimeout,omitempty"`
 }
 
+// DriftPolicy is the reaction of the operator to an idled workload rescaled by someone else.
+// +kubebuilder:validation:Enum=Enforce;Adopt;Notify
+type DriftPolicy string
+
+const (
+	// DriftPolicyEnforce idles the workload again
+	DriftPolicyEnforce DriftPolicy = "Enforce"
+	// DriftPolicyAdopt saves the new replicas as the previous replicas and considers the workload as waked up
+	DriftPolicyAdopt DriftPolicy = "Adopt"
+	// DriftPolicyNotify only emits a warning event, the workload is left as is until the next idling
+	DriftPolicyNotify DriftPolicy = "Notify"
+)
+
 // CrossVersionObjectReference contains enough information to let you identify the referred resource.
 type CrossVersionObjectReference struct {
 	// Kind of the referent; More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds"
@@ -78,9 +154,34 @@ type IdlingStrategy struct {
 type CronStrategy struct {
 	// The schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.
 	Schedule string `json:"schedule"`
+
+	// The IANA time zone of the schedule, e.g. Europe/Paris. Defaults to UTC.
+	// The daylight saving time transitions of the time zone are taken into account.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
+// InactiveStrategy idles the workload when a Prometheus query stays under a threshold for a given duration.
 type InactiveStrategy struct {
+	// The address of the Prometheus server, e.g. http://prometheus.monitoring:9090.
+	// Defaults to the address given to the operator.
+	// +optional
+	PrometheusAddress string `json:"prometheusAddress,omitempty"`
+
+	// The PromQL query measuring the activity of the workload.
+	// The values of a vector result are summed up, an empty result is considered as 0.
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query"`
+
+	// The activity threshold under which the workload is considered inactive.
+	Threshold resource.Quantity `json:"threshold"`
+
+	// The inactivity duration after which the workload is idled.
+	Duration metav1.Duration `json:"duration"`
+
+	// The interval between two queries. Defaults to 1m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
 }
 
 type WakeupStrategy struct {
@@ -89,23 +190,175 @@ type WakeupStrategy struct {
 
 	// +optional
 	OnCallStrategy *OnCallStrategy `json:"onCallStrategy,omitempty"`
+
+	// The holidays during which the scheduled wakeups of the cron strategy are skipped.
+	// +optional
+	HolidayCalendar *HolidayCalendarReference `json:"holidayCalendar,omitempty"`
 }
 
+// HolidayCalendarReference references an iCalendar (.ics) document stored in a ConfigMap.
+// The dates of the calendar are evaluated in the time zone of the wakeup cron strategy.
+type HolidayCalendarReference struct {
+	// The name of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The namespace of the ConfigMap, set it to share a calendar between namespaces.
+	// Defaults to the namespace of the object.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The key of the iCalendar document in the ConfigMap. Defaults to holidays.ics.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// OnCallStrategy wakes up the workload on the first request sent to its Service.
+// While the workload is idled, the Service traffic is routed to the kidle activator.
 type OnCallStrategy struct {
+	// The name of the Service exposing the workload.
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName"`
+
+	// The name of the Service port routed to the activator. Defaults to the first port of the Service.
+	// +optional
+	Port string `json:"port,omitempty"`
+
+	// The maximum time a request is held by the activator while the workload wakes up. Defaults to 2m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
+// IdlingResourcePhase is a label for the idling state of an IdlingResource at the current time.
+// +kubebuilder:validation:Enum=Active;Idling;Idle;WakingUp;Error
+type IdlingResourcePhase string
+
+const (
+	// PhaseActive means that the referenced workload is running
+	PhaseActive IdlingResourcePhase = "Active"
+	// PhaseIdling means that the referenced workload is being idled
+	PhaseIdling IdlingResourcePhase = "Idling"
+	// PhaseIdle means that the referenced workload is idled
+	PhaseIdle IdlingResourcePhase = "Idle"
+	// PhaseWakingUp means that the referenced workload is being waked up, its replicas are not ready yet
+	PhaseWakingUp IdlingResourcePhase = "WakingUp"
+	// PhaseError means that the last reconciliation of the IdlingResource failed
+	PhaseError IdlingResourcePhase = "Error"
+)
+
+const (
+	// ConditionReady is true when the referenced workload is in the desired idling state
+	ConditionReady = "Ready"
+	// ConditionIdled is true when the referenced workload is idled
+	ConditionIdled = "Idled"
+	// ConditionReferenceFound is true when the referenced workload exists
+	ConditionReferenceFound = "ReferenceFound"
+	// ConditionSchedulesConfigured is true when the CronJobs of the cron strategies are up to date
+	ConditionSchedulesConfigured = "SchedulesConfigured"
+	// ConditionDependenciesReady is false while the wakeup waits for the dependencies or the idling waits for the dependents
+	ConditionDependenciesReady = "DependenciesReady"
+	// ConditionWakeupFailed is true when the replicas of the waked up workload are not ready before the wakeup timeout
+	ConditionWakeupFailed = "WakeupFailed"
+)
+
 // IdlingResourceStatus defines the observed state of IdlingResource
 type IdlingResourceStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// The current phase of the IdlingResource
+	// +optional
+	Phase IdlingResourcePhase `json:"phase,omitempty"`
+
+	// The latest available observations of the IdlingResource state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The last time the referenced workload has been idled
+	// +optional
+	LastIdleTime *metav1.Time `json:"lastIdleTime,omitempty"`
+
+	// The last time the referenced workload has been waked up
+	// +optional
+	LastWakeupTime *metav1.Time `json:"lastWakeupTime,omitempty"`
+
+	// The replicas saved before idling, restored on wakeup
+	// +optional
+	PreviousReplicas *int32 `json:"previousReplicas,omitempty"`
+
+	// The time since when the workload is considered inactive by the inactive strategy
+	// +optional
+	InactiveSince *metav1.Time `json:"inactiveSince,omitempty"`
+
+	// The last rescaling of the idled workload by someone else
+	// +optional
+	LastDrift *DriftStatus `json:"lastDrift,omitempty"`
+
+	// The IdlingResources blocking the wakeup (dependencies not ready yet) or the idling (dependents still running)
+	// +optional
+	BlockedBy []string `json:"blockedBy,omitempty"`
+
+	// The resources saved by idling the referenced workload
+	// +optional
+	Savings *Savings `json:"savings,omitempty"`
+}
+
+// Savings are the resource requests of the pods removed by the idlings, multiplied by the idle durations.
+// The totals are updated on each wakeup.
+type Savings struct {
+	// The CPU requests of the pods removed by the current idling
+	// +optional
+	IdledCPU *resource.Quantity `json:"idledCPU,omitempty"`
+
+	// The memory requests of the pods removed by the current idling
+	// +optional
+	IdledMemory *resource.Quantity `json:"idledMemory,omitempty"`
+
+	// The CPU saved by the past idlings, in core-hours
+	// +optional
+	CPUCoreHours string `json:"cpuCoreHours,omitempty"`
+
+	// The memory saved by the past idlings, in GB-hours
+	// +optional
+	MemoryGBHours string `json:"memoryGBHours,omitempty"`
+
+	// The estimated cost saved by the past idlings, with the prices of the pricing ConfigMap of the operator
+	// +optional
+	Cost string `json:"cost,omitempty"`
+}
+
+// DriftStatus describes a rescaling of the idled workload and how the drift policy handled it
+type DriftStatus struct {
+	// The drift policy applied to the drift
+	Policy DriftPolicy `json:"policy"`
+
+	// The time the drift has been detected
+	DetectionTime metav1.Time `json:"detectionTime"`
+
+	// The generation of the IdlingResource when the drift has been detected
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// A human readable message describing the drift
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:resource:shortName=ir
 // +kubebuilder:object:root=true
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Idle",type="boolean",JSONPath=".spec.idle"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 // +kubebuilder:printcolumn:name="RefKind",type="string",JSONPath=".spec.idlingResourceRef.kind"
 // +kubebuilder:printcolumn:name="RefName",type="string",JSONPath=".spec.idlingResourceRef.name"
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.previousReplicas",priority=1
+// +kubebuilder:printcolumn:name="LastIdle",type="date",JSONPath=".status.lastIdleTime",priority=1
+// +kubebuilder:printcolumn:name="LastWakeup",type="date",JSONPath=".status.lastWakeupTime",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // IdlingResource is the Schema for the idlingresources API
 type IdlingResource struct {
@@ -136,6 +389,22 @@ func (ss *IdlingResource) RemoveFinalizer(finalizerName string) {
 	ss.ObjectMeta.Finalizers = array.RemoveString(ss.ObjectMeta.Finalizers, finalizerName)
 }
 
+// GetDriftPolicy returns the drift policy of the IdlingResource, Enforce by default
+func (ss *IdlingResource) GetDriftPolicy() DriftPolicy {
+	if ss.Spec.DriftPolicy == "" {
+		return DriftPolicyEnforce
+	}
+	return ss.Spec.DriftPolicy
+}
+
+// GetWakeupTimeout returns the wakeup timeout of the IdlingResource, 10m by default
+func (ss *IdlingResource) GetWakeupTimeout() time.Duration {
+	if ss.Spec.WakeupTimeout == nil {
+		return DefaultWakeupTimeout
+	}
+	return ss.Spec.WakeupTimeout.Duration
+}
+
 // +kubebuilder:object:root=true
 
 // IdlingResourceList contains a list of IdlingResource
@@ -0,0 +1,115 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	NotificationSinks = "notificationsinks"
+)
+
+// NotificationSinkType is the format of the notifications sent to a sink
+// +kubebuilder:validation:Enum=Webhook;Slack;Teams
+type NotificationSinkType string
+
+const (
+	// NotificationSinkWebhook posts the notifications as generic JSON documents
+	NotificationSinkWebhook NotificationSinkType = "Webhook"
+	// NotificationSinkSlack posts the notifications to a Slack incoming webhook
+	NotificationSinkSlack NotificationSinkType = "Slack"
+	// NotificationSinkTeams posts the notifications to a Microsoft Teams incoming webhook
+	NotificationSinkTeams NotificationSinkType = "Teams"
+)
+
+// NotificationEvent is a transition of a workload notified to the sinks
+// +kubebuilder:validation:Enum=Idled;WakedUp;IdleFailed;WakeupFailed
+type NotificationEvent string
+
+const (
+	// NotificationIdled is sent when a workload is idled
+	NotificationIdled NotificationEvent = "Idled"
+	// NotificationWakedUp is sent when a workload is waked up
+	NotificationWakedUp NotificationEvent = "WakedUp"
+	// NotificationIdleFailed is sent when a workload cannot be idled
+	NotificationIdleFailed NotificationEvent = "IdleFailed"
+	// NotificationWakeupFailed is sent when a workload cannot be waked up
+	NotificationWakeupFailed NotificationEvent = "WakeupFailed"
+)
+
+// NotificationSinkSpec defines the desired state of NotificationSink
+type NotificationSinkSpec struct {
+	// The format of the notifications: Webhook for a generic JSON document, Slack or Teams
+	Type NotificationSinkType `json:"type"`
+
+	// The URL the notifications are posted to
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// The key of a Secret of the namespace of the sink holding the URL, e.g. a Slack or Teams incoming webhook URL.
+	// It takes precedence over the url field.
+	// +optional
+	URLSecretRef *corev1.SecretKeySelector `json:"urlSecretRef,omitempty"`
+
+	// The key of a Secret of the namespace of the sink holding the value of the Authorization header
+	// +optional
+	AuthorizationSecretRef *corev1.SecretKeySelector `json:"authorizationSecretRef,omitempty"`
+
+	// The notified events, all of them if omitted
+	// +optional
+	Events []NotificationEvent `json:"events,omitempty"`
+}
+
+// Notifies returns true if the sink is interested in an event
+func (s *NotificationSinkSpec) Notifies(event NotificationEvent) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// NotificationSink is the Schema for the notificationsinks API.
+// The transitions of the workloads of its namespace are notified to the sink,
+// the sinks of the notification namespace of the operator are notified of the transitions of all the namespaces.
+type NotificationSink struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NotificationSinkSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NotificationSinkList contains a list of NotificationSink
+type NotificationSinkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationSink `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationSink{}, &NotificationSinkList{})
+}
@@ -21,9 +21,147 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIdlingPolicy) DeepCopyInto(out *ClusterIdlingPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIdlingPolicy.
+func (in *ClusterIdlingPolicy) DeepCopy() *ClusterIdlingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIdlingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterIdlingPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIdlingPolicyList) DeepCopyInto(out *ClusterIdlingPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterIdlingPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIdlingPolicyList.
+func (in *ClusterIdlingPolicyList) DeepCopy() *ClusterIdlingPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIdlingPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterIdlingPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIdlingPolicySpec) DeepCopyInto(out *ClusterIdlingPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IdlingStrategy != nil {
+		in, out := &in.IdlingStrategy, &out.IdlingStrategy
+		*out = new(IdlingStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WakeupStrategy != nil {
+		in, out := &in.WakeupStrategy, &out.WakeupStrategy
+		*out = new(WakeupStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WakeupTimeout != nil {
+		in, out := &in.WakeupTimeout, &out.WakeupTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIdlingPolicySpec.
+func (in *ClusterIdlingPolicySpec) DeepCopy() *ClusterIdlingPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIdlingPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIdlingPolicyStatus) DeepCopyInto(out *ClusterIdlingPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OverriddenNamespaces != nil {
+		in, out := &in.OverriddenNamespaces, &out.OverriddenNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConflictingNamespaces != nil {
+		in, out := &in.ConflictingNamespaces, &out.ConflictingNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIdlingPolicyStatus.
+func (in *ClusterIdlingPolicyStatus) DeepCopy() *ClusterIdlingPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIdlingPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CronStrategy) DeepCopyInto(out *CronStrategy) {
 	*out = *in
@@ -54,13 +192,205 @@ func (in *CrossVersionObjectReference) DeepCopy() *CrossVersionObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
+	*out = *in
+	in.DetectionTime.DeepCopyInto(&out.DetectionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HolidayCalendarReference) DeepCopyInto(out *HolidayCalendarReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HolidayCalendarReference.
+func (in *HolidayCalendarReference) DeepCopy() *HolidayCalendarReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HolidayCalendarReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingGroup) DeepCopyInto(out *IdlingGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingGroup.
+func (in *IdlingGroup) DeepCopy() *IdlingGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IdlingGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingGroupList) DeepCopyInto(out *IdlingGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IdlingGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingGroupList.
+func (in *IdlingGroupList) DeepCopy() *IdlingGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IdlingGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingGroupSpec) DeepCopyInto(out *IdlingGroupSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IdleExpirationTime != nil {
+		in, out := &in.IdleExpirationTime, &out.IdleExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.IdlingStrategy != nil {
+		in, out := &in.IdlingStrategy, &out.IdlingStrategy
+		*out = new(IdlingStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WakeupStrategy != nil {
+		in, out := &in.WakeupStrategy, &out.WakeupStrategy
+		*out = new(WakeupStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WakeupTimeout != nil {
+		in, out := &in.WakeupTimeout, &out.WakeupTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingGroupSpec.
+func (in *IdlingGroupSpec) DeepCopy() *IdlingGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingGroupStatus) DeepCopyInto(out *IdlingGroupStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastIdleTime != nil {
+		in, out := &in.LastIdleTime, &out.LastIdleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastWakeupTime != nil {
+		in, out := &in.LastWakeupTime, &out.LastWakeupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.WorkloadStatuses != nil {
+		in, out := &in.WorkloadStatuses, &out.WorkloadStatuses
+		*out = make([]IdlingGroupWorkloadStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingGroupStatus.
+func (in *IdlingGroupStatus) DeepCopy() *IdlingGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlingGroupWorkloadStatus) DeepCopyInto(out *IdlingGroupWorkloadStatus) {
+	*out = *in
+	out.Ref = in.Ref
+	if in.PreviousReplicas != nil {
+		in, out := &in.PreviousReplicas, &out.PreviousReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingGroupWorkloadStatus.
+func (in *IdlingGroupWorkloadStatus) DeepCopy() *IdlingGroupWorkloadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlingGroupWorkloadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IdlingResource) DeepCopyInto(out *IdlingResource) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingResource.
@@ -117,6 +447,10 @@ func (in *IdlingResourceList) DeepCopyObject() runtime.Object {
 func (in *IdlingResourceSpec) DeepCopyInto(out *IdlingResourceSpec) {
 	*out = *in
 	out.IdlingResourceRef = in.IdlingResourceRef
+	if in.IdleExpirationTime != nil {
+		in, out := &in.IdleExpirationTime, &out.IdleExpirationTime
+		*out = (*in).DeepCopy()
+	}
 	if in.IdlingStrategy != nil {
 		in, out := &in.IdlingStrategy, &out.IdlingStrategy
 		*out = new(IdlingStrategy)
@@ -127,6 +461,16 @@ func (in *IdlingResourceSpec) DeepCopyInto(out *IdlingResourceSpec) {
 		*out = new(WakeupStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WakeupTimeout != nil {
+		in, out := &in.WakeupTimeout, &out.WakeupTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingResourceSpec.
@@ -142,6 +486,45 @@ func (in *IdlingResourceSpec) DeepCopy() *IdlingResourceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IdlingResourceStatus) DeepCopyInto(out *IdlingResourceStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastIdleTime != nil {
+		in, out := &in.LastIdleTime, &out.LastIdleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastWakeupTime != nil {
+		in, out := &in.LastWakeupTime, &out.LastWakeupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousReplicas != nil {
+		in, out := &in.PreviousReplicas, &out.PreviousReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InactiveSince != nil {
+		in, out := &in.InactiveSince, &out.InactiveSince
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDrift != nil {
+		in, out := &in.LastDrift, &out.LastDrift
+		*out = new(DriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Savings != nil {
+		in, out := &in.Savings, &out.Savings
+		*out = new(Savings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlingResourceStatus.
@@ -165,7 +548,7 @@ func (in *IdlingStrategy) DeepCopyInto(out *IdlingStrategy) {
 	if in.InactiveStrategy != nil {
 		in, out := &in.InactiveStrategy, &out.InactiveStrategy
 		*out = new(InactiveStrategy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -182,6 +565,13 @@ func (in *IdlingStrategy) DeepCopy() *IdlingStrategy {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InactiveStrategy) DeepCopyInto(out *InactiveStrategy) {
 	*out = *in
+	out.Threshold = in.Threshold.DeepCopy()
+	out.Duration = in.Duration
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InactiveStrategy.
@@ -194,9 +584,102 @@ func (in *InactiveStrategy) DeepCopy() *InactiveStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSink) DeepCopyInto(out *NotificationSink) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSink.
+func (in *NotificationSink) DeepCopy() *NotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationSink) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSinkList) DeepCopyInto(out *NotificationSinkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NotificationSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSinkList.
+func (in *NotificationSinkList) DeepCopy() *NotificationSinkList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSinkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationSinkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSinkSpec) DeepCopyInto(out *NotificationSinkSpec) {
+	*out = *in
+	if in.URLSecretRef != nil {
+		in, out := &in.URLSecretRef, &out.URLSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuthorizationSecretRef != nil {
+		in, out := &in.AuthorizationSecretRef, &out.AuthorizationSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEvent, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSinkSpec.
+func (in *NotificationSinkSpec) DeepCopy() *NotificationSinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OnCallStrategy) DeepCopyInto(out *OnCallStrategy) {
 	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnCallStrategy.
@@ -209,6 +692,31 @@ func (in *OnCallStrategy) DeepCopy() *OnCallStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Savings) DeepCopyInto(out *Savings) {
+	*out = *in
+	if in.IdledCPU != nil {
+		in, out := &in.IdledCPU, &out.IdledCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.IdledMemory != nil {
+		in, out := &in.IdledMemory, &out.IdledMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Savings.
+func (in *Savings) DeepCopy() *Savings {
+	if in == nil {
+		return nil
+	}
+	out := new(Savings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WakeupStrategy) DeepCopyInto(out *WakeupStrategy) {
 	*out = *in
@@ -220,6 +728,11 @@ func (in *WakeupStrategy) DeepCopyInto(out *WakeupStrategy) {
 	if in.OnCallStrategy != nil {
 		in, out := &in.OnCallStrategy, &out.OnCallStrategy
 		*out = new(OnCallStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HolidayCalendar != nil {
+		in, out := &in.HolidayCalendar, &out.HolidayCalendar
+		*out = new(HolidayCalendarReference)
 		**out = **in
 	}
 }
@@ -0,0 +1,285 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+const (
+	icalDate        = "20060102"
+	icalDateTime    = "20060102T150405"
+	icalDateTimeUTC = "20060102T150405Z"
+
+	// maxLineLength is the maximum length of an unfolded content line
+	maxLineLength = 1024 * 1024
+)
+
+// Holiday is an event of a holiday calendar, possibly recurring
+type Holiday struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+
+	// allDay is true if the event is defined with dates, its duration is counted in days
+	allDay bool
+	rule   *rrule.RRule
+}
+
+// Occurrence is a period during which a holiday occurs
+type Occurrence struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Calendar is a list of holidays parsed from an iCalendar document
+type Calendar struct {
+	Holidays []Holiday
+}
+
+// Parse parses the events of an iCalendar document (RFC 5545).
+// The dates and the floating times are evaluated in the given location.
+// Only the DTSTART, DTEND, SUMMARY and RRULE properties of the events are supported.
+func Parse(r io.Reader, location *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{}
+	var event *vevent
+	for i, line := range lines {
+		name, params, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &vevent{}
+		case name == "END" && value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: unexpected END:VEVENT", i+1)
+			}
+			holiday, err := event.toHoliday(location)
+			if err != nil {
+				return nil, fmt.Errorf("event ending line %d: %v", i+1, err)
+			}
+			calendar.Holidays = append(calendar.Holidays, *holiday)
+			event = nil
+		case event != nil:
+			event.set(name, params, value)
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("missing END:VEVENT")
+	}
+	return calendar, nil
+}
+
+// At returns the occurrence of a holiday at the given time, if any
+func (c *Calendar) At(t time.Time) (*Occurrence, bool) {
+	for i := range c.Holidays {
+		if o, found := c.Holidays[i].at(t); found {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// NextStart returns the start of the first holiday occurring after the given time.
+// It returns the zero time if no holiday occurs after the given time.
+func (c *Calendar) NextStart(t time.Time) time.Time {
+	var next time.Time
+	for i := range c.Holidays {
+		start := c.Holidays[i].nextStart(t)
+		if !start.IsZero() && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return next
+}
+
+// at returns the occurrence of the holiday containing the given time
+func (h *Holiday) at(t time.Time) (*Occurrence, bool) {
+	start := h.Start
+	if h.rule != nil {
+		start = h.rule.Before(t, true)
+		if start.IsZero() {
+			return nil, false
+		}
+	}
+	o := h.occurrence(start)
+	if t.Before(o.Start) || !t.Before(o.End) {
+		return nil, false
+	}
+	return o, true
+}
+
+// nextStart returns the start of the next occurrence of the holiday after the given time
+func (h *Holiday) nextStart(t time.Time) time.Time {
+	if h.rule != nil {
+		return h.rule.After(t, false)
+	}
+	if h.Start.After(t) {
+		return h.Start
+	}
+	return time.Time{}
+}
+
+// occurrence returns the occurrence of the holiday starting at the given time
+func (h *Holiday) occurrence(start time.Time) *Occurrence {
+	o := &Occurrence{Summary: h.Summary, Start: start}
+	if h.allDay {
+		// the days may last 23 or 25 hours around the daylight saving time transitions
+		days := int(h.End.Sub(h.Start).Hours()+12) / 24
+		o.End = start.AddDate(0, 0, days)
+	} else {
+		o.End = start.Add(h.End.Sub(h.Start))
+	}
+	return o
+}
+
+// vevent holds the supported properties of an event being parsed
+type vevent struct {
+	summary string
+	dtStart *property
+	dtEnd   *property
+	rrule   string
+}
+
+// property is the value and the parameters of a property
+type property struct {
+	params []string
+	value  string
+}
+
+func (e *vevent) set(name string, params []string, value string) {
+	switch name {
+	case "SUMMARY":
+		e.summary = unescape(value)
+	case "DTSTART":
+		e.dtStart = &property{params: params, value: value}
+	case "DTEND":
+		e.dtEnd = &property{params: params, value: value}
+	case "RRULE":
+		e.rrule = value
+	}
+}
+
+func (e *vevent) toHoliday(location *time.Location) (*Holiday, error) {
+	if e.dtStart == nil {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+	start, allDay, err := e.dtStart.parseTime(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DTSTART: %v", err)
+	}
+
+	holiday := &Holiday{Summary: e.summary, Start: start, End: start, allDay: allDay}
+	switch {
+	case e.dtEnd != nil:
+		end, _, err := e.dtEnd.parseTime(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DTEND: %v", err)
+		}
+		if end.Before(start) {
+			return nil, fmt.Errorf("DTEND is before DTSTART")
+		}
+		holiday.End = end
+	case allDay:
+		holiday.End = start.AddDate(0, 0, 1)
+	}
+
+	if e.rrule != "" {
+		option, err := rrule.StrToROptionInLocation(e.rrule, location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE: %v", err)
+		}
+		option.Dtstart = start
+		rule, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE: %v", err)
+		}
+		holiday.rule = rule
+	}
+	return holiday, nil
+}
+
+// parseTime parses a DATE or a DATE-TIME value.
+// It returns true if the value is a DATE.
+func (p *property) parseTime(location *time.Location) (time.Time, bool, error) {
+	for _, param := range p.params {
+		switch {
+		case param == "VALUE=DATE":
+			t, err := time.ParseInLocation(icalDate, p.value, location)
+			return t, true, err
+		case strings.HasPrefix(param, "TZID="):
+			tz := strings.Trim(strings.TrimPrefix(param, "TZID="), `"`)
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				return time.Time{}, false, fmt.Errorf("unknown time zone %q", tz)
+			}
+			location = loc
+		}
+	}
+
+	switch {
+	case len(p.value) == len(icalDate):
+		t, err := time.ParseInLocation(icalDate, p.value, location)
+		return t, true, err
+	case strings.HasSuffix(p.value, "Z"):
+		t, err := time.Parse(icalDateTimeUTC, p.value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation(icalDateTime, p.value, location)
+		return t, false, err
+	}
+}
+
+// unfold reads the content lines of an iCalendar document.
+// The long lines are folded by inserting a line break followed by a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read calendar: %v", err)
+	}
+	return lines, nil
+}
+
+// splitProperty splits a content line in its name, parameters and value
+func splitProperty(line string) (string, []string, string, error) {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ':' && !quoted:
+			parts := strings.Split(line[:i], ";")
+			return strings.ToUpper(parts[0]), parts[1:], line[i+1:], nil
+		}
+	}
+	return "", nil, "", fmt.Errorf("invalid content line %q", line)
+}
+
+// unescape unescapes a TEXT value
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,83 @@
+package calendar
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const holidays = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//kidle//holidays//EN
+BEGIN:VEVENT
+UID:christmas@kidle.dev
+DTSTART;VALUE=DATE:20211225
+DTEND;VALUE=DATE:20211226
+SUMMARY:Christmas
+RRULE:FREQ=YEARLY
+END:VEVENT
+BEGIN:VEVENT
+UID:shutdown@kidle.dev
+DTSTART;VALUE=DATE:20210809
+DTEND;VALUE=DATE:20210821
+SUMMARY:Company shutdown\, summer
+END:VEVENT
+BEGIN:VEVENT
+UID:maintenance@kidle.dev
+DTSTART;TZID=America/New_York:20211103T060000
+DTEND;TZID=America/New_York:20211103T
+ 120000
+SUMMARY:Maintenance
+END:VEVENT
+END:VCALENDAR
+`
+
+var _ = Describe("Calendar", func() {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	calendar, err := Parse(strings.NewReader(holidays), paris)
+
+	It("parses the events", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calendar.Holidays).To(HaveLen(3))
+		Expect(calendar.Holidays[1].Summary).To(Equal("Company shutdown, summer"))
+	})
+
+	DescribeTable("finds the holiday at a given time",
+		func(at string, expected string) {
+			t, err := time.Parse(time.RFC3339, at)
+			Expect(err).ToNot(HaveOccurred())
+			o, found := calendar.At(t)
+			if expected == "" {
+				Expect(found).To(BeFalse())
+				return
+			}
+			Expect(found).To(BeTrue())
+			Expect(o.Summary).To(Equal(expected))
+		},
+		Entry("on a working day", "2021-12-24T12:00:00+01:00", ""),
+		Entry("at the start of a day in the calendar time zone", "2021-12-25T00:00:00+01:00", "Christmas"),
+		Entry("before the start in the calendar time zone", "2021-12-24T23:30:00Z", "Christmas"),
+		Entry("after the end in the calendar time zone", "2021-12-25T23:30:00Z", ""),
+		Entry("on a recurrence", "2025-12-25T08:00:00+01:00", "Christmas"),
+		Entry("before the first recurrence", "2020-12-25T08:00:00+01:00", ""),
+		Entry("during a period", "2021-08-16T07:30:00+02:00", "Company shutdown, summer"),
+		Entry("after a period", "2021-08-21T07:30:00+02:00", ""),
+		Entry("during an event with a time zone", "2021-11-03T14:00:00Z", "Maintenance"),
+		Entry("after an event with a time zone", "2021-11-03T17:00:00Z", ""),
+	)
+
+	It("returns the next holiday", func() {
+		t, _ := time.Parse(time.RFC3339, "2021-11-04T00:00:00Z")
+		Expect(calendar.NextStart(t)).To(BeTemporally("==", time.Date(2021, 12, 25, 0, 0, 0, 0, paris)))
+	})
+
+	It("rejects an invalid calendar", func() {
+		_, err := Parse(strings.NewReader("BEGIN:VEVENT\nDTSTART:2021\nEND:VEVENT\n"), paris)
+		Expect(err).To(HaveOccurred())
+		_, err = Parse(strings.NewReader("BEGIN:VEVENT\nSUMMARY:no start\nEND:VEVENT\n"), paris)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("autoscaler", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx = context.Background()
+	)
+
+	It("Should neutralise the HorizontalPodAutoscaler of an idled workload", func() {
+		irKey := types.NamespacedName{Name: "ir-autoscaler", Namespace: "default"}
+		deployKey := types.NamespacedName{Name: "autoscaler", Namespace: "default"}
+
+		Expect(k8sClient.Create(ctx, newDeployment(deployKey, 3))).Should(Succeed())
+		metrics := []autoscalingv2beta2.MetricSpec{{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: corev1.ResourceMemory,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:         autoscalingv2beta2.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(100*1024*1024, resource.BinarySI),
+				},
+			},
+		}}
+		behavior := &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleDown: &autoscalingv2beta2.HPAScalingRules{StabilizationWindowSeconds: pointer.Int32(60)},
+		}
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: deployKey.Name, Namespace: deployKey.Namespace},
+			Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: deployKey.Name, APIVersion: "apps/v1"},
+				MinReplicas:    pointer.Int32(2),
+				MaxReplicas:    5,
+				Metrics:        metrics,
+				Behavior:       behavior,
+			},
+		}
+		Expect(k8sClient.Create(ctx, hpa)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		getBounds := func() ([]int32, error) {
+			if err := k8sClient.Get(ctx, deployKey, hpa); err != nil {
+				return nil, err
+			}
+			return []int32{*hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas}, nil
+		}
+
+		By("Checking that the autoscaler is pinned to 1 replica")
+		Eventually(getBounds, timeout, interval).Should(Equal([]int32{1, 1}))
+		d := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, deployKey, d)).Should(Succeed())
+		Expect(d.Spec.Replicas).Should(Equal(pointer.Int32(0)))
+		Expect(d.Annotations).Should(HaveKeyWithValue(kidlev1beta1.MetadataAutoscaler, hpa.Name))
+		Expect(d.Annotations).Should(HaveKeyWithValue(kidlev1beta1.MetadataAutoscalerMinReplicas, "2"))
+		Expect(d.Annotations).Should(HaveKeyWithValue(kidlev1beta1.MetadataAutoscalerMaxReplicas, "5"))
+
+		By("Checking that the autoscaler is restored on wakeup")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(getBounds, timeout, interval).Should(Equal([]int32{2, 5}))
+
+		By("Checking that the metrics and the behavior of the autoscaler are kept")
+		Expect(hpa.Spec.Metrics).Should(HaveLen(1))
+		Expect(hpa.Spec.Metrics[0].Resource.Name).Should(Equal(corev1.ResourceMemory))
+		Expect(hpa.Spec.Metrics[0].Resource.Target.AverageValue.Cmp(*metrics[0].Resource.Target.AverageValue)).Should(BeZero())
+		Expect(hpa.Spec.Behavior).ShouldNot(BeNil())
+		Expect(hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds).Should(Equal(pointer.Int32(60)))
+		Eventually(func() (*int32, error) {
+			if err := k8sClient.Get(ctx, deployKey, d); err != nil {
+				return nil, err
+			}
+			return d.Spec.Replicas, nil
+		}, timeout, interval).Should(Equal(pointer.Int32(2)))
+		Expect(d.Annotations).ShouldNot(HaveKey(kidlev1beta1.MetadataAutoscaler))
+	})
+})
@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/array"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// SystemNamespaces are the namespaces of the Kubernetes components, never selected by a ClusterIdlingPolicy
+var SystemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// ClusterIdlingPolicyReconciler reconciles a ClusterIdlingPolicy object
+type ClusterIdlingPolicyReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	record.EventRecorder
+
+	// OperatorNamespace is the namespace of the operator, never selected by a ClusterIdlingPolicy
+	OperatorNamespace string
+}
+
+// +kubebuilder:rbac:groups=kidle.kidle.dev,resources=clusteridlingpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kidle.kidle.dev,resources=clusteridlingpolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+func (r *ClusterIdlingPolicyReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.Log.WithValues("clusteridlingpolicy", req.Name)
+
+	log.V(1).Info("Starting reconcile loop")
+	defer log.V(1).Info("Finish reconcile loop")
+
+	// Retrieve ClusterIdlingPolicy instance
+	var instance kidlev1beta1.ClusterIdlingPolicy
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	original := instance.DeepCopy()
+
+	err := r.reconcileInstance(ctx, log, &instance)
+
+	// Report the result of the reconciliation in the status
+	if statusErr := r.updateStatus(ctx, original, &instance, err); statusErr != nil {
+		log.Error(statusErr, "unable to update status")
+		if err == nil {
+			return reconcile.Result{}, statusErr
+		}
+	}
+	return reconcile.Result{}, err
+}
+
+// reconcileInstance generates an IdlingGroup in each selected namespace,
+// unless an IdlingGroup created by the users already exists in the namespace.
+func (r *ClusterIdlingPolicyReconciler) reconcileInstance(ctx context.Context, log logr.Logger, instance *kidlev1beta1.ClusterIdlingPolicy) error {
+	// The policy is being deleted, the generated groups are garbage collected
+	if !instance.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	if instance.Spec.IdlingStrategy != nil && instance.Spec.IdlingStrategy.InactiveStrategy != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Unsupported strategy", "The inactive strategy is not supported by a ClusterIdlingPolicy")
+	}
+	if instance.Spec.WakeupStrategy != nil && instance.Spec.WakeupStrategy.OnCallStrategy != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Unsupported strategy", "The on call strategy is not supported by a ClusterIdlingPolicy")
+	}
+
+	nsSelector, err := namespaceSelector(instance)
+	if err != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Selecting namespaces", fmt.Sprintf("Invalid namespace selector: %s", err))
+		return fmt.Errorf("invalid namespace selector: %v", err)
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: nsSelector}); err != nil {
+		return fmt.Errorf("unable to list namespaces: %v", err)
+	}
+	groups := &kidlev1beta1.IdlingGroupList{}
+	if err := r.List(ctx, groups); err != nil {
+		return fmt.Errorf("unable to list idling groups: %v", err)
+	}
+	policies := &kidlev1beta1.ClusterIdlingPolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		return fmt.Errorf("unable to list cluster idling policies: %v", err)
+	}
+
+	// The groups created by the users override the policy in their namespace,
+	// the groups generated by the other policies are resolved by the name of the policies
+	generated := map[string]*kidlev1beta1.IdlingGroup{}
+	overridden := map[string]bool{}
+	for i := range groups.Items {
+		group := &groups.Items[i]
+		policy, ok := group.Labels[kidlev1beta1.LabelClusterIdlingPolicy]
+		switch {
+		case !ok:
+			overridden[group.Namespace] = true
+		case policy == instance.Name:
+			generated[group.Namespace] = group
+		}
+	}
+
+	selected := map[string]bool{}
+	conflicting := instance.Status.ConflictingNamespaces
+	instance.Status.Namespaces = nil
+	instance.Status.OverriddenNamespaces = nil
+	instance.Status.ConflictingNamespaces = nil
+	for _, ns := range namespaces.Items {
+		if ns.Status.Phase == corev1.NamespaceTerminating || r.isProtectedNamespace(ns.Name) {
+			continue
+		}
+		if overridden[ns.Name] {
+			instance.Status.OverriddenNamespaces = append(instance.Status.OverriddenNamespaces, ns.Name)
+			continue
+		}
+		if winner := precedingPolicy(instance, policies.Items, &ns); winner != "" {
+			instance.Status.ConflictingNamespaces = append(instance.Status.ConflictingNamespaces, ns.Name)
+			if !array.ContainsString(conflicting, ns.Name) {
+				r.Event(instance, corev1.EventTypeWarning, "Conflict",
+					fmt.Sprintf("Namespace %s is also selected by the ClusterIdlingPolicy %s, which takes precedence", ns.Name, winner))
+			}
+			continue
+		}
+		selected[ns.Name] = true
+		if err := r.reconcileIdlingGroup(ctx, instance, ns.Name, generated[ns.Name]); err != nil {
+			return err
+		}
+		instance.Status.Namespaces = append(instance.Status.Namespaces, ns.Name)
+	}
+	sort.Strings(instance.Status.Namespaces)
+	sort.Strings(instance.Status.OverriddenNamespaces)
+	sort.Strings(instance.Status.ConflictingNamespaces)
+
+	// Delete the groups of the namespaces which are not selected anymore or overridden
+	for ns, group := range generated {
+		if selected[ns] {
+			continue
+		}
+		if err := r.Delete(ctx, group); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to delete idling group %s/%s: %v", ns, group.Name, err)
+		}
+		log.V(1).Info("idling group deleted", "namespace", ns)
+		r.Event(instance, corev1.EventTypeNormal, "Deleted", fmt.Sprintf("IdlingGroup %s/%s is deleted", ns, group.Name))
+	}
+
+	message := fmt.Sprintf("The policy is applied in %d namespaces", len(instance.Status.Namespaces))
+	if len(instance.Status.ConflictingNamespaces) > 0 {
+		message += fmt.Sprintf(", %d namespaces are also selected by a preceding policy", len(instance.Status.ConflictingNamespaces))
+	}
+	setStatusCondition(&instance.Status.Conditions, instance.Generation, kidlev1beta1.ConditionReady, metav1.ConditionTrue, ReasonReconciled, message)
+	return nil
+}
+
+// namespaceSelector returns the selector of the namespaces of a policy, all the namespaces if it is omitted
+func namespaceSelector(policy *kidlev1beta1.ClusterIdlingPolicy) (labels.Selector, error) {
+	if policy.Spec.NamespaceSelector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+}
+
+// precedingPolicy returns the name of another policy selecting the namespace and taking precedence over the instance, if any.
+// The overlapping policies are resolved by their name in alphabetical order, whatever their creation order.
+func precedingPolicy(instance *kidlev1beta1.ClusterIdlingPolicy, policies []kidlev1beta1.ClusterIdlingPolicy, ns *corev1.Namespace) string {
+	winner := ""
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Name >= instance.Name || !policy.DeletionTimestamp.IsZero() {
+			continue
+		}
+		// A policy with an invalid selector selects no namespace
+		selector, err := namespaceSelector(policy)
+		if err != nil || !selector.Matches(labels.Set(ns.Labels)) {
+			continue
+		}
+		if winner == "" || policy.Name < winner {
+			winner = policy.Name
+		}
+	}
+	return winner
+}
+
+// isProtectedNamespace returns true for the system namespaces and the namespace of the operator,
+// whose workloads could not be waked up if they were idled
+func (r *ClusterIdlingPolicyReconciler) isProtectedNamespace(namespace string) bool {
+	return (r.OperatorNamespace != "" && namespace == r.OperatorNamespace) || array.ContainsString(SystemNamespaces, namespace)
+}
+
+// reconcileIdlingGroup creates or updates the IdlingGroup generated by the policy in a namespace.
+// The idle flag of an existing group and its expiration are kept as they are driven by its cron strategies and kidlectl.
+func (r *ClusterIdlingPolicyReconciler) reconcileIdlingGroup(ctx context.Context, instance *kidlev1beta1.ClusterIdlingPolicy, namespace string, group *kidlev1beta1.IdlingGroup) error {
+	if group == nil {
+		group = &kidlev1beta1.IdlingGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instance.Name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					kidlev1beta1.LabelClusterIdlingPolicy: instance.Name,
+				},
+			},
+			Spec: instance.IdlingGroupSpecFor(false),
+		}
+		if err := ctrl.SetControllerReference(instance, group, r.Scheme); err != nil {
+			return fmt.Errorf("unable to set controller reference: %v", err)
+		}
+		if err := r.Create(ctx, group); err != nil {
+			return fmt.Errorf("unable to create idling group in namespace %s: %v", namespace, err)
+		}
+		r.Event(instance, corev1.EventTypeNormal, "Created", fmt.Sprintf("IdlingGroup %s/%s is created", namespace, group.Name))
+		return nil
+	}
+
+	spec := instance.IdlingGroupSpecFor(group.Spec.Idle)
+	spec.IdleExpirationTime = group.Spec.IdleExpirationTime
+	if equality.Semantic.DeepEqual(group.Spec, spec) {
+		return nil
+	}
+	group.Spec = spec
+	if err := r.Update(ctx, group); err != nil {
+		return fmt.Errorf("unable to update idling group in namespace %s: %v", namespace, err)
+	}
+	r.Event(instance, corev1.EventTypeNormal, "Updated", fmt.Sprintf("IdlingGroup %s/%s is updated", namespace, group.Name))
+	return nil
+}
+
+// updateStatus patches the ClusterIdlingPolicy status if it has changed since the beginning of the reconciliation.
+// The reconciliation error, if any, is reported in the status.
+func (r *ClusterIdlingPolicyReconciler) updateStatus(ctx context.Context, original *kidlev1beta1.ClusterIdlingPolicy, instance *kidlev1beta1.ClusterIdlingPolicy, reconcileErr error) error {
+	instance.Status.ObservedGeneration = instance.Generation
+	if reconcileErr != nil {
+		setStatusCondition(&instance.Status.Conditions, instance.Generation, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonReconcileFailed, reconcileErr.Error())
+	}
+
+	if equality.Semantic.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+	if err := r.Status().Patch(ctx, instance, client.MergeFrom(original)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("unable to update clusteridlingpolicy status: %v", err)
+	}
+	return nil
+}
+
+func (r *ClusterIdlingPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kidlev1beta1.ClusterIdlingPolicy{}).
+		Watches(
+			&source.Kind{Type: &kidlev1beta1.ClusterIdlingPolicy{}},
+			handler.EnqueueRequestsFromMapFunc(r.allPoliciesMapper),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(r.allPoliciesMapper),
+		).
+		Watches(
+			&source.Kind{Type: &kidlev1beta1.IdlingGroup{}},
+			handler.EnqueueRequestsFromMapFunc(r.allPoliciesMapper),
+		).
+		Complete(r)
+}
+
+// allPoliciesMapper requests the reconciliation of all the policies.
+// A namespace, an IdlingGroup or an overlapping policy may change the namespaces selected by any policy.
+func (r *ClusterIdlingPolicyReconciler) allPoliciesMapper(_ client.Object) []reconcile.Request {
+	policies := &kidlev1beta1.ClusterIdlingPolicyList{}
+	if err := r.List(context.Background(), policies); err != nil {
+		r.Log.Error(err, "unable to list cluster idling policies")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name}})
+	}
+	return reqs
+}
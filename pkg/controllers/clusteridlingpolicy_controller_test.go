@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+var _ = Describe("ClusterIdlingPolicy", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx       = context.Background()
+		policyKey = types.NamespacedName{Name: "cip-weekdays"}
+	)
+
+	It("Should generate an IdlingGroup in each selected namespace", func() {
+		for _, name := range []string{"cip-team-a", "cip-team-b"} {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"env": "cip-dev"},
+			}}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+		}
+
+		By("Overriding the policy in a namespace")
+		Expect(k8sClient.Create(ctx, newIdlingGroup(types.NamespacedName{Name: "custom", Namespace: "cip-team-b"}, nil))).Should(Succeed())
+
+		policy := &kidlev1beta1.ClusterIdlingPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: policyKey.Name},
+			Spec: kidlev1beta1.ClusterIdlingPolicySpec{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"env": "cip-dev"},
+				},
+				IdlingStrategy: &kidlev1beta1.IdlingStrategy{
+					CronStrategy: &kidlev1beta1.CronStrategy{Schedule: "0 20 * * 1-5"},
+				},
+				WakeupStrategy: &kidlev1beta1.WakeupStrategy{
+					CronStrategy: &kidlev1beta1.CronStrategy{Schedule: "30 7 * * 1-5"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+
+		groupKey := types.NamespacedName{Name: policyKey.Name, Namespace: "cip-team-a"}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, groupKey, &kidlev1beta1.IdlingGroup{})
+		}, timeout, interval).Should(Succeed())
+
+		group := &kidlev1beta1.IdlingGroup{}
+		Expect(k8sClient.Get(ctx, groupKey, group)).Should(Succeed())
+		Expect(group.Labels).To(HaveKeyWithValue(kidlev1beta1.LabelClusterIdlingPolicy, policyKey.Name))
+		Expect(group.Spec.IdlingStrategy.CronStrategy.Schedule).To(Equal("0 20 * * 1-5"))
+		Expect(group.Spec.WakeupStrategy.CronStrategy.Schedule).To(Equal("30 7 * * 1-5"))
+
+		By("Checking the status of the policy")
+		Eventually(func() (*kidlev1beta1.ClusterIdlingPolicyStatus, error) {
+			policy := &kidlev1beta1.ClusterIdlingPolicy{}
+			if err := k8sClient.Get(ctx, policyKey, policy); err != nil {
+				return nil, err
+			}
+			return &policy.Status, nil
+		}, timeout, interval).Should(And(
+			WithTransform(func(s *kidlev1beta1.ClusterIdlingPolicyStatus) []string { return s.Namespaces }, Equal([]string{"cip-team-a"})),
+			WithTransform(func(s *kidlev1beta1.ClusterIdlingPolicyStatus) []string { return s.OverriddenNamespaces }, Equal([]string{"cip-team-b"})),
+		))
+
+		By("Unselecting the namespace")
+		Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			ns := &corev1.Namespace{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: "cip-team-a"}, ns); err != nil {
+				return err
+			}
+			ns.Labels["env"] = "prod"
+			return k8sClient.Update(ctx, ns)
+		})).Should(Succeed())
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, groupKey, &kidlev1beta1.IdlingGroup{})
+			return err != nil
+		}, timeout, interval).Should(BeTrue())
+	})
+
+	It("Should resolve the policies selecting the same namespace by their name", func() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "cip-team-c",
+			Labels: map[string]string{"env": "cip-overlap"},
+		}}
+		Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+		newPolicy := func(name string) *kidlev1beta1.ClusterIdlingPolicy {
+			return &kidlev1beta1.ClusterIdlingPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: kidlev1beta1.ClusterIdlingPolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"env": "cip-overlap"},
+					},
+				},
+			}
+		}
+		getStatus := func(name string) func() (*kidlev1beta1.ClusterIdlingPolicyStatus, error) {
+			return func() (*kidlev1beta1.ClusterIdlingPolicyStatus, error) {
+				policy := &kidlev1beta1.ClusterIdlingPolicy{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, policy); err != nil {
+					return nil, err
+				}
+				return &policy.Status, nil
+			}
+		}
+		namespaces := func(s *kidlev1beta1.ClusterIdlingPolicyStatus) []string { return s.Namespaces }
+		conflicting := func(s *kidlev1beta1.ClusterIdlingPolicyStatus) []string { return s.ConflictingNamespaces }
+
+		By("Creating the policy coming last first")
+		Expect(k8sClient.Create(ctx, newPolicy("cip-overlap-b"))).Should(Succeed())
+		Eventually(getStatus("cip-overlap-b"), timeout, interval).Should(
+			WithTransform(namespaces, Equal([]string{"cip-team-c"})))
+
+		By("Creating the policy coming first")
+		Expect(k8sClient.Create(ctx, newPolicy("cip-overlap-a"))).Should(Succeed())
+		Eventually(getStatus("cip-overlap-a"), timeout, interval).Should(
+			WithTransform(namespaces, Equal([]string{"cip-team-c"})))
+		Eventually(getStatus("cip-overlap-b"), timeout, interval).Should(And(
+			WithTransform(namespaces, BeEmpty()),
+			WithTransform(conflicting, Equal([]string{"cip-team-c"})),
+		))
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{Name: "cip-overlap-b", Namespace: "cip-team-c"}, &kidlev1beta1.IdlingGroup{})
+		}, timeout, interval).ShouldNot(Succeed())
+
+		By("Deleting the policy coming first")
+		Expect(k8sClient.Delete(ctx, newPolicy("cip-overlap-a"))).Should(Succeed())
+		Eventually(getStatus("cip-overlap-b"), timeout, interval).Should(And(
+			WithTransform(namespaces, Equal([]string{"cip-team-c"})),
+			WithTransform(conflicting, BeEmpty()),
+		))
+	})
+
+	It("Should never select the system namespaces and the namespace of the operator", func() {
+		r := &ClusterIdlingPolicyReconciler{OperatorNamespace: "kidle-system"}
+		Expect(r.isProtectedNamespace("kube-system")).To(BeTrue())
+		Expect(r.isProtectedNamespace("kube-node-lease")).To(BeTrue())
+		Expect(r.isProtectedNamespace("kidle-system")).To(BeTrue())
+		Expect(r.isProtectedNamespace("cip-team-a")).To(BeFalse())
+	})
+})
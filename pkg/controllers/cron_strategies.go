@@ -3,17 +3,23 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
+
 	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/calendar"
 	"github.com/kidle-dev/kidle/pkg/utils/k8s"
 	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	"github.com/kidle-dev/kidle/pkg/utils/schedule"
 	batchv1 "k8s.io/api/batch/v1"
-	"k8s.io/api/batch/v1beta1"
-	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -27,96 +33,185 @@ const (
 )
 
 type CronJobValues struct {
-	key          types.NamespacedName
-	instanceName string
-	strategy     *kidlev1beta1.CronStrategy
-	command      string
+	key      types.NamespacedName
+	owner    *CronOwner
+	strategy *kidlev1beta1.CronStrategy
+	command  string
+
+	// suspend is true while the scheduled runs are skipped
+	suspend                 bool
+	startingDeadlineSeconds *int64
+}
+
+// CronOwner is an object whose idling state is driven by cron strategies
+type CronOwner struct {
+	client.Object
+
+	// Resource is the kidle API resource of the owner, e.g. idlingresources
+	Resource string
+
+	// Prefix is the prefix of the names of the objects created for the owner
+	Prefix string
+
+	// Args are the kidlectl arguments following the command
+	Args []string
+
+	IdlingStrategy *kidlev1beta1.CronStrategy
+	WakeupStrategy *kidlev1beta1.CronStrategy
+
+	// HolidayCalendar lists the holidays during which the scheduled wakeups are skipped
+	HolidayCalendar *kidlev1beta1.HolidayCalendarReference
+}
+
+// CronStrategiesReconciler reconciles the CronJobs running kidlectl on schedule, and their RBAC
+type CronStrategiesReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	record.EventRecorder
+	KidlectlImage string
+
+	// CronJobVersion is the API version of the CronJobs served by the cluster, batch/v1 if empty
+	CronJobVersion schema.GroupVersion
+
+	// CronJobTimeZone is true if the cluster supports the timeZone field of the batch/v1 CronJobs.
+	// The time zone is set with a CRON_TZ prefix of the schedule otherwise.
+	CronJobTimeZone bool
 }
 
 func (r *IdlingResourceReconciler) ReconcileCronStrategies(ctx context.Context, instance *kidlev1beta1.IdlingResource) (ctrl.Result, error) {
+	owner := &CronOwner{
+		Object:   instance,
+		Resource: kidlev1beta1.IdlingResources,
+		Prefix:   "kidle",
+		Args:     []string{instance.Name},
+	}
+	if instance.Spec.IdlingStrategy != nil {
+		owner.IdlingStrategy = instance.Spec.IdlingStrategy.CronStrategy
+	}
+	if instance.Spec.WakeupStrategy != nil {
+		owner.WakeupStrategy = instance.Spec.WakeupStrategy.CronStrategy
+		owner.HolidayCalendar = instance.Spec.WakeupStrategy.HolidayCalendar
+	}
+
+	cronStrategies := &CronStrategiesReconciler{
+		Client:          r.Client,
+		Scheme:          r.Scheme,
+		EventRecorder:   r.EventRecorder,
+		KidlectlImage:   r.KidlectlImage,
+		CronJobVersion:  r.CronJobVersion,
+		CronJobTimeZone: r.CronJobTimeZone,
+	}
+	return cronStrategies.Reconcile(ctx, owner)
+}
+
+// Reconcile creates, updates or deletes the idle and wakeup CronJobs of the owner
+func (r *CronStrategiesReconciler) Reconcile(ctx context.Context, instance *CronOwner) (ctrl.Result, error) {
 	// Create dedicated RBAC for the instance
 	if err := r.createRBAC(ctx, instance); err != nil {
-		r.Event(instance, corev1.EventTypeWarning, "Adding RBAC", fmt.Sprintf("Failed to add RBAC: %s", err))
+		r.Event(instance.Object, corev1.EventTypeWarning, "Adding RBAC", fmt.Sprintf("Failed to add RBAC: %s", err))
 		return reconcile.Result{}, fmt.Errorf("error when adding RBAC: %v", err)
 	}
 
 	cjIdleKey := types.NamespacedName{
-		Namespace: instance.Namespace,
-		Name:      k8s.ToDNSName("kidle", instance.Name, CommandIdle),
+		Namespace: instance.GetNamespace(),
+		Name:      k8s.ToDNSName(instance.Prefix, instance.GetName(), CommandIdle),
 	}
 
 	// Create or update idle cronjob for the instance
-	if instance.Spec.IdlingStrategy != nil && instance.Spec.IdlingStrategy.CronStrategy != nil {
+	if instance.IdlingStrategy != nil {
 		cjIdleValues := &CronJobValues{
-			key:          cjIdleKey,
-			instanceName: instance.Name,
-			command:      CommandIdle,
-			strategy:     instance.Spec.IdlingStrategy.CronStrategy,
+			key:      cjIdleKey,
+			owner:    instance,
+			command:  CommandIdle,
+			strategy: instance.IdlingStrategy,
 		}
 		if err := r.createOrUpdateCronJob(ctx, instance, cjIdleValues); err != nil {
-			r.Event(instance, corev1.EventTypeWarning, "Creating idle CronJob", fmt.Sprintf("Failed to create CronJob: %s", err))
+			r.Event(instance.Object, corev1.EventTypeWarning, "Creating idle CronJob", fmt.Sprintf("Failed to create CronJob: %s", err))
 			return reconcile.Result{}, fmt.Errorf("error when creating idle CronJob: %v", err)
 		} else {
-			r.Event(instance, corev1.EventTypeNormal, "Creating idle CronJob", "Created")
+			r.Event(instance.Object, corev1.EventTypeNormal, "Creating idle CronJob", "Created")
 		}
 	} else {
 		// Delete the idle cronjob if necessary
-		cronJob := &v1beta1.CronJob{}
-		if err := r.Get(ctx, cjIdleKey, cronJob); err == nil {
-			if err := r.Delete(ctx, cronJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
-				r.Event(instance, corev1.EventTypeWarning, "Deleting idle CronJob", fmt.Sprintf("Failed to delete CronJob: %s", err))
-				return reconcile.Result{}, fmt.Errorf("error when deleting idle CronJob: %v", err)
-			} else {
-				r.Event(instance, corev1.EventTypeNormal, "Deleting idle CronJob", "Deleted")
-			}
+		if deleted, err := r.deleteCronJob(ctx, cjIdleKey); err != nil {
+			r.Event(instance.Object, corev1.EventTypeWarning, "Deleting idle CronJob", fmt.Sprintf("Failed to delete CronJob: %s", err))
+			return reconcile.Result{}, fmt.Errorf("error when deleting idle CronJob: %v", err)
+		} else if deleted {
+			r.Event(instance.Object, corev1.EventTypeNormal, "Deleting idle CronJob", "Deleted")
 		}
 	}
 
 	cjWakeupKey := types.NamespacedName{
-		Namespace: instance.Namespace,
-		Name:      k8s.ToDNSName("kidle", instance.Name, CommandWakeup),
+		Namespace: instance.GetNamespace(),
+		Name:      k8s.ToDNSName(instance.Prefix, instance.GetName(), CommandWakeup),
 	}
 
 	// Create wakeup cronjob RBAC for the instance
-	if instance.Spec.WakeupStrategy != nil && instance.Spec.WakeupStrategy.CronStrategy != nil {
+	var result ctrl.Result
+	if instance.WakeupStrategy != nil {
 		cjValues := &CronJobValues{
-			key:          cjWakeupKey,
-			instanceName: instance.Name,
-			command:      CommandWakeup,
-			strategy:     instance.Spec.WakeupStrategy.CronStrategy,
+			key:      cjWakeupKey,
+			owner:    instance,
+			command:  CommandWakeup,
+			strategy: instance.WakeupStrategy,
 		}
+
+		// Suspend the wakeup cronjob during the holidays
+		var holiday *calendar.Occurrence
+		if instance.HolidayCalendar != nil {
+			now := time.Now()
+			h, requeueAfter, err := r.getHoliday(ctx, instance, now)
+			if err != nil {
+				r.Event(instance.Object, corev1.EventTypeWarning, "Loading holiday calendar", fmt.Sprintf("Failed to load holiday calendar: %s", err))
+				return reconcile.Result{}, fmt.Errorf("error when loading holiday calendar: %v", err)
+			}
+			holiday = h
+			result.RequeueAfter = requeueAfter
+			cjValues.suspend = holiday != nil
+			cjValues.startingDeadlineSeconds = pointer.Int64(HolidayStartingDeadlineSeconds)
+		}
+
 		if err := r.createOrUpdateCronJob(ctx, instance, cjValues); err != nil {
-			r.Event(instance, corev1.EventTypeWarning, "Creating wakeup CronJob", fmt.Sprintf("Failed to create CronJob: %s", err))
+			r.Event(instance.Object, corev1.EventTypeWarning, "Creating wakeup CronJob", fmt.Sprintf("Failed to create CronJob: %s", err))
 			return reconcile.Result{}, fmt.Errorf("error when creating wakeup CronJob: %v", err)
 		} else {
-			r.Event(instance, corev1.EventTypeNormal, "Creating wakeup CronJob", "Created")
+			r.Event(instance.Object, corev1.EventTypeNormal, "Creating wakeup CronJob", "Created")
+		}
+
+		if holiday != nil {
+			requeueAfter, err := r.recordSkippedWakeup(ctx, instance, cjValues, holiday)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			result = mergeResults(result, ctrl.Result{RequeueAfter: requeueAfter})
 		}
 	} else {
 		// Delete the wakeup cronjob if necessary
-		cronJob := &v1beta1.CronJob{}
-		if err := r.Get(ctx, cjWakeupKey, cronJob); err == nil {
-			if err := r.Delete(ctx, cronJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
-				r.Event(instance, corev1.EventTypeWarning, "Deleting wakeup CronJob", fmt.Sprintf("Failed to delete CronJob: %s", err))
-				return reconcile.Result{}, fmt.Errorf("error when deleting wakeup CronJob: %v", err)
-			} else {
-				r.Event(instance, corev1.EventTypeNormal, "Deleting wakeup CronJob", "Deleted")
-			}
+		if deleted, err := r.deleteCronJob(ctx, cjWakeupKey); err != nil {
+			r.Event(instance.Object, corev1.EventTypeWarning, "Deleting wakeup CronJob", fmt.Sprintf("Failed to delete CronJob: %s", err))
+			return reconcile.Result{}, fmt.Errorf("error when deleting wakeup CronJob: %v", err)
+		} else if deleted {
+			r.Event(instance.Object, corev1.EventTypeNormal, "Deleting wakeup CronJob", "Deleted")
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
-func (r *IdlingResourceReconciler) createOrUpdateCronJob(ctx context.Context, instance *kidlev1beta1.IdlingResource, cjValues *CronJobValues) error {
-	cronJob := &v1beta1.CronJob{}
-	if err := r.Get(ctx, cjValues.key, cronJob); err != nil {
+func (r *CronStrategiesReconciler) createOrUpdateCronJob(ctx context.Context, instance *CronOwner, cjValues *CronJobValues) error {
+	if _, err := schedule.Parse(cjValues.strategy.Schedule, cjValues.strategy.TimeZone); err != nil {
+		return err
+	}
+
+	cronJob, err := r.getCronJob(ctx, cjValues.key)
+	if err != nil {
 		if errors.IsNotFound(err) {
-			cj := NewCronJob(cjValues.key)
+			cj := &runnerCronJob{CronJob: NewCronJob(cjValues.key)}
 			r.setCronjobValues(cj, cjValues)
-			if err := controllerutil.SetControllerReference(instance, cj, r.Scheme); err != nil {
+			if err := controllerutil.SetControllerReference(instance.Object, cj.CronJob, r.Scheme); err != nil {
 				return fmt.Errorf("unable to set controller reference for cronJob: %v", err)
 			}
-			if err := r.Create(ctx, cj); err != nil {
+			if err := r.createCronJob(ctx, cj); err != nil {
 				return fmt.Errorf("unable to create cronJob: %v", err)
 			}
 			return nil
@@ -125,27 +220,28 @@ func (r *IdlingResourceReconciler) createOrUpdateCronJob(ctx context.Context, in
 		}
 	}
 
+	// The CronJobs created with another API version are migrated by their update with the served API version
 	if r.cronJobNeedChanges(cronJob, cjValues) {
 		r.setCronjobValues(cronJob, cjValues)
-		if err := r.Update(ctx, cronJob); err != nil {
+		if err := r.updateCronJob(ctx, cronJob); err != nil {
 			return fmt.Errorf("unable to update cronJob: %v", err)
 		}
 	}
 	return nil
 }
 
-func NewCronJob(key types.NamespacedName) *batchv1beta1.CronJob {
-	var cj = &batchv1beta1.CronJob{
+func NewCronJob(key types.NamespacedName) *batchv1.CronJob {
+	var cj = &batchv1.CronJob{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "CronJob",
-			APIVersion: "batch/v1beta1",
+			APIVersion: "batch/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      key.Name,
 			Namespace: key.Namespace,
 		},
-		Spec: batchv1beta1.CronJobSpec{
-			JobTemplate: batchv1beta1.JobTemplateSpec{
+		Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{
 				Spec: batchv1.JobSpec{
 					Template: corev1.PodTemplateSpec{
 						ObjectMeta: metav1.ObjectMeta{},
@@ -165,15 +261,23 @@ func NewCronJob(key types.NamespacedName) *batchv1beta1.CronJob {
 	return cj
 }
 
-func (r *IdlingResourceReconciler) cronJobNeedChanges(cronJob *batchv1beta1.CronJob, cjValues *CronJobValues) bool {
-	if cronJob.Spec.JobTemplate.Spec.Template.Spec.ServiceAccountName != getSaName(cjValues.instanceName) {
+func (r *CronStrategiesReconciler) cronJobNeedChanges(cronJob *runnerCronJob, cjValues *CronJobValues) bool {
+	if version, _ := k8s.GetAnnotation(cronJob, kidlev1beta1.MetadataCronJobAPIVersion); version != cronJobAPIVersion(r.CronJobVersion) {
 		return true
 	}
 
-	if cronJob.Spec.Suspend != pointer.Bool(false) {
+	if cronJob.Spec.JobTemplate.Spec.Template.Spec.ServiceAccountName != getSaName(cjValues.owner) {
 		return true
 	}
-	if cronJob.Spec.Schedule != cjValues.strategy.Schedule {
+
+	if cronJob.Spec.Suspend == nil || *cronJob.Spec.Suspend != cjValues.suspend {
+		return true
+	}
+	if !equality.Semantic.DeepEqual(cronJob.Spec.StartingDeadlineSeconds, cjValues.startingDeadlineSeconds) {
+		return true
+	}
+	cronSchedule, timeZone := r.cronJobSchedule(cjValues.strategy)
+	if cronJob.Spec.Schedule != cronSchedule || !equality.Semantic.DeepEqual(cronJob.TimeZone, timeZone) {
 		return true
 	}
 
@@ -181,46 +285,57 @@ func (r *IdlingResourceReconciler) cronJobNeedChanges(cronJob *batchv1beta1.Cron
 	if container.Image != r.KidlectlImage {
 		return true
 	}
-	if len(container.Args) != 2 ||
-		container.Args[0] != cjValues.command ||
-		container.Args[1] != cjValues.key.Name {
+	if !equality.Semantic.DeepEqual(container.Args, cronJobArgs(cjValues)) {
 		return true
 	}
 	return false
 }
 
-func (r *IdlingResourceReconciler) setCronjobValues(cronJob *batchv1beta1.CronJob, cjValues *CronJobValues) {
-	cronJob.Spec.JobTemplate.Spec.Template.Spec.ServiceAccountName = getSaName(cjValues.instanceName)
+func (r *CronStrategiesReconciler) setCronjobValues(cronJob *runnerCronJob, cjValues *CronJobValues) {
+	k8s.AddAnnotation(cronJob, kidlev1beta1.MetadataCronJobAPIVersion, cronJobAPIVersion(r.CronJobVersion))
+	cronJob.Spec.JobTemplate.Spec.Template.Spec.ServiceAccountName = getSaName(cjValues.owner)
 
-	cronJob.Spec.Suspend = pointer.Bool(false)
-	cronJob.Spec.Schedule = cjValues.strategy.Schedule
+	cronJob.Spec.Suspend = pointer.Bool(cjValues.suspend)
+	cronJob.Spec.StartingDeadlineSeconds = cjValues.startingDeadlineSeconds
+	cronJob.Spec.Schedule, cronJob.TimeZone = r.cronJobSchedule(cjValues.strategy)
 
 	container := k8s.ContainersToMap(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers)[CronJobContainerName]
 	container.Image = r.KidlectlImage
-	container.Args = []string{
-		cjValues.command,
-		cjValues.instanceName,
-	}
+	container.Args = cronJobArgs(cjValues)
 	k8s.SetContainer(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers, &container)
 }
 
-func getSaName(instanceName string) string {
-	return k8s.ToDNSName("kidle", instanceName, "sa")
+// cronJobSchedule returns the schedule and the time zone of a CronJob.
+// The clusters without the timeZone field, older than Kubernetes 1.25, get the time zone as a CRON_TZ prefix of the schedule.
+func (r *CronStrategiesReconciler) cronJobSchedule(strategy *kidlev1beta1.CronStrategy) (string, *string) {
+	if !r.CronJobTimeZone || strategy.TimeZone == "" {
+		return schedule.CronJobSchedule(strategy.Schedule, strategy.TimeZone), nil
+	}
+	return strategy.Schedule, pointer.String(strategy.TimeZone)
+}
+
+// cronJobArgs returns the kidlectl args of a CronJob
+func cronJobArgs(cjValues *CronJobValues) []string {
+	return append([]string{cjValues.command}, cjValues.owner.Args...)
+}
+
+func getSaName(instance *CronOwner) string {
+	return k8s.ToDNSName(instance.Prefix, instance.GetName(), "sa")
 }
 
-func (r *IdlingResourceReconciler) createRBAC(ctx context.Context, instance *kidlev1beta1.IdlingResource) error {
-	saName := getSaName(instance.Name)
+func (r *CronStrategiesReconciler) createRBAC(ctx context.Context, instance *CronOwner) error {
+	saName := getSaName(instance)
 	sa := &corev1.ServiceAccount{}
-	saKey := types.NamespacedName{Namespace: instance.Namespace, Name: saName}
+	saKey := types.NamespacedName{Namespace: instance.GetNamespace(), Name: saName}
 	if err := r.Get(ctx, saKey, sa); err != nil {
 		if errors.IsNotFound(err) {
 			sa = &corev1.ServiceAccount{
 				ObjectMeta: metav1.ObjectMeta{
-					Namespace: instance.Namespace,
+					Namespace: instance.GetNamespace(),
 					Name:      saName,
 				},
 			}
-			if err := controllerutil.SetControllerReference(instance, sa, r.Scheme); err != nil {
+			if err := controllerutil.SetControllerReference(instance.Object, sa, r.Scheme); err != nil {
 				return fmt.Errorf("unable to set controller reference for sa: %v", err)
 			}
 			if err := r.Create(ctx, sa); err != nil {
@@ -231,25 +346,25 @@ func (r *IdlingResourceReconciler) createRBAC(ctx context.Context, instance *kid
 		}
 	}
 
-	roleName := k8s.ToDNSName("kidle", instance.Name, "role")
+	roleName := k8s.ToDNSName(instance.Prefix, instance.GetName(), "role")
 	role := &rbacv1.Role{}
-	roleKey := types.NamespacedName{Namespace: instance.Namespace, Name: roleName}
+	roleKey := types.NamespacedName{Namespace: instance.GetNamespace(), Name: roleName}
 	policyRule := rbacv1.PolicyRule{
 		Verbs:         []string{"get", "patch", "update"},
 		APIGroups:     []string{"kidle.kidle.dev"},
-		Resources:     []string{"idlingresources"},
-		ResourceNames: []string{instance.Name},
+		Resources:     []string{instance.Resource},
+		ResourceNames: []string{instance.GetName()},
 	}
 	if err := r.Get(ctx, roleKey, role); err != nil {
 		if errors.IsNotFound(err) {
 			role = &rbacv1.Role{
 				ObjectMeta: metav1.ObjectMeta{
-					Namespace: instance.Namespace,
+					Namespace: instance.GetNamespace(),
 					Name:      roleName,
 				},
 				Rules: []rbacv1.PolicyRule{policyRule},
 			}
-			if err = controllerutil.SetControllerReference(instance, role, r.Scheme); err != nil {
+			if err = controllerutil.SetControllerReference(instance.Object, role, r.Scheme); err != nil {
 				return fmt.Errorf("unable to set controller reference for role: %v", err)
 			}
 			if err = r.Create(ctx, role); err != nil {
@@ -260,19 +375,19 @@ func (r *IdlingResourceReconciler) createRBAC(ctx context.Context, instance *kid
 		}
 	} else {
 		role.Rules[0] = policyRule
-		if err := r.Update(ctx, role) ; err != nil {
+		if err := r.Update(ctx, role); err != nil {
 			return fmt.Errorf("unable to update role: %v", err)
 		}
 	}
 
-	rbName := k8s.ToDNSName("kidle", instance.Name, "rb")
+	rbName := k8s.ToDNSName(instance.Prefix, instance.GetName(), "rb")
 	rb := &rbacv1.RoleBinding{}
-	rbKey := types.NamespacedName{Namespace: instance.Namespace, Name: rbName}
+	rbKey := types.NamespacedName{Namespace: instance.GetNamespace(), Name: rbName}
 	if err := r.Get(ctx, rbKey, rb); err != nil {
 		if errors.IsNotFound(err) {
 			rb := &rbacv1.RoleBinding{
 				ObjectMeta: metav1.ObjectMeta{
-					Namespace: instance.Namespace,
+					Namespace: instance.GetNamespace(),
 					Name:      rbName,
 				},
 				Subjects: []rbacv1.Subject{{
@@ -285,7 +400,7 @@ func (r *IdlingResourceReconciler) createRBAC(ctx context.Context, instance *kid
 					Name:     role.Name,
 				},
 			}
-			if err = controllerutil.SetControllerReference(instance, rb, r.Scheme); err != nil {
+			if err = controllerutil.SetControllerReference(instance.Object, rb, r.Scheme); err != nil {
 				return fmt.Errorf("unable to set controller reference for rolebinding: %v", err)
 			}
 			if err = r.Create(ctx, rb); err != nil {
@@ -294,8 +409,8 @@ func (r *IdlingResourceReconciler) createRBAC(ctx context.Context, instance *kid
 		} else {
 			return fmt.Errorf("unable to get rolebinding: %v", err)
 		}
-	}// else {
-		// TODO update if necessary
+	} // else {
+	// TODO update if necessary
 	//}
 	return nil
 }
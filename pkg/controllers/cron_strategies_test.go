@@ -11,6 +11,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
@@ -120,6 +122,128 @@ var _ = Describe("cronjob strategy", func() {
 		})
 	})
 
+	Context("Time zone cronjob strategy suite", func() {
+		var (
+			irKey          = types.NamespacedName{Name: "ir-timezone-cronjob-strategy", Namespace: "default"}
+			idlingStrategy = &kidlev1beta1.IdlingStrategy{
+				CronStrategy: &kidlev1beta1.CronStrategy{
+					Schedule: "0 20 * * 1-5",
+					TimeZone: "Europe/Paris",
+				},
+			}
+			idlingResource = newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       "none",
+				APIVersion: "apps/appsv1",
+			})
+		)
+		idlingResource.Spec.IdlingStrategy = idlingStrategy
+
+		It("Has created an IdlingResource object", func() {
+
+			By("Creating the IdlingResource object")
+			Expect(k8sClient.Create(ctx, idlingResource)).Should(Succeed())
+		})
+
+		It("Has created a cronjob in the time zone", func() {
+			if !cronJobTimeZone {
+				assertCronJob(irKey, CommandIdle, "CRON_TZ=Europe/Paris 0 20 * * 1-5")
+				return
+			}
+			assertCronJob(irKey, CommandIdle, "0 20 * * 1-5")
+
+			By("Validation of the cronjob time zone")
+			cronStrategies := &CronStrategiesReconciler{Client: k8sClient, CronJobVersion: cronJobVersion, CronJobTimeZone: cronJobTimeZone}
+			cj, err := cronStrategies.getCronJob(ctx, types.NamespacedName{Name: k8s.ToDNSName("kidle", irKey.Name, CommandIdle), Namespace: irKey.Namespace})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cj.TimeZone).To(Equal(pointer.String("Europe/Paris")))
+		})
+
+		It("Has rejected an unknown time zone", func() {
+			By("Setting an unknown time zone")
+			ir := &kidlev1beta1.IdlingResource{}
+			Expect(k8sClient.Get(ctx, irKey, ir)).Should(Succeed())
+			ir.Spec.IdlingStrategy.CronStrategy.TimeZone = "Europe/Lyon"
+			Expect(k8sClient.Update(ctx, ir)).Should(Succeed())
+
+			By("Reporting the invalid time zone in the status")
+			Eventually(func() bool {
+				ir := &kidlev1beta1.IdlingResource{}
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return false
+				}
+				return meta.IsStatusConditionFalse(ir.Status.Conditions, kidlev1beta1.ConditionSchedulesConfigured)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("Holiday calendar suite", func() {
+		var (
+			irKey          = types.NamespacedName{Name: "ir-holiday-cronjob-strategy", Namespace: "default"}
+			cmKey          = types.NamespacedName{Name: "holidays", Namespace: "default"}
+			cjKey          = types.NamespacedName{Name: k8s.ToDNSName("kidle", irKey.Name, CommandWakeup), Namespace: irKey.Namespace}
+			wakeupStrategy = &kidlev1beta1.WakeupStrategy{
+				CronStrategy: &kidlev1beta1.CronStrategy{
+					Schedule: "30 7 * * *",
+				},
+				HolidayCalendar: &kidlev1beta1.HolidayCalendarReference{
+					Name: cmKey.Name,
+				},
+			}
+			idlingResource = newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       "none",
+				APIVersion: "apps/appsv1",
+			})
+			holidayOn = func(day time.Time) string {
+				return "BEGIN:VCALENDAR\nBEGIN:VEVENT\n" +
+					"DTSTART;VALUE=DATE:" + day.Format("20060102") + "\n" +
+					"SUMMARY:Day off\nEND:VEVENT\nEND:VCALENDAR\n"
+			}
+			suspendOf = func() (*bool, error) {
+				cj := &batchv1beta1.CronJob{}
+				if err := k8sClient.Get(ctx, cjKey, cj); err != nil {
+					return nil, err
+				}
+				return cj.Spec.Suspend, nil
+			}
+		)
+		idlingResource.Spec.WakeupStrategy = wakeupStrategy
+
+		It("Has suspended the wakeup cronjob during the holiday", func() {
+			By("Creating a holiday calendar with today")
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: cmKey.Name, Namespace: cmKey.Namespace},
+				Data: map[string]string{
+					kidlev1beta1.DefaultHolidayCalendarKey: holidayOn(time.Now().UTC()),
+				},
+			}
+			Expect(k8sClient.Create(ctx, cm)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, idlingResource)).Should(Succeed())
+
+			Eventually(suspendOf, timeout, interval).Should(Equal(pointer.Bool(true)))
+			cj := &batchv1beta1.CronJob{}
+			Expect(k8sClient.Get(ctx, cjKey, cj)).Should(Succeed())
+			Expect(cj.Spec.StartingDeadlineSeconds).To(Equal(pointer.Int64(HolidayStartingDeadlineSeconds)))
+		})
+
+		It("Has resumed the wakeup cronjob after the holiday", func() {
+			By("Moving the holiday to tomorrow")
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, cmKey, cm)).Should(Succeed())
+			cm.Data[kidlev1beta1.DefaultHolidayCalendarKey] = holidayOn(time.Now().UTC().AddDate(0, 0, 2))
+			Expect(k8sClient.Update(ctx, cm)).Should(Succeed())
+
+			By("Triggering a reconciliation")
+			ir := &kidlev1beta1.IdlingResource{}
+			Expect(k8sClient.Get(ctx, irKey, ir)).Should(Succeed())
+			ir.Spec.WakeupStrategy.CronStrategy.Schedule = "0 8 * * *"
+			Expect(k8sClient.Update(ctx, ir)).Should(Succeed())
+
+			Eventually(suspendOf, timeout, interval).Should(Equal(pointer.Bool(false)))
+		})
+	})
+
 	assertServiceAccount = func(irKey types.NamespacedName) {
 		By("Validation of the service account creation")
 		sa := &corev1.ServiceAccount{}
@@ -206,15 +330,18 @@ var _ = Describe("cronjob strategy", func() {
 
 	assertCronJob = func(irKey types.NamespacedName, command string, cron string) {
 		By("Validation of the cronjob creation")
-		cj := &batchv1beta1.CronJob{}
+		cronStrategies := &CronStrategiesReconciler{Client: k8sClient, CronJobVersion: cronJobVersion, CronJobTimeZone: cronJobTimeZone}
 		cjKey := types.NamespacedName{Name: k8s.ToDNSName("kidle", irKey.Name, command), Namespace: irKey.Namespace}
-		Eventually(func() error {
-			return k8sClient.Get(ctx, cjKey, cj)
+		var cj *runnerCronJob
+		Eventually(func() (err error) {
+			cj, err = cronStrategies.getCronJob(ctx, cjKey)
+			return err
 		}, timeout, interval).Should(Succeed())
 
 		By("Validation of the cronjob metadata")
 		Expect(cj.ObjectMeta.Name).To(Equal(cjKey.Name))
 		Expect(cj.ObjectMeta.Namespace).To(Equal(cjKey.Namespace))
+		Expect(cj.ObjectMeta.Annotations).To(HaveKeyWithValue(kidlev1beta1.MetadataCronJobAPIVersion, cronJobVersion.String()))
 
 		By("Validation of the cronjob spec")
 		Expect(cj.Spec.Suspend).To(Equal(pointer.Bool(false)))
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The CronJobs running kidlectl are handled as batch/v1 CronJobs, and converted from and to batch/v1beta1
+// when the cluster does not serve batch/v1 yet, or from and to unstructured objects when the cluster supports their time zone.
+
+// newCronJobObject returns an empty CronJob of an API version, batch/v1 by default
+func newCronJobObject(gv schema.GroupVersion) client.Object {
+	if gv == batchv1beta1.SchemeGroupVersion {
+		return &batchv1beta1.CronJob{}
+	}
+	return &batchv1.CronJob{}
+}
+
+// cronJobAPIVersion returns the API version of the CronJobs served by the cluster, batch/v1 by default
+func cronJobAPIVersion(gv schema.GroupVersion) string {
+	if gv.Empty() {
+		return batchv1.SchemeGroupVersion.String()
+	}
+	return gv.String()
+}
+
+// runnerCronJob is a CronJob running kidlectl, handled as a batch/v1 CronJob.
+// TimeZone is the spec.timeZone of the CronJob, served from Kubernetes 1.25 but unknown to the batch/v1 types in use:
+// the CronJobs are read and written as unstructured objects to keep it when the cluster supports it.
+type runnerCronJob struct {
+	*batchv1.CronJob
+	TimeZone *string
+}
+
+// DeepCopy copies the CronJob and its time zone
+func (in *runnerCronJob) DeepCopy() *runnerCronJob {
+	out := &runnerCronJob{CronJob: in.CronJob.DeepCopy()}
+	if in.TimeZone != nil {
+		timeZone := *in.TimeZone
+		out.TimeZone = &timeZone
+	}
+	return out
+}
+
+// newObject returns an empty CronJob of the API version of the reconciler
+func (r *CronStrategiesReconciler) newObject() client.Object {
+	if r.CronJobTimeZone {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+		return u
+	}
+	return newCronJobObject(r.CronJobVersion)
+}
+
+// toObject converts a CronJob to an object of the API version of the reconciler
+func (r *CronStrategiesReconciler) toObject(cronJob *runnerCronJob) (client.Object, error) {
+	if r.CronJobTimeZone {
+		return cronJobToUnstructured(cronJob)
+	}
+	return cronJobToObject(cronJob.CronJob, r.CronJobVersion), nil
+}
+
+// fromObject converts an object of the API version of the reconciler to a CronJob
+func (r *CronStrategiesReconciler) fromObject(obj client.Object) (*runnerCronJob, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return cronJobFromUnstructured(u)
+	}
+	return &runnerCronJob{CronJob: cronJobFromObject(obj)}, nil
+}
+
+// getCronJob reads a CronJob with the API version of the reconciler
+func (r *CronStrategiesReconciler) getCronJob(ctx context.Context, key types.NamespacedName) (*runnerCronJob, error) {
+	obj := r.newObject()
+	if err := r.Get(ctx, key, obj); err != nil {
+		return nil, err
+	}
+	return r.fromObject(obj)
+}
+
+// createCronJob creates a CronJob with the API version of the reconciler
+func (r *CronStrategiesReconciler) createCronJob(ctx context.Context, cronJob *runnerCronJob) error {
+	obj, err := r.toObject(cronJob)
+	if err != nil {
+		return err
+	}
+	if err := r.Create(ctx, obj); err != nil {
+		return err
+	}
+	created, err := r.fromObject(obj)
+	if err != nil {
+		return err
+	}
+	*cronJob = *created
+	return nil
+}
+
+// updateCronJob updates a CronJob with the API version of the reconciler
+func (r *CronStrategiesReconciler) updateCronJob(ctx context.Context, cronJob *runnerCronJob) error {
+	obj, err := r.toObject(cronJob)
+	if err != nil {
+		return err
+	}
+	if err := r.Update(ctx, obj); err != nil {
+		return err
+	}
+	updated, err := r.fromObject(obj)
+	if err != nil {
+		return err
+	}
+	*cronJob = *updated
+	return nil
+}
+
+// patchCronJob patches a CronJob with the changes from its original version
+func (r *CronStrategiesReconciler) patchCronJob(ctx context.Context, original *runnerCronJob, cronJob *runnerCronJob) error {
+	originalObj, err := r.toObject(original)
+	if err != nil {
+		return err
+	}
+	obj, err := r.toObject(cronJob)
+	if err != nil {
+		return err
+	}
+	return r.Patch(ctx, obj, client.MergeFrom(originalObj))
+}
+
+// deleteCronJob deletes a CronJob with its jobs, it returns false if the CronJob does not exist
+func (r *CronStrategiesReconciler) deleteCronJob(ctx context.Context, key types.NamespacedName) (bool, error) {
+	obj := r.newObject()
+	if err := r.Get(ctx, key, obj); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return true, client.IgnoreNotFound(r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
+
+// cronJobToUnstructured converts a CronJob to an unstructured batch/v1 CronJob with its time zone
+func cronJobToUnstructured(in *runnerCronJob) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(in.CronJob)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert cronJob: %v", err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+	if in.TimeZone != nil {
+		if err := unstructured.SetNestedField(u.Object, *in.TimeZone, "spec", "timeZone"); err != nil {
+			return nil, fmt.Errorf("unable to set the time zone of cronJob: %v", err)
+		}
+	}
+	return u, nil
+}
+
+// cronJobFromUnstructured converts an unstructured batch/v1 CronJob to a CronJob with its time zone
+func cronJobFromUnstructured(u *unstructured.Unstructured) (*runnerCronJob, error) {
+	cronJob := &batchv1.CronJob{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cronJob); err != nil {
+		return nil, fmt.Errorf("unable to convert cronJob: %v", err)
+	}
+	out := &runnerCronJob{CronJob: cronJob}
+	if timeZone, found, _ := unstructured.NestedString(u.Object, "spec", "timeZone"); found {
+		out.TimeZone = &timeZone
+	}
+	return out, nil
+}
+
+// cronJobToObject converts a batch/v1 CronJob to a CronJob of an API version
+func cronJobToObject(in *batchv1.CronJob, gv schema.GroupVersion) client.Object {
+	if gv != batchv1beta1.SchemeGroupVersion {
+		return in.DeepCopy()
+	}
+	in = in.DeepCopy()
+	return &batchv1beta1.CronJob{
+		ObjectMeta: in.ObjectMeta,
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   in.Spec.Schedule,
+			StartingDeadlineSeconds:    in.Spec.StartingDeadlineSeconds,
+			ConcurrencyPolicy:          batchv1beta1.ConcurrencyPolicy(in.Spec.ConcurrencyPolicy),
+			Suspend:                    in.Spec.Suspend,
+			JobTemplate:                batchv1beta1.JobTemplateSpec{ObjectMeta: in.Spec.JobTemplate.ObjectMeta, Spec: in.Spec.JobTemplate.Spec},
+			SuccessfulJobsHistoryLimit: in.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     in.Spec.FailedJobsHistoryLimit,
+		},
+		Status: batchv1beta1.CronJobStatus{
+			Active:             in.Status.Active,
+			LastScheduleTime:   in.Status.LastScheduleTime,
+			LastSuccessfulTime: in.Status.LastSuccessfulTime,
+		},
+	}
+}
+
+// cronJobFromObject converts a batch/v1 or a batch/v1beta1 CronJob to a batch/v1 CronJob
+func cronJobFromObject(obj client.Object) *batchv1.CronJob {
+	switch in := obj.(type) {
+	case *batchv1.CronJob:
+		return in.DeepCopy()
+	case *batchv1beta1.CronJob:
+		in = in.DeepCopy()
+		return &batchv1.CronJob{
+			ObjectMeta: in.ObjectMeta,
+			Spec: batchv1.CronJobSpec{
+				Schedule:                   in.Spec.Schedule,
+				StartingDeadlineSeconds:    in.Spec.StartingDeadlineSeconds,
+				ConcurrencyPolicy:          batchv1.ConcurrencyPolicy(in.Spec.ConcurrencyPolicy),
+				Suspend:                    in.Spec.Suspend,
+				JobTemplate:                batchv1.JobTemplateSpec{ObjectMeta: in.Spec.JobTemplate.ObjectMeta, Spec: in.Spec.JobTemplate.Spec},
+				SuccessfulJobsHistoryLimit: in.Spec.SuccessfulJobsHistoryLimit,
+				FailedJobsHistoryLimit:     in.Spec.FailedJobsHistoryLimit,
+			},
+			Status: batchv1.CronJobStatus{
+				Active:             in.Status.Active,
+				LastScheduleTime:   in.Status.LastScheduleTime,
+				LastSuccessfulTime: in.Status.LastSuccessfulTime,
+			},
+		}
+	}
+	return nil
+}
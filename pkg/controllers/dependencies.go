@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"github.com/kidle-dev/kidle/pkg/utils/array"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DependencyRequeueInterval is the interval between two checks of the dependencies blocking a wakeup or an idling
+const DependencyRequeueInterval = 5 * time.Second
+
+const (
+	// ReasonWaitingForDependencies is the reason of a wakeup waiting for the dependencies to be ready
+	ReasonWaitingForDependencies = "WaitingForDependencies"
+	// ReasonWaitingForDependents is the reason of an idling waiting for the dependents to be idled
+	ReasonWaitingForDependents = "WaitingForDependents"
+)
+
+// reconcileDependencies holds the wakeup until the dependencies of the IdlingResource are ready,
+// and the idling until the IdlingResources depending on it are idled.
+// It returns true if the workload must wait.
+func (r *IdlingResourceReconciler) reconcileDependencies(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingResource, i idler.Idler) (bool, error) {
+	var blockedBy []string
+	var err error
+	var phase kidlev1beta1.IdlingResourcePhase
+	var reason, message string
+	switch {
+	case i.NeedWakeup(instance):
+		blockedBy, err = r.dependenciesNotReady(ctx, log, instance)
+		phase, reason, message = kidlev1beta1.PhaseWakingUp, ReasonWaitingForDependencies, "Waiting for the dependencies to be ready: %s"
+	case i.NeedIdle(instance):
+		blockedBy, err = r.dependentsNotIdled(ctx, log, instance)
+		phase, reason, message = kidlev1beta1.PhaseIdling, ReasonWaitingForDependents, "Waiting for the dependents to be idled: %s"
+	}
+	if err != nil {
+		return false, err
+	}
+
+	previous := instance.Status.BlockedBy
+	instance.Status.BlockedBy = blockedBy
+	if len(blockedBy) == 0 {
+		if len(instance.Spec.DependsOn) > 0 || meta.FindStatusCondition(instance.Status.Conditions, kidlev1beta1.ConditionDependenciesReady) != nil {
+			setCondition(instance, kidlev1beta1.ConditionDependenciesReady, metav1.ConditionTrue, ReasonReconciled, "No dependency is blocking the workload")
+		}
+		return false, nil
+	}
+
+	message = fmt.Sprintf(message, strings.Join(blockedBy, ", "))
+	if !equality.Semantic.DeepEqual(previous, blockedBy) {
+		r.Event(instance, corev1.EventTypeNormal, reason, message)
+	}
+	setIdlingPhase(instance, phase)
+	setCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, reason, message)
+	setCondition(instance, kidlev1beta1.ConditionDependenciesReady, metav1.ConditionFalse, reason, message)
+	return true, nil
+}
+
+// dependenciesNotReady returns the dependencies of the IdlingResource whose workload is not awake and ready
+func (r *IdlingResourceReconciler) dependenciesNotReady(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingResource) ([]string, error) {
+	var notReady []string
+	for _, name := range instance.Spec.DependsOn {
+		dependency := &kidlev1beta1.IdlingResource{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: name}, dependency); err != nil {
+			if errors.IsNotFound(err) {
+				notReady = append(notReady, name)
+				continue
+			}
+			return nil, fmt.Errorf("unable to read the dependency %s: %v", name, err)
+		}
+		if dependency.Spec.Idle {
+			notReady = append(notReady, name)
+			continue
+		}
+
+		i, err := r.getWorkloadIdler(ctx, log, instance.Namespace, dependency.Spec.IdlingResourceRef)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				notReady = append(notReady, name)
+				continue
+			}
+			return nil, fmt.Errorf("unable to read the workload of the dependency %s: %v", name, err)
+		}
+		if !i.IsReady() {
+			notReady = append(notReady, name)
+		}
+	}
+	return notReady, nil
+}
+
+// dependentsNotIdled returns the IdlingResources depending on the IdlingResource whose workload is not idled
+func (r *IdlingResourceReconciler) dependentsNotIdled(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingResource) ([]string, error) {
+	irs := &kidlev1beta1.IdlingResourceList{}
+	if err := r.List(ctx, irs, client.InNamespace(instance.Namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list idling resources: %v", err)
+	}
+
+	var notIdled []string
+	for _, dependent := range irs.Items {
+		if !array.ContainsString(dependent.Spec.DependsOn, instance.Name) || dependent.IsBeingDeleted() {
+			continue
+		}
+		i, err := r.getWorkloadIdler(ctx, log, instance.Namespace, dependent.Spec.IdlingResourceRef)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to read the workload of the dependent %s: %v", dependent.Name, err)
+		}
+		if !i.IsIdled() {
+			notIdled = append(notIdled, dependent.Name)
+		}
+	}
+	return notIdled, nil
+}
+
+// getWorkloadIdler returns the Idler of a workload referenced by an IdlingResource
+func (r *IdlingResourceReconciler) getWorkloadIdler(ctx context.Context, log logr.Logger, namespace string, ref kidlev1beta1.CrossVersionObjectReference) (idler.Idler, error) {
+	registration := idler.DefaultRegistry.Lookup(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	if registration == nil {
+		return r.getScaleIdler(ctx, log, namespace, ref)
+	}
+	workload := registration.NewObject()
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, workload); err != nil {
+		return nil, err
+	}
+	return registration.NewIdler(r.Client, log, workload), nil
+}
+
+// workloadKey returns the Kind/name reference of a workload used by the depends-on annotation
+func workloadKey(workload client.Object) string {
+	ref := workloadReference(workload)
+	return fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+}
+
+// workloadDependencies returns the Kind/name references of the depends-on annotation of a workload
+func workloadDependencies(workload client.Object) []string {
+	var dependencies []string
+	for _, dependency := range strings.Split(workload.GetAnnotations()[kidlev1beta1.MetadataDependsOn], ",") {
+		if dependency = strings.TrimSpace(dependency); dependency != "" {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+// checkDependencies rejects the unknown and circular dependencies of the depends-on annotation of a workload of a group,
+// which would block its wakeup or the idling of its dependencies forever.
+// The dependencies are looked up among the workloads of the namespace.
+func checkDependencies(workload client.Object, workloads []client.Object) error {
+	graph := make(map[string][]string, len(workloads))
+	for _, w := range workloads {
+		graph[workloadKey(w)] = workloadDependencies(w)
+	}
+
+	key := workloadKey(workload)
+	for _, dependency := range workloadDependencies(workload) {
+		if _, found := graph[dependency]; !found {
+			return fmt.Errorf("unknown dependency %s in the %s annotation", dependency, kidlev1beta1.MetadataDependsOn)
+		}
+		if cycle := workloadDependencyCycle(graph, key, dependency, []string{key}); cycle != nil {
+			return fmt.Errorf("circular dependency in the %s annotation: %s", kidlev1beta1.MetadataDependsOn, strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// workloadDependencyCycle returns the path from the dependency back to the root workload, or nil without cycle
+func workloadDependencyCycle(graph map[string][]string, root string, dependency string, path []string) []string {
+	path = append(path, dependency)
+	if dependency == root {
+		return path
+	}
+	if array.ContainsString(path[:len(path)-1], dependency) {
+		return nil
+	}
+	for _, next := range graph[dependency] {
+		if cycle := workloadDependencyCycle(graph, root, next, path); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// waitingDependencies returns the dependencies of a workload of a group which are not awake and ready.
+// The dependencies are looked up among the workloads of the namespace, checked by checkDependencies.
+func waitingDependencies(c client.Client, log logr.Logger, workload client.Object, workloads []client.Object) ([]string, error) {
+	byKey := make(map[string]client.Object, len(workloads))
+	for _, w := range workloads {
+		byKey[workloadKey(w)] = w
+	}
+
+	var notReady []string
+	for _, dependency := range workloadDependencies(workload) {
+		w, found := byKey[dependency]
+		if !found {
+			notReady = append(notReady, dependency)
+			continue
+		}
+		i, err := newWorkloadIdler(c, log, w)
+		if err != nil {
+			return nil, err
+		}
+		if !i.IsReady() {
+			notReady = append(notReady, dependency)
+		}
+	}
+	return notReady, nil
+}
+
+// runningDependents returns the members of a group depending on a workload which are not idled
+func runningDependents(c client.Client, log logr.Logger, workload client.Object, members []client.Object) ([]string, error) {
+	key := workloadKey(workload)
+
+	var notIdled []string
+	for _, member := range members {
+		if !array.ContainsString(workloadDependencies(member), key) {
+			continue
+		}
+		i, err := newWorkloadIdler(c, log, member)
+		if err != nil {
+			return nil, err
+		}
+		if !i.IsIdled() {
+			notIdled = append(notIdled, workloadKey(member))
+		}
+	}
+	return notIdled, nil
+}
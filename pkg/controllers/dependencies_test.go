@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("dependencies", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx = context.Background()
+	)
+
+	getReplicas := func(deployKey types.NamespacedName) func() (*int32, error) {
+		return func() (*int32, error) {
+			d := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, deployKey, d); err != nil {
+				return nil, err
+			}
+			return d.Spec.Replicas, nil
+		}
+	}
+
+	It("Should wake up an IdlingResource once its dependencies are ready", func() {
+		dbKey := types.NamespacedName{Name: "deps-db", Namespace: "default"}
+		appKey := types.NamespacedName{Name: "deps-app", Namespace: "default"}
+		irDbKey := types.NamespacedName{Name: "ir-deps-db", Namespace: "default"}
+		irAppKey := types.NamespacedName{Name: "ir-deps-app", Namespace: "default"}
+
+		By("Idling the application and its database")
+		for _, key := range []types.NamespacedName{dbKey, appKey} {
+			Expect(k8sClient.Create(ctx, newDeployment(key, 2))).Should(Succeed())
+		}
+		db := newIdlingResource(irDbKey, &kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: dbKey.Name, APIVersion: "apps/v1"})
+		db.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, db)).Should(Succeed())
+		app := newIdlingResource(irAppKey, &kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: appKey.Name, APIVersion: "apps/v1"})
+		app.Spec.Idle = true
+		app.Spec.DependsOn = []string{irDbKey.Name}
+		Expect(k8sClient.Create(ctx, app)).Should(Succeed())
+
+		Eventually(getReplicas(appKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+		Eventually(getReplicas(dbKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+
+		By("Checking that the application waits for its idled database")
+		Expect(setIdleFlag(ctx, irAppKey, false)).Should(Succeed())
+		ir := &kidlev1beta1.IdlingResource{}
+		Eventually(func() ([]string, error) {
+			if err := k8sClient.Get(ctx, irAppKey, ir); err != nil {
+				return nil, err
+			}
+			return ir.Status.BlockedBy, nil
+		}, timeout, interval).Should(Equal([]string{irDbKey.Name}))
+		Expect(ir.Status.Phase).Should(Equal(kidlev1beta1.PhaseWakingUp))
+		Expect(meta.FindStatusCondition(ir.Status.Conditions, kidlev1beta1.ConditionDependenciesReady).Reason).Should(Equal(ReasonWaitingForDependencies))
+		Consistently(getReplicas(appKey), time.Second, interval).Should(Equal(pointer.Int32(0)))
+
+		By("Checking that the application waits for its database to be ready")
+		Expect(setIdleFlag(ctx, irDbKey, false)).Should(Succeed())
+		Eventually(getReplicas(dbKey), timeout, interval).Should(Equal(pointer.Int32(2)))
+		Consistently(getReplicas(appKey), time.Second, interval).Should(Equal(pointer.Int32(0)))
+
+		By("Checking that the application is waked up once its database is ready")
+		Expect(setDeploymentReady(ctx, dbKey)).Should(Succeed())
+		Eventually(getReplicas(appKey), timeout, interval).Should(Equal(pointer.Int32(2)))
+		Eventually(func() ([]string, error) {
+			if err := k8sClient.Get(ctx, irAppKey, ir); err != nil {
+				return nil, err
+			}
+			return ir.Status.BlockedBy, nil
+		}, timeout, interval).Should(BeEmpty())
+		Expect(meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionDependenciesReady)).Should(BeTrue())
+
+		By("Checking that the database waits for the application to be idled")
+		Expect(setIdleFlag(ctx, irDbKey, true)).Should(Succeed())
+		Eventually(func() ([]string, error) {
+			if err := k8sClient.Get(ctx, irDbKey, ir); err != nil {
+				return nil, err
+			}
+			return ir.Status.BlockedBy, nil
+		}, timeout, interval).Should(Equal([]string{irAppKey.Name}))
+		Consistently(getReplicas(dbKey), time.Second, interval).Should(Equal(pointer.Int32(2)))
+
+		Expect(setIdleFlag(ctx, irAppKey, true)).Should(Succeed())
+		Eventually(getReplicas(appKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+		Eventually(getReplicas(dbKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+	})
+
+	It("Should wake up the members of an IdlingGroup in the order of their dependencies", func() {
+		groupKey := types.NamespacedName{Name: "ig-deps", Namespace: "default"}
+		dbKey := types.NamespacedName{Name: "ig-deps-db", Namespace: "default"}
+		appKey := types.NamespacedName{Name: "ig-deps-app", Namespace: "default"}
+
+		for _, key := range []types.NamespacedName{dbKey, appKey} {
+			d := newDeployment(key, 1)
+			d.Labels = map[string]string{"env": "deps"}
+			if key == appKey {
+				d.Annotations = map[string]string{kidlev1beta1.MetadataDependsOn: "Deployment/" + dbKey.Name}
+			}
+			Expect(k8sClient.Create(ctx, d)).Should(Succeed())
+		}
+		group := newIdlingGroup(groupKey, &metav1.LabelSelector{MatchLabels: map[string]string{"env": "deps"}})
+		group.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, group)).Should(Succeed())
+
+		Eventually(getReplicas(appKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+		Eventually(getReplicas(dbKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+
+		By("Checking that the application waits for its database to be ready")
+		Expect(setGroupIdleFlag(ctx, groupKey, false)).Should(Succeed())
+		Eventually(getReplicas(dbKey), timeout, interval).Should(Equal(pointer.Int32(1)))
+		Eventually(func() ([]kidlev1beta1.IdlingGroupWorkloadStatus, error) {
+			if err := k8sClient.Get(ctx, groupKey, group); err != nil {
+				return nil, err
+			}
+			return group.Status.WorkloadStatuses, nil
+		}, timeout, interval).Should(ContainElement(kidlev1beta1.IdlingGroupWorkloadStatus{
+			Ref:              kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: appKey.Name, APIVersion: "apps/v1"},
+			State:            kidlev1beta1.WorkloadWaiting,
+			PreviousReplicas: pointer.Int32(1),
+			BlockedBy:        []string{"Deployment/" + dbKey.Name},
+			Message:          "Waiting for the dependencies to be ready: Deployment/" + dbKey.Name,
+		}))
+		Expect(group.Status.Phase).Should(Equal(kidlev1beta1.PhaseWakingUp))
+		Consistently(getReplicas(appKey), time.Second, interval).Should(Equal(pointer.Int32(0)))
+
+		By("Checking that the application is waked up once its database is ready")
+		Expect(setDeploymentReady(ctx, dbKey)).Should(Succeed())
+		Eventually(getReplicas(appKey), timeout, interval).Should(Equal(pointer.Int32(1)))
+	})
+
+	It("Should report the unknown and circular dependencies of the members of an IdlingGroup as errors", func() {
+		groupKey := types.NamespacedName{Name: "ig-deps-invalid", Namespace: "default"}
+		dependencies := map[string]string{
+			"ig-deps-front":  "Deployment/ig-deps-back",
+			"ig-deps-back":   "Deployment/ig-deps-front",
+			"ig-deps-orphan": "Deployment/ig-deps-missing",
+		}
+		for name, dependsOn := range dependencies {
+			d := newDeployment(types.NamespacedName{Name: name, Namespace: "default"}, 1)
+			d.Labels = map[string]string{"env": "deps-invalid"}
+			d.Annotations = map[string]string{kidlev1beta1.MetadataDependsOn: dependsOn}
+			Expect(k8sClient.Create(ctx, d)).Should(Succeed())
+		}
+		group := newIdlingGroup(groupKey, &metav1.LabelSelector{MatchLabels: map[string]string{"env": "deps-invalid"}})
+		group.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, group)).Should(Succeed())
+
+		messages := func() (map[string]string, error) {
+			if err := k8sClient.Get(ctx, groupKey, group); err != nil {
+				return nil, err
+			}
+			messages := map[string]string{}
+			for _, status := range group.Status.WorkloadStatuses {
+				if status.State == kidlev1beta1.WorkloadError {
+					messages[status.Ref.Name] = status.Message
+				}
+			}
+			return messages, nil
+		}
+		Eventually(messages, timeout, interval).Should(Equal(map[string]string{
+			"ig-deps-front":  "circular dependency in the kidle.kidle.dev/depends-on annotation: Deployment/ig-deps-front -> Deployment/ig-deps-back -> Deployment/ig-deps-front",
+			"ig-deps-back":   "circular dependency in the kidle.kidle.dev/depends-on annotation: Deployment/ig-deps-back -> Deployment/ig-deps-front -> Deployment/ig-deps-back",
+			"ig-deps-orphan": "unknown dependency Deployment/ig-deps-missing in the kidle.kidle.dev/depends-on annotation",
+		}))
+		Consistently(getReplicas(types.NamespacedName{Name: "ig-deps-front", Namespace: "default"}), time.Second, interval).Should(Equal(pointer.Int32(1)))
+	})
+})
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReasonDrifted is the reason of the Ready condition of a drifted workload left as is
+const ReasonDrifted = "Drifted"
+
+// reconcileDrift applies the drift policy when the idled workload has been rescaled by someone else.
+// It returns true if the workload must be left as is.
+func (r *IdlingResourceReconciler) reconcileDrift(ctx context.Context, instance *kidlev1beta1.IdlingResource, idler idler.Idler) (bool, error) {
+	if !instance.Spec.Idle {
+		return false, nil
+	}
+
+	// A drift notified for the current generation is left as is until the next idling
+	policy := instance.GetDriftPolicy()
+	if drift := instance.Status.LastDrift; drift != nil && drift.Policy == kidlev1beta1.DriftPolicyNotify &&
+		drift.ObservedGeneration == instance.Generation && policy == kidlev1beta1.DriftPolicyNotify {
+		setIdlingPhase(instance, kidlev1beta1.PhaseActive)
+		setCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonDrifted, drift.Message)
+		return true, nil
+	}
+
+	if !idler.HasDrifted() {
+		return false, nil
+	}
+
+	ref := instance.Spec.IdlingResourceRef
+	drift := &kidlev1beta1.DriftStatus{
+		Policy:             policy,
+		DetectionTime:      metav1.Time{Time: time.Now()},
+		ObservedGeneration: instance.Generation,
+	}
+	instance.Status.LastDrift = drift
+
+	switch policy {
+	case kidlev1beta1.DriftPolicyAdopt:
+		replicas, err := idler.AcceptDrift(ctx, true)
+		if err != nil {
+			return false, fmt.Errorf("unable to adopt the drift: %v", err)
+		}
+
+		// The workload is considered as waked up
+		adopted := instance.DeepCopy()
+		adopted.Spec.Idle = false
+		if err := r.Patch(ctx, adopted, client.MergeFrom(instance)); err != nil {
+			return false, fmt.Errorf("unable to wake up the idling resource: %v", err)
+		}
+		instance.Spec.Idle = false
+		instance.Generation = adopted.Generation
+
+		drift.Message = fmt.Sprintf("%s %s rescaled while idled, the workload is adopted", ref.Kind, ref.Name)
+		r.Event(instance, corev1.EventTypeNormal, "DriftAdopted", drift.Message)
+		instance.Status.LastWakeupTime = &drift.DetectionTime
+		instance.Status.PreviousReplicas = replicas
+		r.stopSavings(ctx, instance)
+		setIdlingPhase(instance, kidlev1beta1.PhaseActive)
+		return true, nil
+
+	case kidlev1beta1.DriftPolicyNotify:
+		if _, err := idler.AcceptDrift(ctx, false); err != nil {
+			return false, fmt.Errorf("unable to accept the drift: %v", err)
+		}
+
+		drift.Message = fmt.Sprintf("%s %s rescaled while idled, the workload is left running until the next idling", ref.Kind, ref.Name)
+		r.Event(instance, corev1.EventTypeWarning, "Drifted", drift.Message)
+		r.stopSavings(ctx, instance)
+		setIdlingPhase(instance, kidlev1beta1.PhaseActive)
+		setCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonDrifted, drift.Message)
+		return true, nil
+	}
+
+	// The workload is idled again by the reconciliation
+	drift.Message = fmt.Sprintf("%s %s rescaled while idled, the workload is idled again", ref.Kind, ref.Name)
+	r.Event(instance, corev1.EventTypeNormal, "DriftEnforced", drift.Message)
+	return false, nil
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+var _ = Describe("drift policy", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx = context.Background()
+	)
+
+	// idleDeployment creates an idled Deployment of 2 replicas referenced by an IdlingResource with the given drift policy
+	idleDeployment := func(irKey types.NamespacedName, deployKey types.NamespacedName, policy kidlev1beta1.DriftPolicy) {
+		Expect(k8sClient.Create(ctx, newDeployment(deployKey, 2))).Should(Succeed())
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.Idle = true
+		ir.Spec.DriftPolicy = policy
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		Eventually(func() (kidlev1beta1.IdlingResourcePhase, error) {
+			if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+				return "", err
+			}
+			return ir.Status.Phase, nil
+		}, timeout, interval).Should(Equal(kidlev1beta1.PhaseIdle))
+	}
+
+	// rescale sets the replicas of a Deployment
+	rescale := func(deployKey types.NamespacedName, replicas int32) {
+		Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			d := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, deployKey, d); err != nil {
+				return err
+			}
+			d.Spec.Replicas = pointer.Int32(replicas)
+			return k8sClient.Update(ctx, d)
+		})).Should(Succeed())
+	}
+
+	getReplicas := func(deployKey types.NamespacedName) func() (*int32, error) {
+		return func() (*int32, error) {
+			d := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, deployKey, d); err != nil {
+				return nil, err
+			}
+			return d.Spec.Replicas, nil
+		}
+	}
+
+	getLastDrift := func(irKey types.NamespacedName) func() (*kidlev1beta1.DriftStatus, error) {
+		return func() (*kidlev1beta1.DriftStatus, error) {
+			ir := &kidlev1beta1.IdlingResource{}
+			if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+				return nil, err
+			}
+			return ir.Status.LastDrift, nil
+		}
+	}
+
+	Context("Enforce policy", func() {
+		irKey := types.NamespacedName{Name: "ir-drift-enforce", Namespace: "default"}
+		deployKey := types.NamespacedName{Name: "drift-enforce", Namespace: "default"}
+
+		It("Should idle the rescaled Deployment again", func() {
+			idleDeployment(irKey, deployKey, "")
+			rescale(deployKey, 3)
+
+			By("Checking that the drift is reported")
+			Eventually(getLastDrift(irKey), timeout, interval).ShouldNot(BeNil())
+			drift, _ := getLastDrift(irKey)()
+			Expect(drift.Policy).Should(Equal(kidlev1beta1.DriftPolicyEnforce))
+
+			By("Checking that the Deployment is idled again")
+			Eventually(getReplicas(deployKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+
+			By("Checking that the replicas before the drift are restored on wakeup")
+			Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+			Eventually(getReplicas(deployKey), timeout, interval).Should(Equal(pointer.Int32(2)))
+		})
+	})
+
+	Context("Adopt policy", func() {
+		irKey := types.NamespacedName{Name: "ir-drift-adopt", Namespace: "default"}
+		deployKey := types.NamespacedName{Name: "drift-adopt", Namespace: "default"}
+
+		It("Should adopt the rescaled Deployment", func() {
+			idleDeployment(irKey, deployKey, kidlev1beta1.DriftPolicyAdopt)
+			rescale(deployKey, 3)
+
+			By("Checking that the IdlingResource is waked up")
+			ir := &kidlev1beta1.IdlingResource{}
+			Eventually(func() (bool, error) {
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return false, err
+				}
+				return ir.Spec.Idle, nil
+			}, timeout, interval).Should(BeFalse())
+
+			Eventually(func() (*int32, error) {
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return nil, err
+				}
+				return ir.Status.PreviousReplicas, nil
+			}, timeout, interval).Should(Equal(pointer.Int32(3)))
+			Expect(ir.Status.Phase).Should(Equal(kidlev1beta1.PhaseActive))
+			Expect(ir.Status.LastDrift).ShouldNot(BeNil())
+			Expect(ir.Status.LastDrift.Policy).Should(Equal(kidlev1beta1.DriftPolicyAdopt))
+
+			By("Checking that the savings stop growing once the workload is adopted")
+			Expect(ir.Status.Savings).ShouldNot(BeNil())
+			Expect(ir.Status.Savings.IdledCPU).Should(BeNil())
+			Expect(ir.Status.Savings.CPUCoreHours).ShouldNot(BeEmpty())
+			now := time.Now()
+			Expect(getSavingsTotals(ir, nil, now.Add(time.Hour))).Should(Equal(getSavingsTotals(ir, nil, now)))
+
+			By("Checking that the adopted replicas are restored after the next idling")
+			Expect(setIdleFlag(ctx, irKey, true)).Should(Succeed())
+			Eventually(getReplicas(deployKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+			Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+			Eventually(getReplicas(deployKey), timeout, interval).Should(Equal(pointer.Int32(3)))
+		})
+	})
+
+	Context("Notify policy", func() {
+		irKey := types.NamespacedName{Name: "ir-drift-notify", Namespace: "default"}
+		deployKey := types.NamespacedName{Name: "drift-notify", Namespace: "default"}
+
+		It("Should leave the rescaled Deployment running", func() {
+			idleDeployment(irKey, deployKey, kidlev1beta1.DriftPolicyNotify)
+			rescale(deployKey, 3)
+
+			By("Checking that the drift is reported")
+			ir := &kidlev1beta1.IdlingResource{}
+			Eventually(func() (bool, error) {
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return false, err
+				}
+				return meta.IsStatusConditionFalse(ir.Status.Conditions, kidlev1beta1.ConditionReady), nil
+			}, timeout, interval).Should(BeTrue())
+			Expect(meta.FindStatusCondition(ir.Status.Conditions, kidlev1beta1.ConditionReady).Reason).Should(Equal(ReasonDrifted))
+			Expect(ir.Status.LastDrift).ShouldNot(BeNil())
+			Expect(ir.Status.LastDrift.Policy).Should(Equal(kidlev1beta1.DriftPolicyNotify))
+			Expect(ir.Spec.Idle).Should(BeTrue())
+
+			By("Checking that the Deployment is left running")
+			Consistently(getReplicas(deployKey), time.Second, interval).Should(Equal(pointer.Int32(3)))
+
+			By("Checking that the Deployment is idled by the next idling")
+			Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+			Expect(setIdleFlag(ctx, irKey, true)).Should(Succeed())
+			Eventually(getReplicas(deployKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+		})
+	})
+})
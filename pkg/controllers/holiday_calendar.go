@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/calendar"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"github.com/kidle-dev/kidle/pkg/utils/schedule"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// HolidayCalendarRefreshInterval is the maximum interval between two loads of a holiday calendar
+	HolidayCalendarRefreshInterval = time.Hour
+
+	// HolidayStartingDeadlineSeconds is the starting deadline of the wakeup CronJobs with a holiday calendar.
+	// The wakeups missed while the CronJob was suspended are not run when it is resumed.
+	HolidayStartingDeadlineSeconds = 300
+)
+
+// getHoliday returns the holiday occurring now, if any, and the duration until the next change of holiday
+func (r *CronStrategiesReconciler) getHoliday(ctx context.Context, instance *CronOwner, now time.Time) (*calendar.Occurrence, time.Duration, error) {
+	ref := instance.HolidayCalendar
+	key := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = instance.GetNamespace()
+	}
+	dataKey := ref.Key
+	if dataKey == "" {
+		dataKey = kidlev1beta1.DefaultHolidayCalendarKey
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		return nil, 0, fmt.Errorf("unable to get configmap %s: %v", key, err)
+	}
+	data, found := cm.Data[dataKey]
+	if !found {
+		return nil, 0, fmt.Errorf("key %s not found in configmap %s", dataKey, key)
+	}
+
+	location, err := schedule.LoadLocation(instance.WakeupStrategy.TimeZone)
+	if err != nil {
+		return nil, 0, err
+	}
+	cal, err := calendar.Parse(strings.NewReader(data), location)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid calendar in configmap %s: %v", key, err)
+	}
+
+	requeueAfter := HolidayCalendarRefreshInterval
+	holiday, found := cal.At(now)
+	if found {
+		if d := holiday.End.Sub(now); d < requeueAfter {
+			requeueAfter = d
+		}
+		return holiday, requeueAfter, nil
+	}
+	if next := cal.NextStart(now); !next.IsZero() && next.Sub(now) < requeueAfter {
+		requeueAfter = next.Sub(now)
+	}
+	return nil, requeueAfter, nil
+}
+
+// recordSkippedWakeup records an event for the last wakeup scheduled during the holiday.
+// The skipped wakeup is saved on the CronJob to record it only once.
+// It returns the duration until the next scheduled wakeup.
+func (r *CronStrategiesReconciler) recordSkippedWakeup(ctx context.Context, instance *CronOwner, cjValues *CronJobValues, holiday *calendar.Occurrence) (time.Duration, error) {
+	s, err := schedule.Parse(cjValues.strategy.Schedule, cjValues.strategy.TimeZone)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var last time.Time
+	for t := s.Next(holiday.Start.Add(-time.Second)); !t.IsZero() && !t.After(now); t = s.Next(t) {
+		last = t
+	}
+	requeueAfter := s.Next(now).Sub(now)
+	if last.IsZero() {
+		return requeueAfter, nil
+	}
+
+	cronJob, err := r.getCronJob(ctx, cjValues.key)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get cronJob: %v", err)
+	}
+	skipped := last.Format(time.RFC3339)
+	if value, _ := k8s.GetAnnotation(cronJob, kidlev1beta1.MetadataLastSkippedWakeup); value == skipped {
+		return requeueAfter, nil
+	}
+
+	original := cronJob.DeepCopy()
+	k8s.AddAnnotation(cronJob, kidlev1beta1.MetadataLastSkippedWakeup, skipped)
+	if err := r.patchCronJob(ctx, original, cronJob); err != nil {
+		return 0, fmt.Errorf("unable to patch cronJob: %v", err)
+	}
+	summary := holiday.Summary
+	if summary == "" {
+		summary = "a holiday"
+	}
+	r.Event(instance.Object, corev1.EventTypeNormal, "WakeupSkipped", fmt.Sprintf("Wakeup scheduled at %s skipped during %s", skipped, summary))
+	return requeueAfter, nil
+}
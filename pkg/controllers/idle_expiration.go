@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/schedule"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// IdleExpirationLookback is the period searched for the last runs of the cron strategies when an idle flag expires
+const IdleExpirationLookback = 31 * 24 * time.Hour
+
+// idleAfterExpiration returns the idle flag dictated by the cron strategies at the given time.
+// The workload is idled if the idling strategy has run after the wakeup strategy.
+// The idle flag is reversed if none of the strategies has run.
+func idleAfterExpiration(idle bool, idling *kidlev1beta1.CronStrategy, wakeup *kidlev1beta1.CronStrategy, now time.Time) (bool, error) {
+	var lastIdle, lastWakeup time.Time
+	var err error
+	if idling != nil {
+		if lastIdle, err = schedule.Prev(idling.Schedule, idling.TimeZone, now, IdleExpirationLookback); err != nil {
+			return idle, err
+		}
+	}
+	if wakeup != nil {
+		if lastWakeup, err = schedule.Prev(wakeup.Schedule, wakeup.TimeZone, now, IdleExpirationLookback); err != nil {
+			return idle, err
+		}
+	}
+
+	if lastIdle.IsZero() && lastWakeup.IsZero() {
+		return !idle, nil
+	}
+	return lastIdle.After(lastWakeup), nil
+}
+
+// reconcileIdleExpiration resets the idle flag of an IdlingResource or an IdlingGroup once its expiration time has passed.
+// idle and expiration point to the fields of the object, they are patched with the idle flag dictated by the cron strategies.
+func reconcileIdleExpiration(ctx context.Context, c client.Client, recorder record.EventRecorder, instance client.Object,
+	idle *bool, expiration **metav1.Time, idlingStrategy *kidlev1beta1.IdlingStrategy, wakeupStrategy *kidlev1beta1.WakeupStrategy) (reconcile.Result, error) {
+	if *expiration == nil || instance.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+	now := time.Now()
+	if now.Before((*expiration).Time) {
+		return reconcile.Result{RequeueAfter: (*expiration).Sub(now)}, nil
+	}
+
+	var idling, wakeup *kidlev1beta1.CronStrategy
+	if idlingStrategy != nil {
+		idling = idlingStrategy.CronStrategy
+	}
+	if wakeupStrategy != nil {
+		wakeup = wakeupStrategy.CronStrategy
+	}
+	expiredIdle, err := idleAfterExpiration(*idle, idling, wakeup, now)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("unable to evaluate the cron strategies: %v", err)
+	}
+
+	patch := client.MergeFrom(instance.DeepCopyObject().(client.Object))
+	*idle = expiredIdle
+	*expiration = nil
+	if err := c.Patch(ctx, instance, patch); err != nil {
+		return reconcile.Result{}, fmt.Errorf("unable to reset the expired idle flag: %v", err)
+	}
+	recorder.Event(instance, corev1.EventTypeNormal, "IdleExpired", fmt.Sprintf("The idle flag has expired, it is reset to %t", expiredIdle))
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("idle expiration", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx = context.Background()
+	)
+
+	DescribeTable("returns the idle flag dictated by the cron strategies",
+		func(idle bool, idling *kidlev1beta1.CronStrategy, wakeup *kidlev1beta1.CronStrategy, expected bool) {
+			// Monday 22:00 UTC
+			now := time.Date(2021, 10, 4, 22, 0, 0, 0, time.UTC)
+			Expect(idleAfterExpiration(idle, idling, wakeup, now)).To(Equal(expected))
+		},
+		Entry("after the idling", false, &kidlev1beta1.CronStrategy{Schedule: "0 20 * * 1-5"}, &kidlev1beta1.CronStrategy{Schedule: "30 7 * * 1-5"}, true),
+		Entry("after the wakeup", true, &kidlev1beta1.CronStrategy{Schedule: "0 23 * * 1-5"}, &kidlev1beta1.CronStrategy{Schedule: "30 7 * * 1-5"}, false),
+		Entry("in another time zone", false,
+			&kidlev1beta1.CronStrategy{Schedule: "0 20 * * 1-5", TimeZone: "America/New_York"},
+			&kidlev1beta1.CronStrategy{Schedule: "30 7 * * 1-5", TimeZone: "America/New_York"}, false),
+		Entry("with an idling strategy only", false, &kidlev1beta1.CronStrategy{Schedule: "0 20 * * 1-5"}, nil, true),
+		Entry("without cron strategies", false, nil, nil, true),
+		Entry("without cron strategies when idled", true, nil, nil, false),
+	)
+
+	It("Should reset the idle flag once expired", func() {
+		irKey := types.NamespacedName{Name: "ir-expiration", Namespace: "default"}
+		deployKey := types.NamespacedName{Name: "expiration", Namespace: "default"}
+
+		By("Waking up the Deployment for 2 seconds")
+		Expect(k8sClient.Create(ctx, newDeployment(deployKey, 2))).Should(Succeed())
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.IdleExpirationTime = &metav1.Time{Time: time.Now().Add(2 * time.Second)}
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking that the Deployment is idled after the expiration")
+		Eventually(func() (bool, error) {
+			if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+				return false, err
+			}
+			return ir.Spec.Idle, nil
+		}, timeout, interval).Should(BeTrue())
+		Expect(ir.Spec.IdleExpirationTime).Should(BeNil())
+
+		Eventually(func() (*int32, error) {
+			d := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, deployKey, d); err != nil {
+				return nil, err
+			}
+			return d.Spec.Replicas, nil
+		}, timeout, interval).Should(Equal(pointer.Int32(0)))
+	})
+})
@@ -0,0 +1,136 @@
+package idler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AutoscalerGroupVersion is the API version of the HorizontalPodAutoscalers served by the cluster.
+// The autoscalers are read and patched with this version, so that their metrics and behavior are kept.
+var AutoscalerGroupVersion = autoscalingv2beta2.SchemeGroupVersion
+
+// findAutoscaler returns the HorizontalPodAutoscaler scaling a workload, nil if the workload has none
+func findAutoscaler(ctx context.Context, c client.Client, workload metav1.Object, kind string) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	hpas := &unstructured.UnstructuredList{}
+	hpas.SetGroupVersionKind(AutoscalerGroupVersion.WithKind("HorizontalPodAutoscalerList"))
+	if err := c.List(ctx, hpas, client.InNamespace(workload.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("unable to list horizontal pod autoscalers: %v", err)
+	}
+	for i := range hpas.Items {
+		// The replicas bounds and the scale target are the same in autoscaling/v2 and autoscaling/v2beta2
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(hpas.Items[i].Object, hpa); err != nil {
+			return nil, fmt.Errorf("unable to convert horizontal pod autoscaler %s: %v", hpas.Items[i].GetName(), err)
+		}
+		target := hpa.Spec.ScaleTargetRef
+		if target.Kind == kind && target.Name == workload.GetName() {
+			return hpa, nil
+		}
+	}
+	return nil, nil
+}
+
+// patchAutoscaler sets the replicas bounds of an autoscaler, the other fields are left untouched.
+// A nil min replicas removes it, the autoscaler defaults it to 1.
+func patchAutoscaler(ctx context.Context, c client.Client, namespace string, name string, minReplicas *int32, maxReplicas int32) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"minReplicas": minReplicas,
+			"maxReplicas": maxReplicas,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	hpa := &unstructured.Unstructured{}
+	hpa.SetGroupVersionKind(AutoscalerGroupVersion.WithKind("HorizontalPodAutoscaler"))
+	hpa.SetNamespace(namespace)
+	hpa.SetName(name)
+	return c.Patch(ctx, hpa, client.RawPatch(types.MergePatchType, patch))
+}
+
+// saveAutoscaler records the replicas bounds of the autoscaler in the annotations of the workload.
+// The bounds recorded by a previous idling are kept, the autoscaler is already neutralised.
+func saveAutoscaler(workload metav1.Object, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	if k8s.HasAnnotation(workload, kidlev1beta1.MetadataAutoscaler) {
+		return
+	}
+	minReplicas := ""
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = strconv.Itoa(int(*hpa.Spec.MinReplicas))
+	}
+	k8s.AddAnnotation(workload, kidlev1beta1.MetadataAutoscaler, hpa.Name)
+	k8s.AddAnnotation(workload, kidlev1beta1.MetadataAutoscalerMinReplicas, minReplicas)
+	k8s.AddAnnotation(workload, kidlev1beta1.MetadataAutoscalerMaxReplicas, strconv.Itoa(int(hpa.Spec.MaxReplicas)))
+}
+
+// neutraliseAutoscaler pins the replicas bounds of the autoscaler to 1.
+// The autoscaler of a workload scaled to 0 is then disabled, even with the HPAScaleToZero feature gate.
+func neutraliseAutoscaler(ctx context.Context, c client.Client, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) error {
+	return patchAutoscaler(ctx, c, hpa.Namespace, hpa.Name, pointer.Int32(1), 1)
+}
+
+// restoreAutoscaler restores the replicas bounds of the autoscaler recorded in the annotations of the workload.
+// It returns the min replicas of the autoscaler to wake up the workload with, the autoscaler decides of the replicas then.
+// It returns nil if the workload has no recorded autoscaler or if the autoscaler has been deleted.
+func restoreAutoscaler(ctx context.Context, c client.Client, workload metav1.Object) (*int32, error) {
+	name, found := k8s.GetAnnotation(workload, kidlev1beta1.MetadataAutoscaler)
+	if !found {
+		return nil, nil
+	}
+
+	var minReplicas *int32
+	if v, _ := k8s.GetAnnotation(workload, kidlev1beta1.MetadataAutoscalerMinReplicas); v != "" {
+		replicas, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %v", kidlev1beta1.MetadataAutoscalerMinReplicas, err)
+		}
+		minReplicas = pointer.Int32(int32(replicas))
+	}
+	v, _ := k8s.GetAnnotation(workload, kidlev1beta1.MetadataAutoscalerMaxReplicas)
+	maxReplicas, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", kidlev1beta1.MetadataAutoscalerMaxReplicas, err)
+	}
+
+	err = patchAutoscaler(ctx, c, workload.GetNamespace(), name, minReplicas, int32(maxReplicas))
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore the horizontal pod autoscaler %s: %v", name, err)
+	}
+
+	// The min replicas of an autoscaler default to 1
+	if minReplicas == nil || *minReplicas == 0 {
+		return pointer.Int32(1), nil
+	}
+	return minReplicas, nil
+}
+
+// autoscalerAnnotations are the annotations recording the autoscaler of a workload
+var autoscalerAnnotations = []string{
+	kidlev1beta1.MetadataAutoscaler,
+	kidlev1beta1.MetadataAutoscalerMinReplicas,
+	kidlev1beta1.MetadataAutoscalerMaxReplicas,
+}
+
+// removeAutoscalerAnnotations removes the recorded autoscaler from the annotations of the workload
+func removeAutoscalerAnnotations(workload metav1.Object) {
+	for _, annotation := range autoscalerAnnotations {
+		k8s.RemoveAnnotation(workload, annotation)
+	}
+}
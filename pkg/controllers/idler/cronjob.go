@@ -6,20 +6,46 @@ import (
 	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
 	"github.com/kidle-dev/kidle/pkg/utils/k8s"
 	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
 )
 
+// CronJobRegistration returns the registration of the CronJobs of an API version, batch/v1 or batch/v1beta1.
+// The CronJobs are registered with the API version served by the cluster, found by discovery.
+func CronJobRegistration(gv schema.GroupVersion) Registration {
+	registration := Registration{
+		GroupVersionKind: gv.WithKind("CronJob"),
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler {
+			return NewCronJobIdler(c, log, workload)
+		},
+		State: func(workload client.Object) string {
+			return strconv.FormatBool(isSuspended(workload))
+		},
+	}
+	if gv == batchv1beta1.SchemeGroupVersion {
+		registration.NewObject = func() client.Object { return &batchv1beta1.CronJob{} }
+		registration.NewList = func() client.ObjectList { return &batchv1beta1.CronJobList{} }
+	} else {
+		registration.NewObject = func() client.Object { return &batchv1.CronJob{} }
+		registration.NewList = func() client.ObjectList { return &batchv1.CronJobList{} }
+	}
+	return registration
+}
+
+// CronJobIdler idles a batch/v1 or a batch/v1beta1 CronJob by suspending it
 type CronJobIdler struct {
 	client.Client
 	Log     logr.Logger
-	CronJob *batchv1beta1.CronJob
+	CronJob client.Object
 	ObjectIdler
 }
 
-func NewCronJobIdler(client client.Client, log logr.Logger, cronjob *batchv1beta1.CronJob) *CronJobIdler {
+func NewCronJobIdler(client client.Client, log logr.Logger, cronjob client.Object) *CronJobIdler {
 	return &CronJobIdler{
 		Client:      client,
 		Log:         log,
@@ -29,53 +55,118 @@ func NewCronJobIdler(client client.Client, log logr.Logger, cronjob *batchv1beta
 }
 
 func (i *CronJobIdler) NeedIdle(instance *kidlev1beta1.IdlingResource) bool {
-	return instance.Spec.Idle && !*i.CronJob.Spec.Suspend
+	return instance.Spec.Idle && !isSuspended(i.CronJob)
 }
 
 func (i *CronJobIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) bool {
-	return !instance.Spec.Idle && *i.CronJob.Spec.Suspend
+	return !instance.Spec.Idle && isSuspended(i.CronJob)
 }
 
 func (i *CronJobIdler) Idle(ctx context.Context) error {
-	if !*i.CronJob.Spec.Suspend {
+	if !isSuspended(i.CronJob) {
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			if err := i.Get(ctx, types.NamespacedName{Namespace: i.CronJob.Namespace, Name: i.CronJob.Name}, i.CronJob); err != nil {
-				i.Log.Error(err, "unable to get cronjob","name", i.CronJob.Name)
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.CronJob.GetNamespace(), Name: i.CronJob.GetName()}, i.CronJob); err != nil {
+				i.Log.Error(err, "unable to get cronjob", "name", i.CronJob.GetName())
 				return err
 			}
-			k8s.AddAnnotation(&i.CronJob.ObjectMeta, kidlev1beta1.MetadataExpectedState, "true")
-			i.CronJob.Spec.Suspend = pointer.Bool(true)
+			k8s.AddAnnotation(i.CronJob, kidlev1beta1.MetadataExpectedState, "true")
+			setSuspend(i.CronJob, true)
 			return i.Update(ctx, i.CronJob)
 		})
 		if err != nil {
-			i.Log.Error(err, "unable to suspend cronjob", "name", i.CronJob.Name)
+			i.Log.Error(err, "unable to suspend cronjob", "name", i.CronJob.GetName())
 			return err
 		}
-		i.Log.V(1).Info("cronjob suspended", "name", i.CronJob.Name)
+		i.Log.V(1).Info("cronjob suspended", "name", i.CronJob.GetName())
 	} else {
-		i.Log.V(2).Info("cronjob already suspended", "name", i.CronJob.Name)
+		i.Log.V(2).Info("cronjob already suspended", "name", i.CronJob.GetName())
 	}
 	return nil
 }
 
 func (i *CronJobIdler) Wakeup(ctx context.Context) (*int32, error) {
-	if *i.CronJob.Spec.Suspend {
+	if isSuspended(i.CronJob) {
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			if err := i.Get(ctx, types.NamespacedName{Namespace: i.CronJob.Namespace, Name: i.CronJob.Name}, i.CronJob); err != nil {
-				i.Log.Error(err, "unable to get cronjob","name", i.CronJob.Name)
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.CronJob.GetNamespace(), Name: i.CronJob.GetName()}, i.CronJob); err != nil {
+				i.Log.Error(err, "unable to get cronjob", "name", i.CronJob.GetName())
 				return err
 			}
-			k8s.AddAnnotation(&i.CronJob.ObjectMeta, kidlev1beta1.MetadataExpectedState, "false")
-			i.CronJob.Spec.Suspend = pointer.Bool(false)
+			k8s.AddAnnotation(i.CronJob, kidlev1beta1.MetadataExpectedState, "false")
+			setSuspend(i.CronJob, false)
 			return i.Update(ctx, i.CronJob)
 		})
 		if err != nil {
-			i.Log.Error(err, "unable to wakeup cronjob", "name", i.CronJob.Name)
+			i.Log.Error(err, "unable to wakeup cronjob", "name", i.CronJob.GetName())
 			return nil, err
 		}
-		i.Log.V(1).Info("cronjob waked up", "name", i.CronJob.Name)
+		i.Log.V(1).Info("cronjob waked up", "name", i.CronJob.GetName())
 	} else {
-		i.Log.V(2).Info("cronjob already waked up", "name", i.CronJob.Name)
+		i.Log.V(2).Info("cronjob already waked up", "name", i.CronJob.GetName())
+	}
+	return nil, nil
+}
+
+// HasDrifted returns true if the suspended cronjob has been resumed by someone else
+func (i *CronJobIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(i.CronJob, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "true" && !isSuspended(i.CronJob)
+}
+
+// AcceptDrift saves the current suspend flag of the cronjob as its expected state.
+// A cronjob has no replicas to adopt.
+func (i *CronJobIdler) AcceptDrift(ctx context.Context, _ bool) (*int32, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.CronJob.GetNamespace(), Name: i.CronJob.GetName()}, i.CronJob); err != nil {
+			return err
+		}
+		k8s.AddAnnotation(i.CronJob, kidlev1beta1.MetadataExpectedState, strconv.FormatBool(isSuspended(i.CronJob)))
+		return i.Update(ctx, i.CronJob)
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of cronjob", "name", i.CronJob.GetName())
+		return nil, err
 	}
 	return nil, nil
 }
+
+// IsReady returns true if the cronjob is not suspended, a cronjob has no replicas to wait for
+func (i *CronJobIdler) IsReady() bool {
+	return !isSuspended(i.CronJob)
+}
+
+// IsIdled returns true if the cronjob is suspended and none of its jobs is running
+func (i *CronJobIdler) IsIdled() bool {
+	return isSuspended(i.CronJob) && activeJobs(i.CronJob) == 0
+}
+
+// isSuspended returns the suspend flag of a batch/v1 or a batch/v1beta1 CronJob
+func isSuspended(cronJob client.Object) bool {
+	switch cj := cronJob.(type) {
+	case *batchv1.CronJob:
+		return cj.Spec.Suspend != nil && *cj.Spec.Suspend
+	case *batchv1beta1.CronJob:
+		return cj.Spec.Suspend != nil && *cj.Spec.Suspend
+	}
+	return false
+}
+
+// setSuspend sets the suspend flag of a batch/v1 or a batch/v1beta1 CronJob
+func setSuspend(cronJob client.Object, suspend bool) {
+	switch cj := cronJob.(type) {
+	case *batchv1.CronJob:
+		cj.Spec.Suspend = pointer.Bool(suspend)
+	case *batchv1beta1.CronJob:
+		cj.Spec.Suspend = pointer.Bool(suspend)
+	}
+}
+
+// activeJobs returns the number of running jobs of a batch/v1 or a batch/v1beta1 CronJob
+func activeJobs(cronJob client.Object) int {
+	switch cj := cronJob.(type) {
+	case *batchv1.CronJob:
+		return len(cj.Status.Active)
+	case *batchv1beta1.CronJob:
+		return len(cj.Status.Active)
+	}
+	return 0
+}
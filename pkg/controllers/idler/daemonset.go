@@ -0,0 +1,167 @@
+package idler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+)
+
+func init() {
+	MustRegister(Registration{
+		GroupVersionKind: appsv1.SchemeGroupVersion.WithKind("DaemonSet"),
+		NewObject:        func() client.Object { return &appsv1.DaemonSet{} },
+		NewList:          func() client.ObjectList { return &appsv1.DaemonSetList{} },
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler {
+			return NewDaemonSetIdler(c, log, workload.(*appsv1.DaemonSet))
+		},
+		State: func(workload client.Object) string {
+			return strconv.FormatBool(isDaemonSetIdled(workload.(*appsv1.DaemonSet)))
+		},
+	})
+}
+
+// DaemonSetIdler idles a DaemonSet by replacing its nodeSelector with a selector matching no node.
+// The original nodeSelector is saved in an annotation and restored on wakeup.
+type DaemonSetIdler struct {
+	client.Client
+	Log       logr.Logger
+	DaemonSet *appsv1.DaemonSet
+	ObjectIdler
+}
+
+func NewDaemonSetIdler(client client.Client, log logr.Logger, daemonSet *appsv1.DaemonSet) *DaemonSetIdler {
+	return &DaemonSetIdler{
+		Client:      client,
+		Log:         log,
+		DaemonSet:   daemonSet,
+		ObjectIdler: NewObjectIdler(client, log, daemonSet, kidlev1beta1.MetadataPreviousNodeSelector),
+	}
+}
+
+func (i *DaemonSetIdler) NeedIdle(instance *kidlev1beta1.IdlingResource) bool {
+	return instance.Spec.Idle && !isDaemonSetIdled(i.DaemonSet)
+}
+
+func (i *DaemonSetIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) bool {
+	return !instance.Spec.Idle && isDaemonSetIdled(i.DaemonSet)
+}
+
+func (i *DaemonSetIdler) Idle(ctx context.Context) error {
+	if !isDaemonSetIdled(i.DaemonSet) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.DaemonSet.Namespace, Name: i.DaemonSet.Name}, i.DaemonSet); err != nil {
+				i.Log.Error(err, "unable to get daemonset", "name", i.DaemonSet.Name)
+				return err
+			}
+			// The nodeSelector of a drifted daemonset is not saved, the daemonset is restored to its nodeSelector before the drift
+			if expected, _ := k8s.GetAnnotation(&i.DaemonSet.ObjectMeta, kidlev1beta1.MetadataExpectedState); expected != "true" {
+				nodeSelector, err := json.Marshal(i.DaemonSet.Spec.Template.Spec.NodeSelector)
+				if err != nil {
+					return fmt.Errorf("unable to save the nodeSelector: %v", err)
+				}
+				k8s.AddAnnotation(&i.DaemonSet.ObjectMeta, kidlev1beta1.MetadataPreviousNodeSelector, string(nodeSelector))
+			}
+			k8s.AddAnnotation(&i.DaemonSet.ObjectMeta, kidlev1beta1.MetadataExpectedState, "true")
+			i.DaemonSet.Spec.Template.Spec.NodeSelector = map[string]string{kidlev1beta1.IdledNodeSelector: "true"}
+			return i.Update(ctx, i.DaemonSet)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to idle daemonset", "name", i.DaemonSet.Name)
+			return err
+		}
+		i.Log.V(1).Info("daemonset idled", "name", i.DaemonSet.Name)
+	} else {
+		i.Log.V(2).Info("daemonset already idled", "name", i.DaemonSet.Name)
+	}
+	return nil
+}
+
+func (i *DaemonSetIdler) Wakeup(ctx context.Context) (*int32, error) {
+	if isDaemonSetIdled(i.DaemonSet) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.DaemonSet.Namespace, Name: i.DaemonSet.Name}, i.DaemonSet); err != nil {
+				i.Log.Error(err, "unable to get daemonset", "name", i.DaemonSet.Name)
+				return err
+			}
+			nodeSelector, err := getPreviousNodeSelector(i.DaemonSet)
+			if err != nil {
+				return err
+			}
+			k8s.AddAnnotation(&i.DaemonSet.ObjectMeta, kidlev1beta1.MetadataExpectedState, "false")
+			k8s.RemoveAnnotation(&i.DaemonSet.ObjectMeta, kidlev1beta1.MetadataPreviousNodeSelector)
+			i.DaemonSet.Spec.Template.Spec.NodeSelector = nodeSelector
+			return i.Update(ctx, i.DaemonSet)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to wakeup daemonset", "name", i.DaemonSet.Name)
+			return nil, err
+		}
+		i.Log.V(1).Info("daemonset waked up", "name", i.DaemonSet.Name)
+	} else {
+		i.Log.V(2).Info("daemonset already waked up", "name", i.DaemonSet.Name)
+	}
+	return nil, nil
+}
+
+// HasDrifted returns true if the nodeSelector of the idled daemonset has been changed by someone else
+func (i *DaemonSetIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(&i.DaemonSet.ObjectMeta, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "true" && !isDaemonSetIdled(i.DaemonSet)
+}
+
+// AcceptDrift saves the current state of the daemonset as its expected state.
+// A daemonset has no replicas to adopt.
+func (i *DaemonSetIdler) AcceptDrift(ctx context.Context, _ bool) (*int32, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.DaemonSet.Namespace, Name: i.DaemonSet.Name}, i.DaemonSet); err != nil {
+			return err
+		}
+		k8s.AddAnnotation(&i.DaemonSet.ObjectMeta, kidlev1beta1.MetadataExpectedState, strconv.FormatBool(isDaemonSetIdled(i.DaemonSet)))
+		return i.Update(ctx, i.DaemonSet)
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of daemonset", "name", i.DaemonSet.Name)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// IsReady returns true if the daemonset is running and its pods are updated and ready on all the selected nodes
+func (i *DaemonSetIdler) IsReady() bool {
+	status := i.DaemonSet.Status
+	return !isDaemonSetIdled(i.DaemonSet) && status.ObservedGeneration >= i.DaemonSet.Generation &&
+		status.UpdatedNumberScheduled >= status.DesiredNumberScheduled && status.NumberReady >= status.DesiredNumberScheduled
+}
+
+// IsIdled returns true if the daemonset is idled and none of its pods is running
+func (i *DaemonSetIdler) IsIdled() bool {
+	status := i.DaemonSet.Status
+	return isDaemonSetIdled(i.DaemonSet) && status.CurrentNumberScheduled == 0 && status.NumberMisscheduled == 0
+}
+
+// isDaemonSetIdled returns true if the daemonset selects the nodes of the idled daemonsets
+func isDaemonSetIdled(daemonSet *appsv1.DaemonSet) bool {
+	_, found := daemonSet.Spec.Template.Spec.NodeSelector[kidlev1beta1.IdledNodeSelector]
+	return found
+}
+
+// getPreviousNodeSelector decodes the nodeSelector saved in the daemonset annotations, nil if none was saved
+func getPreviousNodeSelector(daemonSet *appsv1.DaemonSet) (map[string]string, error) {
+	value, found := k8s.GetAnnotation(&daemonSet.ObjectMeta, kidlev1beta1.MetadataPreviousNodeSelector)
+	if !found {
+		return nil, nil
+	}
+	var nodeSelector map[string]string
+	if err := json.Unmarshal([]byte(value), &nodeSelector); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", kidlev1beta1.MetadataPreviousNodeSelector, err)
+	}
+	return nodeSelector, nil
+}
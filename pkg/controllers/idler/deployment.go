@@ -13,6 +13,20 @@ import (
 	"strconv"
 )
 
+func init() {
+	MustRegister(Registration{
+		GroupVersionKind: appsv1.SchemeGroupVersion.WithKind("Deployment"),
+		NewObject:        func() client.Object { return &appsv1.Deployment{} },
+		NewList:          func() client.ObjectList { return &appsv1.DeploymentList{} },
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler {
+			return NewDeploymentIdler(c, log, workload.(*appsv1.Deployment))
+		},
+		State: func(workload client.Object) string {
+			return strconv.Itoa(int(*workload.(*appsv1.Deployment).Spec.Replicas))
+		},
+	})
+}
+
 type DeploymentIdler struct {
 	client.Client
 	Log        logr.Logger
@@ -25,7 +39,7 @@ func NewDeploymentIdler(client client.Client, log logr.Logger, deployment *appsv
 		Client:      client,
 		Log:         log,
 		Deployment:  deployment,
-		ObjectIdler: NewObjectIdler(client, log, deployment),
+		ObjectIdler: NewObjectIdler(client, log, deployment, autoscalerAnnotations...),
 	}
 }
 
@@ -39,12 +53,23 @@ func (i *DeploymentIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) bool
 
 func (i *DeploymentIdler) Idle(ctx context.Context) error {
 	if i.Deployment.Spec.Replicas != pointer.Int32(0) {
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// The autoscaler of the deployment is neutralised once its replicas bounds are saved
+		hpa, err := findAutoscaler(ctx, i.Client, i.Deployment, "Deployment")
+		if err != nil {
+			return err
+		}
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			if err := i.Get(ctx, types.NamespacedName{Namespace: i.Deployment.Namespace, Name: i.Deployment.Name}, i.Deployment); err != nil {
 				i.Log.Error(err, "unable to get deployment","name", i.Deployment.Name)
 				return err
 			}
-			k8s.AddAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataPreviousReplicas, strconv.Itoa(int(*i.Deployment.Spec.Replicas)))
+			if hpa != nil {
+				saveAutoscaler(&i.Deployment.ObjectMeta, hpa)
+			}
+			// The replicas of a drifted workload are not saved, the workload is restored to its replicas before the drift
+			if expected, _ := k8s.GetAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataExpectedState); expected != "0" {
+				k8s.AddAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataPreviousReplicas, strconv.Itoa(int(*i.Deployment.Spec.Replicas)))
+			}
 			k8s.AddAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataExpectedState, "0")
 			i.Deployment.Spec.Replicas = pointer.Int32(0)
 			return i.Update(ctx, i.Deployment)
@@ -53,6 +78,12 @@ func (i *DeploymentIdler) Idle(ctx context.Context) error {
 			i.Log.Error(err, "unable to downscale deployment", "name", i.Deployment.Name)
 			return err
 		}
+		if hpa != nil {
+			if err := neutraliseAutoscaler(ctx, i.Client, hpa); err != nil {
+				i.Log.Error(err, "unable to neutralise the autoscaler of deployment", "name", i.Deployment.Name)
+				return err
+			}
+		}
 		i.Log.V(1).Info("deployment idled", "name", i.Deployment.Name)
 	} else {
 		i.Log.V(2).Info("deployment already idled", "name", i.Deployment.Name)
@@ -71,6 +102,15 @@ func (i *DeploymentIdler) Wakeup(ctx context.Context) (*int32, error) {
 		}
 	}
 
+	// The deployment is waked up with the min replicas of its autoscaler, the autoscaler decides of the replicas then
+	minReplicas, err := restoreAutoscaler(ctx, i.Client, &i.Deployment.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	if minReplicas != nil {
+		previousReplicas = minReplicas
+	}
+
 	if i.Deployment.Spec.Replicas != previousReplicas {
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			if err := i.Get(ctx, types.NamespacedName{Namespace: i.Deployment.Namespace, Name: i.Deployment.Name}, i.Deployment); err != nil {
@@ -78,6 +118,7 @@ func (i *DeploymentIdler) Wakeup(ctx context.Context) (*int32, error) {
 				return err
 			}
 			k8s.AddAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataExpectedState, strconv.Itoa(int(*previousReplicas)))
+			removeAutoscalerAnnotations(&i.Deployment.ObjectMeta)
 			i.Deployment.Spec.Replicas = previousReplicas
 			return i.Update(ctx, i.Deployment)
 		})
@@ -91,3 +132,43 @@ func (i *DeploymentIdler) Wakeup(ctx context.Context) (*int32, error) {
 	}
 	return previousReplicas, nil
 }
+
+// HasDrifted returns true if the idled deployment has been rescaled by someone else
+func (i *DeploymentIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "0" && *i.Deployment.Spec.Replicas > 0
+}
+
+// AcceptDrift saves the current replicas of the deployment as its expected state.
+// The replicas are also saved as the replicas to restore on wakeup if adopt is true.
+func (i *DeploymentIdler) AcceptDrift(ctx context.Context, adopt bool) (*int32, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.Deployment.Namespace, Name: i.Deployment.Name}, i.Deployment); err != nil {
+			return err
+		}
+		replicas := strconv.Itoa(int(*i.Deployment.Spec.Replicas))
+		k8s.AddAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataExpectedState, replicas)
+		if adopt {
+			k8s.AddAnnotation(&i.Deployment.ObjectMeta, kidlev1beta1.MetadataPreviousReplicas, replicas)
+		}
+		return i.Update(ctx, i.Deployment)
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of deployment", "name", i.Deployment.Name)
+		return nil, err
+	}
+	return i.Deployment.Spec.Replicas, nil
+}
+
+// IsReady returns true if the deployment is running and all its replicas are ready
+func (i *DeploymentIdler) IsReady() bool {
+	replicas := *i.Deployment.Spec.Replicas
+	status := i.Deployment.Status
+	return replicas > 0 && status.ObservedGeneration >= i.Deployment.Generation &&
+		status.UpdatedReplicas >= replicas && status.ReadyReplicas >= replicas
+}
+
+// IsIdled returns true if the deployment is scaled to 0 and all its pods are gone
+func (i *DeploymentIdler) IsIdled() bool {
+	return *i.Deployment.Spec.Replicas == 0 && i.Deployment.Status.Replicas == 0
+}
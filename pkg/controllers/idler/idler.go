@@ -6,10 +6,12 @@ import (
 	"github.com/go-logr/logr"
 	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
 	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
 )
 
 type Idler interface {
@@ -21,24 +23,58 @@ type Idler interface {
 
 	Idle(ctx context.Context) error
 	Wakeup(ctx context.Context) (*int32, error)
+
+	GetPreviousReplicas() (*int32, error)
+
+	HasDrifted() bool
+	AcceptDrift(ctx context.Context, adopt bool) (*int32, error)
+
+	IsReady() bool
+	IsIdled() bool
 }
 
+// WorkloadIdler is an Idler giving access to its workload, implemented by the idlers embedding an ObjectIdler
+type WorkloadIdler interface {
+	Idler
+	Workload() client.Object
+}
+
+// The idlers give access to their workload, e.g. to estimate the resources saved by an idling
+var (
+	_ WorkloadIdler = &DeploymentIdler{}
+	_ WorkloadIdler = &StatefulSetIdler{}
+	_ WorkloadIdler = &CronJobIdler{}
+	_ WorkloadIdler = &JobIdler{}
+	_ WorkloadIdler = &DaemonSetIdler{}
+	_ WorkloadIdler = &ScaleIdler{}
+	_ WorkloadIdler = &ScaledObjectIdler{}
+	_ WorkloadIdler = &VirtualMachineIdler{}
+)
+
 type ObjectIdler struct {
 	client.Client
 	Log           logr.Logger
 	Object        metav1.Object
 	RuntimeObject client.Object
+	// Annotations are the annotations saved by the idler of the kind, removed with the annotations common to all kinds
+	Annotations []string
 }
 
-func NewObjectIdler(k8sClient client.Client, log logr.Logger, o interface{}) ObjectIdler {
+func NewObjectIdler(k8sClient client.Client, log logr.Logger, o interface{}, annotations ...string) ObjectIdler {
 	return ObjectIdler{
 		Client:        k8sClient,
 		Log:           log,
 		Object:        o.(metav1.Object),
 		RuntimeObject: o.(client.Object),
+		Annotations:   annotations,
 	}
 }
 
+// Workload returns the idled workload
+func (o *ObjectIdler) Workload() client.Object {
+	return o.RuntimeObject
+}
+
 func (o *ObjectIdler) SetReference(ctx context.Context, instanceName string) error {
 	if !k8s.HasAnnotation(o.Object, kidlev1beta1.MetadataIdlingResourceReference) {
 		o.Log.Info(fmt.Sprintf("Set reference for object %v", o.Object.GetName()))
@@ -70,6 +106,9 @@ func (o *ObjectIdler) RemoveAnnotations(ctx context.Context) error {
 			k8s.RemoveAnnotation(o.Object, kidlev1beta1.MetadataIdlingResourceReference)
 			k8s.RemoveAnnotation(o.Object, kidlev1beta1.MetadataPreviousReplicas)
 			k8s.RemoveAnnotation(o.Object, kidlev1beta1.MetadataExpectedState)
+			for _, annotation := range o.Annotations {
+				k8s.RemoveAnnotation(o.Object, annotation)
+			}
 			return o.Update(ctx, o.RuntimeObject)
 		})
 		if err != nil {
@@ -79,3 +118,17 @@ func (o *ObjectIdler) RemoveAnnotations(ctx context.Context) error {
 	}
 	return nil
 }
+
+// GetPreviousReplicas returns the replicas saved in annotations before idling.
+// It returns nil if the object has no saved replicas.
+func (o *ObjectIdler) GetPreviousReplicas() (*int32, error) {
+	metadataPreviousReplicas, found := k8s.GetAnnotation(o.Object, kidlev1beta1.MetadataPreviousReplicas)
+	if !found {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(metadataPreviousReplicas)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", kidlev1beta1.MetadataPreviousReplicas, err)
+	}
+	return pointer.Int32(int32(v)), nil
+}
@@ -0,0 +1,13 @@
+package idler_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestIdler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Idler Suite")
+}
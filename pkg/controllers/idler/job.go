@@ -0,0 +1,135 @@
+package idler
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+)
+
+func init() {
+	MustRegister(Registration{
+		GroupVersionKind: batchv1.SchemeGroupVersion.WithKind("Job"),
+		NewObject:        func() client.Object { return &batchv1.Job{} },
+		NewList:          func() client.ObjectList { return &batchv1.JobList{} },
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler {
+			return NewJobIdler(c, log, workload.(*batchv1.Job))
+		},
+		State: func(workload client.Object) string {
+			return strconv.FormatBool(isJobSuspended(workload.(*batchv1.Job)))
+		},
+	})
+}
+
+// JobIdler idles a Job by suspending it, the pods of a suspended job are deleted
+type JobIdler struct {
+	client.Client
+	Log logr.Logger
+	Job *batchv1.Job
+	ObjectIdler
+}
+
+func NewJobIdler(client client.Client, log logr.Logger, job *batchv1.Job) *JobIdler {
+	return &JobIdler{
+		Client:      client,
+		Log:         log,
+		Job:         job,
+		ObjectIdler: NewObjectIdler(client, log, job),
+	}
+}
+
+func (i *JobIdler) NeedIdle(instance *kidlev1beta1.IdlingResource) bool {
+	return instance.Spec.Idle && !isJobSuspended(i.Job)
+}
+
+func (i *JobIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) bool {
+	return !instance.Spec.Idle && isJobSuspended(i.Job)
+}
+
+func (i *JobIdler) Idle(ctx context.Context) error {
+	if !isJobSuspended(i.Job) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.Job.Namespace, Name: i.Job.Name}, i.Job); err != nil {
+				i.Log.Error(err, "unable to get job", "name", i.Job.Name)
+				return err
+			}
+			k8s.AddAnnotation(&i.Job.ObjectMeta, kidlev1beta1.MetadataExpectedState, "true")
+			i.Job.Spec.Suspend = pointer.Bool(true)
+			return i.Update(ctx, i.Job)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to suspend job", "name", i.Job.Name)
+			return err
+		}
+		i.Log.V(1).Info("job suspended", "name", i.Job.Name)
+	} else {
+		i.Log.V(2).Info("job already suspended", "name", i.Job.Name)
+	}
+	return nil
+}
+
+func (i *JobIdler) Wakeup(ctx context.Context) (*int32, error) {
+	if isJobSuspended(i.Job) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.Job.Namespace, Name: i.Job.Name}, i.Job); err != nil {
+				i.Log.Error(err, "unable to get job", "name", i.Job.Name)
+				return err
+			}
+			k8s.AddAnnotation(&i.Job.ObjectMeta, kidlev1beta1.MetadataExpectedState, "false")
+			i.Job.Spec.Suspend = pointer.Bool(false)
+			return i.Update(ctx, i.Job)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to resume job", "name", i.Job.Name)
+			return nil, err
+		}
+		i.Log.V(1).Info("job resumed", "name", i.Job.Name)
+	} else {
+		i.Log.V(2).Info("job already resumed", "name", i.Job.Name)
+	}
+	return nil, nil
+}
+
+// HasDrifted returns true if the suspended job has been resumed by someone else
+func (i *JobIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(&i.Job.ObjectMeta, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "true" && !isJobSuspended(i.Job)
+}
+
+// AcceptDrift saves the current suspend flag of the job as its expected state.
+// A job has no replicas to adopt.
+func (i *JobIdler) AcceptDrift(ctx context.Context, _ bool) (*int32, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.Job.Namespace, Name: i.Job.Name}, i.Job); err != nil {
+			return err
+		}
+		k8s.AddAnnotation(&i.Job.ObjectMeta, kidlev1beta1.MetadataExpectedState, strconv.FormatBool(isJobSuspended(i.Job)))
+		return i.Update(ctx, i.Job)
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of job", "name", i.Job.Name)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// IsReady returns true if the job is resumed, the pods of a job do not have to be ready
+func (i *JobIdler) IsReady() bool {
+	return !isJobSuspended(i.Job)
+}
+
+// IsIdled returns true if the job is suspended and none of its pods is running
+func (i *JobIdler) IsIdled() bool {
+	return isJobSuspended(i.Job) && i.Job.Status.Active == 0
+}
+
+// isJobSuspended returns the suspend flag of a job
+func isJobSuspended(job *batchv1.Job) bool {
+	return job.Spec.Suspend != nil && *job.Spec.Suspend
+}
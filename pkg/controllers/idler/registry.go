@@ -0,0 +1,107 @@
+package idler
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Registration describes a kind of workload and how its workloads are idled
+type Registration struct {
+	// GroupVersionKind is the kind of the workloads
+	GroupVersionKind schema.GroupVersionKind
+
+	// NewObject returns an empty workload, used to read and watch the workloads of the kind
+	NewObject func() client.Object
+
+	// NewList returns an empty list of workloads, used by the IdlingGroups to select the workloads of the kind
+	NewList func() client.ObjectList
+
+	// NewIdler returns the Idler of a workload of the kind
+	NewIdler func(c client.Client, log logr.Logger, workload client.Object) Idler
+
+	// State returns the state of a workload compared to its expected state annotation, e.g. its replicas.
+	// A workload whose state is not the expected one has drifted, its owner is reconciled.
+	State func(workload client.Object) string
+}
+
+// Registry holds the registrations of the kinds of workloads, in the order of their registration
+type Registry struct {
+	registrations []*Registration
+	byType        map[reflect.Type]*Registration
+}
+
+// DefaultRegistry is the registry of the kinds of workloads idled by the controllers.
+// The idlers register their kind when the package is initialised.
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{byType: map[reflect.Type]*Registration{}}
+}
+
+// Register adds a kind of workload to the registry, a kind can only be registered once
+func (r *Registry) Register(registration Registration) error {
+	if registration.NewObject == nil || registration.NewList == nil || registration.NewIdler == nil || registration.State == nil {
+		return fmt.Errorf("incomplete registration of %s", registration.GroupVersionKind)
+	}
+	for _, registered := range r.registrations {
+		if registered.GroupVersionKind == registration.GroupVersionKind {
+			return fmt.Errorf("%s is already registered", registration.GroupVersionKind)
+		}
+	}
+	r.registrations = append(r.registrations, &registration)
+
+	// The unstructured workloads are looked up by their kind
+	if t := reflect.TypeOf(registration.NewObject()); t != reflect.TypeOf(&unstructured.Unstructured{}) {
+		r.byType[t] = &registration
+	}
+	return nil
+}
+
+// Lookup returns the registration of a kind, nil if the kind is not registered.
+// A kind registered with another version is returned for the references of another API version,
+// or without API group for the references whose apiVersion is omitted.
+func (r *Registry) Lookup(gvk schema.GroupVersionKind) *Registration {
+	for _, registration := range r.registrations {
+		if registration.GroupVersionKind == gvk {
+			return registration
+		}
+	}
+	for _, registration := range r.registrations {
+		registered := registration.GroupVersionKind
+		if registered.Kind == gvk.Kind && (registered.Group == gvk.Group || gvk.Group == "") {
+			return registration
+		}
+	}
+	return nil
+}
+
+// For returns the registration of the kind of a workload, nil if the kind is not registered
+func (r *Registry) For(workload client.Object) *Registration {
+	if u, ok := workload.(*unstructured.Unstructured); ok {
+		gvk := u.GroupVersionKind()
+		for _, registration := range r.registrations {
+			if registration.GroupVersionKind == gvk {
+				return registration
+			}
+		}
+		return nil
+	}
+	return r.byType[reflect.TypeOf(workload)]
+}
+
+// Registrations returns the registered kinds, in the order of their registration
+func (r *Registry) Registrations() []*Registration {
+	return r.registrations
+}
+
+// MustRegister adds a kind of workload to the DefaultRegistry, it panics if the kind cannot be registered
+func MustRegister(registration Registration) {
+	if err := DefaultRegistry.Register(registration); err != nil {
+		panic(err)
+	}
+}
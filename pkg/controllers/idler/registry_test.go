@@ -0,0 +1,105 @@
+package idler
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Registry", func() {
+	// The CronJobs are registered by the operator with the API version served by the cluster
+	registry := NewRegistry()
+	for _, registration := range DefaultRegistry.Registrations() {
+		Expect(registry.Register(*registration)).To(Succeed())
+	}
+	Expect(registry.Register(CronJobRegistration(batchv1beta1.SchemeGroupVersion))).To(Succeed())
+
+	widgetKind := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	widget := Registration{
+		GroupVersionKind: widgetKind,
+		NewObject: func() client.Object {
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(widgetKind)
+			return u
+		},
+		NewList: func() client.ObjectList {
+			u := &unstructured.UnstructuredList{}
+			u.SetGroupVersionKind(widgetKind.GroupVersion().WithKind("WidgetList"))
+			return u
+		},
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler { return nil },
+		State:    func(workload client.Object) string { return "" },
+	}
+
+	DescribeTable("looks up the registered kinds",
+		func(gvk schema.GroupVersionKind, expected string) {
+			registration := registry.Lookup(gvk)
+			if expected == "" {
+				Expect(registration).To(BeNil())
+				return
+			}
+			Expect(registration).ToNot(BeNil())
+			Expect(registration.GroupVersionKind.String()).To(Equal(expected))
+		},
+		Entry("with the registered version", appsv1.SchemeGroupVersion.WithKind("Deployment"), "apps/v1, Kind=Deployment"),
+		Entry("with another version", schema.GroupVersionKind{Group: "batch", Version: "v2", Kind: "CronJob"}, "batch/v1beta1, Kind=CronJob"),
+		Entry("without API group", schema.GroupVersionKind{Kind: "StatefulSet"}, "apps/v1, Kind=StatefulSet"),
+		Entry("with a job", batchv1.SchemeGroupVersion.WithKind("Job"), "batch/v1, Kind=Job"),
+		Entry("with a daemonset", appsv1.SchemeGroupVersion.WithKind("DaemonSet"), "apps/v1, Kind=DaemonSet"),
+		Entry("with another API group", schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Deployment"}, ""),
+		Entry("with an unknown kind", appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), ""),
+	)
+
+	It("finds the registration of a workload", func() {
+		Expect(registry.For(&appsv1.Deployment{}).GroupVersionKind.Kind).To(Equal("Deployment"))
+		Expect(registry.For(&batchv1beta1.CronJob{}).GroupVersionKind.Kind).To(Equal("CronJob"))
+		Expect(registry.For(&batchv1.CronJob{})).To(BeNil())
+		Expect(registry.For(&appsv1.ReplicaSet{})).To(BeNil())
+	})
+
+	It("gives the state of a workload", func() {
+		deployment := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: pointer.Int32(3)}}
+		Expect(registry.For(deployment).State(deployment)).To(Equal("3"))
+		cronJob := &batchv1beta1.CronJob{Spec: batchv1beta1.CronJobSpec{Suspend: pointer.Bool(true)}}
+		Expect(registry.For(cronJob).State(cronJob)).To(Equal("true"))
+	})
+
+	It("registers the batch/v1 CronJobs", func() {
+		registry := NewRegistry()
+		Expect(registry.Register(CronJobRegistration(batchv1.SchemeGroupVersion))).To(Succeed())
+		Expect(registry.Lookup(batchv1beta1.SchemeGroupVersion.WithKind("CronJob")).GroupVersionKind).To(Equal(batchv1.SchemeGroupVersion.WithKind("CronJob")))
+		cronJob := &batchv1.CronJob{}
+		Expect(registry.For(cronJob).State(cronJob)).To(Equal("false"))
+	})
+
+	It("registers the unstructured workloads by their kind", func() {
+		registry := NewRegistry()
+		Expect(registry.Register(widget)).To(Succeed())
+		Expect(registry.Lookup(widgetKind)).ToNot(BeNil())
+		Expect(registry.For(widget.NewObject())).ToNot(BeNil())
+
+		other := &unstructured.Unstructured{}
+		other.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"})
+		Expect(registry.For(other)).To(BeNil())
+	})
+
+	It("rejects a kind registered twice", func() {
+		registry := NewRegistry()
+		Expect(registry.Register(widget)).To(Succeed())
+		Expect(registry.Register(widget)).ToNot(Succeed())
+	})
+
+	It("rejects an incomplete registration", func() {
+		incomplete := widget
+		incomplete.State = nil
+		Expect(NewRegistry().Register(incomplete)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,200 @@
+package idler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/scale"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ScaleIdler idles any resource exposing the scale subresource, e.g. the custom resources of an operator.
+// The kidle annotations are set on the resource, the replicas are read and set through its scale subresource.
+type ScaleIdler struct {
+	client.Client
+	Log            logr.Logger
+	Scales         scale.ScalesGetter
+	Resource       schema.GroupResource
+	ScaledResource *unstructured.Unstructured
+	Scale          *autoscalingv1.Scale
+	ObjectIdler
+}
+
+func NewScaleIdler(client client.Client, scales scale.ScalesGetter, log logr.Logger, resource schema.GroupResource, workload *unstructured.Unstructured, s *autoscalingv1.Scale) *ScaleIdler {
+	return &ScaleIdler{
+		Client:         client,
+		Log:            log,
+		Scales:         scales,
+		Resource:       resource,
+		ScaledResource: workload,
+		Scale:          s,
+		ObjectIdler:    NewObjectIdler(client, log, workload, autoscalerAnnotations...),
+	}
+}
+
+// NewScalesGetter returns a client of the scale subresources, the kind of each scale subresource is resolved by discovery
+func NewScalesGetter(cfg *rest.Config, mapper meta.RESTMapper) (scale.ScalesGetter, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return scale.NewForConfig(cfg, mapper, dynamic.LegacyAPIPathResolverFunc, scale.NewDiscoveryScaleKindResolver(discoveryClient))
+}
+
+func (i *ScaleIdler) NeedIdle(instance *kidlev1beta1.IdlingResource) bool {
+	return instance.Spec.Idle && i.Scale.Spec.Replicas > 0
+}
+
+func (i *ScaleIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) bool {
+	return !instance.Spec.Idle && i.Scale.Spec.Replicas == 0
+}
+
+func (i *ScaleIdler) Idle(ctx context.Context) error {
+	if i.Scale.Spec.Replicas != 0 {
+		// The autoscaler of the resource is neutralised once its replicas bounds are saved
+		hpa, err := findAutoscaler(ctx, i.Client, i.ScaledResource, i.ScaledResource.GetKind())
+		if err != nil {
+			return err
+		}
+		err = i.updateWorkload(ctx, func() {
+			if hpa != nil {
+				saveAutoscaler(i.ScaledResource, hpa)
+			}
+			// The replicas of a drifted workload are not saved, the workload is restored to its replicas before the drift
+			if expected, _ := k8s.GetAnnotation(i.ScaledResource, kidlev1beta1.MetadataExpectedState); expected != "0" {
+				k8s.AddAnnotation(i.ScaledResource, kidlev1beta1.MetadataPreviousReplicas, strconv.Itoa(int(i.Scale.Spec.Replicas)))
+			}
+			k8s.AddAnnotation(i.ScaledResource, kidlev1beta1.MetadataExpectedState, "0")
+		})
+		if err == nil {
+			err = i.scale(ctx, 0)
+		}
+		if err != nil {
+			i.Log.Error(err, "unable to downscale resource", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+			return err
+		}
+		if hpa != nil {
+			if err := neutraliseAutoscaler(ctx, i.Client, hpa); err != nil {
+				i.Log.Error(err, "unable to neutralise the autoscaler of resource", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+				return err
+			}
+		}
+		i.Log.V(1).Info("resource idled", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+	} else {
+		i.Log.V(2).Info("resource already idled", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+	}
+	return nil
+}
+
+func (i *ScaleIdler) Wakeup(ctx context.Context) (*int32, error) {
+	previousReplicas, err := i.GetPreviousReplicas()
+	if err != nil {
+		return nil, err
+	}
+	if previousReplicas == nil {
+		previousReplicas = pointer.Int32(1)
+	}
+
+	// The resource is waked up with the min replicas of its autoscaler, the autoscaler decides of the replicas then
+	minReplicas, err := restoreAutoscaler(ctx, i.Client, i.ScaledResource)
+	if err != nil {
+		return nil, err
+	}
+	if minReplicas != nil {
+		previousReplicas = minReplicas
+	}
+
+	if i.Scale.Spec.Replicas != *previousReplicas {
+		err := i.updateWorkload(ctx, func() {
+			k8s.AddAnnotation(i.ScaledResource, kidlev1beta1.MetadataExpectedState, strconv.Itoa(int(*previousReplicas)))
+			removeAutoscalerAnnotations(i.ScaledResource)
+		})
+		if err == nil {
+			err = i.scale(ctx, *previousReplicas)
+		}
+		if err != nil {
+			i.Log.Error(err, "unable to wakeup resource", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+			return nil, err
+		}
+		i.Log.V(1).Info("resource waked up", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+	} else {
+		i.Log.V(2).Info("resource already waked up", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+	}
+	return previousReplicas, nil
+}
+
+// HasDrifted returns true if the idled resource has been rescaled by someone else
+func (i *ScaleIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(i.ScaledResource, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "0" && i.Scale.Spec.Replicas > 0
+}
+
+// AcceptDrift saves the current replicas of the resource as its expected state.
+// The replicas are also saved as the replicas to restore on wakeup if adopt is true.
+func (i *ScaleIdler) AcceptDrift(ctx context.Context, adopt bool) (*int32, error) {
+	replicas := strconv.Itoa(int(i.Scale.Spec.Replicas))
+	err := i.updateWorkload(ctx, func() {
+		k8s.AddAnnotation(i.ScaledResource, kidlev1beta1.MetadataExpectedState, replicas)
+		if adopt {
+			k8s.AddAnnotation(i.ScaledResource, kidlev1beta1.MetadataPreviousReplicas, replicas)
+		}
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of resource", "kind", i.ScaledResource.GetKind(), "name", i.ScaledResource.GetName())
+		return nil, err
+	}
+	return pointer.Int32(i.Scale.Spec.Replicas), nil
+}
+
+// IsReady returns true if the resource is running and all its replicas are observed.
+// The scale subresource does not report the readiness of the replicas.
+func (i *ScaleIdler) IsReady() bool {
+	return i.Scale.Spec.Replicas > 0 && i.Scale.Status.Replicas >= i.Scale.Spec.Replicas
+}
+
+// IsIdled returns true if the resource is scaled to 0 and none of its replicas is observed
+func (i *ScaleIdler) IsIdled() bool {
+	return i.Scale.Spec.Replicas == 0 && i.Scale.Status.Replicas == 0
+}
+
+// updateWorkload updates the annotations of the resource set by the mutate function
+func (i *ScaleIdler) updateWorkload(ctx context.Context, mutate func()) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.ScaledResource.GetNamespace(), Name: i.ScaledResource.GetName()}, i.ScaledResource); err != nil {
+			return err
+		}
+		mutate()
+		return i.Update(ctx, i.ScaledResource)
+	})
+}
+
+// scale sets the replicas of the resource through its scale subresource
+func (i *ScaleIdler) scale(ctx context.Context, replicas int32) error {
+	scales := i.Scales.Scales(i.ScaledResource.GetNamespace())
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		s, err := scales.Get(ctx, i.Resource, i.ScaledResource.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		s.Spec.Replicas = replicas
+		if s, err = scales.Update(ctx, i.Resource, s, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		i.Scale = s
+		return nil
+	})
+}
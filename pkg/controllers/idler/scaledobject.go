@@ -0,0 +1,173 @@
+package idler
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+)
+
+const (
+	// KedaPausedReplicas is the annotation pausing the autoscaling of a KEDA ScaledObject at a number of replicas
+	KedaPausedReplicas = "autoscaling.keda.sh/paused-replicas"
+)
+
+var (
+	// ScaledObjectGroupVersionResource is the resource of the KEDA ScaledObjects
+	ScaledObjectGroupVersionResource = schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}
+
+	// ScaledObjectGroupVersionKind is the kind of the KEDA ScaledObjects
+	ScaledObjectGroupVersionKind = ScaledObjectGroupVersionResource.GroupVersion().WithKind("ScaledObject")
+)
+
+// ScaledObjectRegistration returns the registration of the KEDA ScaledObjects.
+// The ScaledObjects are only registered when KEDA is installed, they are handled as unstructured objects.
+func ScaledObjectRegistration() Registration {
+	return Registration{
+		GroupVersionKind: ScaledObjectGroupVersionKind,
+		NewObject: func() client.Object {
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(ScaledObjectGroupVersionKind)
+			return u
+		},
+		NewList: func() client.ObjectList {
+			u := &unstructured.UnstructuredList{}
+			u.SetGroupVersionKind(ScaledObjectGroupVersionResource.GroupVersion().WithKind("ScaledObjectList"))
+			return u
+		},
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler {
+			return NewScaledObjectIdler(c, log, workload.(*unstructured.Unstructured))
+		},
+		State: func(workload client.Object) string {
+			return strconv.FormatBool(isScaledObjectIdled(workload))
+		},
+	}
+}
+
+// ScaledObjectIdler idles a KEDA ScaledObject by pausing it at 0 replicas.
+// KEDA scales the target of the ScaledObject down to 0 and stops autoscaling it until the pause is removed.
+// A pause set by the user at other replicas is saved while idled and restored on wakeup.
+type ScaledObjectIdler struct {
+	client.Client
+	Log          logr.Logger
+	ScaledObject *unstructured.Unstructured
+	ObjectIdler
+}
+
+func NewScaledObjectIdler(client client.Client, log logr.Logger, scaledObject *unstructured.Unstructured) *ScaledObjectIdler {
+	return &ScaledObjectIdler{
+		Client:       client,
+		Log:          log,
+		ScaledObject: scaledObject,
+		ObjectIdler:  NewObjectIdler(client, log, scaledObject, kidlev1beta1.MetadataPreviousPausedReplicas),
+	}
+}
+
+func (i *ScaledObjectIdler) NeedIdle(instance *kidlev1beta1.IdlingResource) bool {
+	return instance.Spec.Idle && !isScaledObjectIdled(i.ScaledObject)
+}
+
+func (i *ScaledObjectIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) bool {
+	return !instance.Spec.Idle && isScaledObjectIdled(i.ScaledObject)
+}
+
+func (i *ScaledObjectIdler) Idle(ctx context.Context) error {
+	if !isScaledObjectIdled(i.ScaledObject) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.ScaledObject.GetNamespace(), Name: i.ScaledObject.GetName()}, i.ScaledObject); err != nil {
+				i.Log.Error(err, "unable to get scaledobject", "name", i.ScaledObject.GetName())
+				return err
+			}
+			// The pause set by the user is restored on wakeup
+			if paused, found := k8s.GetAnnotation(i.ScaledObject, KedaPausedReplicas); found {
+				k8s.AddAnnotation(i.ScaledObject, kidlev1beta1.MetadataPreviousPausedReplicas, paused)
+			}
+			k8s.AddAnnotation(i.ScaledObject, kidlev1beta1.MetadataExpectedState, "true")
+			k8s.AddAnnotation(i.ScaledObject, KedaPausedReplicas, "0")
+			return i.Update(ctx, i.ScaledObject)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to pause scaledobject", "name", i.ScaledObject.GetName())
+			return err
+		}
+		i.Log.V(1).Info("scaledobject paused", "name", i.ScaledObject.GetName())
+	} else {
+		i.Log.V(2).Info("scaledobject already paused", "name", i.ScaledObject.GetName())
+	}
+	return nil
+}
+
+func (i *ScaledObjectIdler) Wakeup(ctx context.Context) (*int32, error) {
+	if isScaledObjectIdled(i.ScaledObject) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.ScaledObject.GetNamespace(), Name: i.ScaledObject.GetName()}, i.ScaledObject); err != nil {
+				i.Log.Error(err, "unable to get scaledobject", "name", i.ScaledObject.GetName())
+				return err
+			}
+			k8s.AddAnnotation(i.ScaledObject, kidlev1beta1.MetadataExpectedState, "false")
+			if paused, found := k8s.GetAnnotation(i.ScaledObject, kidlev1beta1.MetadataPreviousPausedReplicas); found {
+				k8s.AddAnnotation(i.ScaledObject, KedaPausedReplicas, paused)
+				k8s.RemoveAnnotation(i.ScaledObject, kidlev1beta1.MetadataPreviousPausedReplicas)
+			} else {
+				k8s.RemoveAnnotation(i.ScaledObject, KedaPausedReplicas)
+			}
+			return i.Update(ctx, i.ScaledObject)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to resume scaledobject", "name", i.ScaledObject.GetName())
+			return nil, err
+		}
+		i.Log.V(1).Info("scaledobject resumed", "name", i.ScaledObject.GetName())
+	} else {
+		i.Log.V(2).Info("scaledobject already resumed", "name", i.ScaledObject.GetName())
+	}
+	return nil, nil
+}
+
+// HasDrifted returns true if the pause at 0 replicas of the scaledobject has been removed or changed by someone else
+func (i *ScaledObjectIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(i.ScaledObject, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "true" && !isScaledObjectIdled(i.ScaledObject)
+}
+
+// AcceptDrift saves the current pause of the scaledobject as its expected state.
+// The replicas of a scaledobject are decided by KEDA, there are no replicas to adopt.
+// The pause saved before the idling is dropped, the current pause is the one of the user.
+func (i *ScaledObjectIdler) AcceptDrift(ctx context.Context, _ bool) (*int32, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.ScaledObject.GetNamespace(), Name: i.ScaledObject.GetName()}, i.ScaledObject); err != nil {
+			return err
+		}
+		k8s.AddAnnotation(i.ScaledObject, kidlev1beta1.MetadataExpectedState, strconv.FormatBool(isScaledObjectIdled(i.ScaledObject)))
+		k8s.RemoveAnnotation(i.ScaledObject, kidlev1beta1.MetadataPreviousPausedReplicas)
+		return i.Update(ctx, i.ScaledObject)
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of scaledobject", "name", i.ScaledObject.GetName())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// IsReady returns true if the scaledobject is not paused at 0 replicas, the replicas of its target are decided by KEDA
+func (i *ScaledObjectIdler) IsReady() bool {
+	return !isScaledObjectIdled(i.ScaledObject)
+}
+
+// IsIdled returns true if the scaledobject is paused at 0 replicas, KEDA scales its target down asynchronously
+func (i *ScaledObjectIdler) IsIdled() bool {
+	return isScaledObjectIdled(i.ScaledObject)
+}
+
+// isScaledObjectIdled returns true if the scaledobject is paused at 0 replicas by the paused-replicas annotation.
+// A scaledobject paused at other replicas is not idled.
+func isScaledObjectIdled(scaledObject client.Object) bool {
+	paused, _ := k8s.GetAnnotation(scaledObject, KedaPausedReplicas)
+	return paused == "0"
+}
@@ -13,6 +13,20 @@ import (
 	"strconv"
 )
 
+func init() {
+	MustRegister(Registration{
+		GroupVersionKind: appsv1.SchemeGroupVersion.WithKind("StatefulSet"),
+		NewObject:        func() client.Object { return &appsv1.StatefulSet{} },
+		NewList:          func() client.ObjectList { return &appsv1.StatefulSetList{} },
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler {
+			return NewStatefulSetIdler(c, log, workload.(*appsv1.StatefulSet))
+		},
+		State: func(workload client.Object) string {
+			return strconv.Itoa(int(*workload.(*appsv1.StatefulSet).Spec.Replicas))
+		},
+	})
+}
+
 type StatefulSetIdler struct {
 	client.Client
 	Log         logr.Logger
@@ -25,7 +39,7 @@ func NewStatefulSetIdler(client client.Client, log logr.Logger, statefulSet *app
 		Client:      client,
 		Log:         log,
 		StatefulSet: statefulSet,
-		ObjectIdler: NewObjectIdler(client, log, statefulSet),
+		ObjectIdler: NewObjectIdler(client, log, statefulSet, autoscalerAnnotations...),
 	}
 }
 
@@ -39,12 +53,23 @@ func (i *StatefulSetIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) boo
 
 func (i *StatefulSetIdler) Idle(ctx context.Context) error {
 	if i.StatefulSet.Spec.Replicas != pointer.Int32(0) {
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// The autoscaler of the statefulset is neutralised once its replicas bounds are saved
+		hpa, err := findAutoscaler(ctx, i.Client, i.StatefulSet, "StatefulSet")
+		if err != nil {
+			return err
+		}
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			if err := i.Get(ctx, types.NamespacedName{Namespace: i.StatefulSet.Namespace, Name: i.StatefulSet.Name}, i.StatefulSet); err != nil {
 				i.Log.Error(err, "unable to get statefulset","name", i.StatefulSet.Name)
 				return err
 			}
-			k8s.AddAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataPreviousReplicas, strconv.Itoa(int(*i.StatefulSet.Spec.Replicas)))
+			if hpa != nil {
+				saveAutoscaler(&i.StatefulSet.ObjectMeta, hpa)
+			}
+			// The replicas of a drifted workload are not saved, the workload is restored to its replicas before the drift
+			if expected, _ := k8s.GetAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataExpectedState); expected != "0" {
+				k8s.AddAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataPreviousReplicas, strconv.Itoa(int(*i.StatefulSet.Spec.Replicas)))
+			}
 			k8s.AddAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataExpectedState, "0")
 			i.StatefulSet.Spec.Replicas = pointer.Int32(0)
 			return i.Update(ctx, i.StatefulSet)
@@ -53,6 +78,12 @@ func (i *StatefulSetIdler) Idle(ctx context.Context) error {
 			i.Log.Error(err, "unable to downscale statefulset", "name", i.StatefulSet.Name)
 			return err
 		}
+		if hpa != nil {
+			if err := neutraliseAutoscaler(ctx, i.Client, hpa); err != nil {
+				i.Log.Error(err, "unable to neutralise the autoscaler of statefulset", "name", i.StatefulSet.Name)
+				return err
+			}
+		}
 		i.Log.V(1).Info("statefulset idled", "name", i.StatefulSet.Name)
 	} else {
 		i.Log.V(2).Info("statefulset already idled", "name", i.StatefulSet.Name)
@@ -71,6 +102,15 @@ func (i *StatefulSetIdler) Wakeup(ctx context.Context) (*int32, error) {
 		}
 	}
 
+	// The statefulset is waked up with the min replicas of its autoscaler, the autoscaler decides of the replicas then
+	minReplicas, err := restoreAutoscaler(ctx, i.Client, &i.StatefulSet.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	if minReplicas != nil {
+		previousReplicas = minReplicas
+	}
+
 	if i.StatefulSet.Spec.Replicas != previousReplicas {
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			if err := i.Get(ctx, types.NamespacedName{Namespace: i.StatefulSet.Namespace, Name: i.StatefulSet.Name}, i.StatefulSet); err != nil {
@@ -78,6 +118,7 @@ func (i *StatefulSetIdler) Wakeup(ctx context.Context) (*int32, error) {
 				return err
 			}
 			k8s.AddAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataExpectedState, strconv.Itoa(int(*previousReplicas)))
+			removeAutoscalerAnnotations(&i.StatefulSet.ObjectMeta)
 			i.StatefulSet.Spec.Replicas = previousReplicas
 			return i.Update(ctx, i.StatefulSet)
 		})
@@ -91,3 +132,42 @@ func (i *StatefulSetIdler) Wakeup(ctx context.Context) (*int32, error) {
 	}
 	return previousReplicas, nil
 }
+
+// HasDrifted returns true if the idled statefulset has been rescaled by someone else
+func (i *StatefulSetIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "0" && *i.StatefulSet.Spec.Replicas > 0
+}
+
+// AcceptDrift saves the current replicas of the statefulset as its expected state.
+// The replicas are also saved as the replicas to restore on wakeup if adopt is true.
+func (i *StatefulSetIdler) AcceptDrift(ctx context.Context, adopt bool) (*int32, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.StatefulSet.Namespace, Name: i.StatefulSet.Name}, i.StatefulSet); err != nil {
+			return err
+		}
+		replicas := strconv.Itoa(int(*i.StatefulSet.Spec.Replicas))
+		k8s.AddAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataExpectedState, replicas)
+		if adopt {
+			k8s.AddAnnotation(&i.StatefulSet.ObjectMeta, kidlev1beta1.MetadataPreviousReplicas, replicas)
+		}
+		return i.Update(ctx, i.StatefulSet)
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of statefulset", "name", i.StatefulSet.Name)
+		return nil, err
+	}
+	return i.StatefulSet.Spec.Replicas, nil
+}
+
+// IsReady returns true if the statefulset is running and all its replicas are ready
+func (i *StatefulSetIdler) IsReady() bool {
+	replicas := *i.StatefulSet.Spec.Replicas
+	status := i.StatefulSet.Status
+	return replicas > 0 && status.ObservedGeneration >= i.StatefulSet.Generation && status.ReadyReplicas >= replicas
+}
+
+// IsIdled returns true if the statefulset is scaled to 0 and all its pods are gone
+func (i *StatefulSetIdler) IsIdled() bool {
+	return *i.StatefulSet.Spec.Replicas == 0 && i.StatefulSet.Status.Replicas == 0
+}
@@ -0,0 +1,187 @@
+package idler
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+)
+
+const (
+	// RunStrategyAlways keeps a KubeVirt VirtualMachine running
+	RunStrategyAlways = "Always"
+	// RunStrategyHalted keeps a KubeVirt VirtualMachine stopped
+	RunStrategyHalted = "Halted"
+)
+
+var (
+	// VirtualMachineGroupVersionResource is the resource of the KubeVirt VirtualMachines
+	VirtualMachineGroupVersionResource = schema.GroupVersionResource{Group: "kubevirt.io", Version: "v1", Resource: "virtualmachines"}
+
+	// VirtualMachineGroupVersionKind is the kind of the KubeVirt VirtualMachines
+	VirtualMachineGroupVersionKind = VirtualMachineGroupVersionResource.GroupVersion().WithKind("VirtualMachine")
+)
+
+// VirtualMachineRegistration returns the registration of the KubeVirt VirtualMachines.
+// The VirtualMachines are only registered when KubeVirt is installed, they are handled as unstructured objects.
+func VirtualMachineRegistration() Registration {
+	return Registration{
+		GroupVersionKind: VirtualMachineGroupVersionKind,
+		NewObject: func() client.Object {
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(VirtualMachineGroupVersionKind)
+			return u
+		},
+		NewList: func() client.ObjectList {
+			u := &unstructured.UnstructuredList{}
+			u.SetGroupVersionKind(VirtualMachineGroupVersionResource.GroupVersion().WithKind("VirtualMachineList"))
+			return u
+		},
+		NewIdler: func(c client.Client, log logr.Logger, workload client.Object) Idler {
+			return NewVirtualMachineIdler(c, log, workload.(*unstructured.Unstructured))
+		},
+		State: func(workload client.Object) string {
+			return strconv.FormatBool(getRunStrategy(workload.(*unstructured.Unstructured)) == RunStrategyHalted)
+		},
+	}
+}
+
+// VirtualMachineIdler idles a KubeVirt VirtualMachine by setting its runStrategy to Halted.
+// The previous runStrategy is saved in an annotation and restored on wakeup.
+type VirtualMachineIdler struct {
+	client.Client
+	Log            logr.Logger
+	VirtualMachine *unstructured.Unstructured
+	ObjectIdler
+}
+
+func NewVirtualMachineIdler(client client.Client, log logr.Logger, vm *unstructured.Unstructured) *VirtualMachineIdler {
+	return &VirtualMachineIdler{
+		Client:         client,
+		Log:            log,
+		VirtualMachine: vm,
+		ObjectIdler:    NewObjectIdler(client, log, vm, kidlev1beta1.MetadataPreviousRunStrategy),
+	}
+}
+
+func (i *VirtualMachineIdler) NeedIdle(instance *kidlev1beta1.IdlingResource) bool {
+	return instance.Spec.Idle && getRunStrategy(i.VirtualMachine) != RunStrategyHalted
+}
+
+func (i *VirtualMachineIdler) NeedWakeup(instance *kidlev1beta1.IdlingResource) bool {
+	return !instance.Spec.Idle && getRunStrategy(i.VirtualMachine) == RunStrategyHalted
+}
+
+func (i *VirtualMachineIdler) Idle(ctx context.Context) error {
+	if getRunStrategy(i.VirtualMachine) != RunStrategyHalted {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.VirtualMachine.GetNamespace(), Name: i.VirtualMachine.GetName()}, i.VirtualMachine); err != nil {
+				i.Log.Error(err, "unable to get virtualmachine", "name", i.VirtualMachine.GetName())
+				return err
+			}
+			// The runStrategy of a drifted virtualmachine is not saved, the virtualmachine is restored to its runStrategy before the drift
+			if expected, _ := k8s.GetAnnotation(i.VirtualMachine, kidlev1beta1.MetadataExpectedState); expected != "true" {
+				k8s.AddAnnotation(i.VirtualMachine, kidlev1beta1.MetadataPreviousRunStrategy, getRunStrategy(i.VirtualMachine))
+			}
+			k8s.AddAnnotation(i.VirtualMachine, kidlev1beta1.MetadataExpectedState, "true")
+			return i.setRunStrategy(ctx, RunStrategyHalted)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to halt virtualmachine", "name", i.VirtualMachine.GetName())
+			return err
+		}
+		i.Log.V(1).Info("virtualmachine halted", "name", i.VirtualMachine.GetName())
+	} else {
+		i.Log.V(2).Info("virtualmachine already halted", "name", i.VirtualMachine.GetName())
+	}
+	return nil
+}
+
+func (i *VirtualMachineIdler) Wakeup(ctx context.Context) (*int32, error) {
+	if getRunStrategy(i.VirtualMachine) == RunStrategyHalted {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.Get(ctx, types.NamespacedName{Namespace: i.VirtualMachine.GetNamespace(), Name: i.VirtualMachine.GetName()}, i.VirtualMachine); err != nil {
+				i.Log.Error(err, "unable to get virtualmachine", "name", i.VirtualMachine.GetName())
+				return err
+			}
+			runStrategy, found := k8s.GetAnnotation(i.VirtualMachine, kidlev1beta1.MetadataPreviousRunStrategy)
+			if !found || runStrategy == RunStrategyHalted {
+				runStrategy = RunStrategyAlways
+			}
+			k8s.AddAnnotation(i.VirtualMachine, kidlev1beta1.MetadataExpectedState, "false")
+			k8s.RemoveAnnotation(i.VirtualMachine, kidlev1beta1.MetadataPreviousRunStrategy)
+			return i.setRunStrategy(ctx, runStrategy)
+		})
+		if err != nil {
+			i.Log.Error(err, "unable to wakeup virtualmachine", "name", i.VirtualMachine.GetName())
+			return nil, err
+		}
+		i.Log.V(1).Info("virtualmachine waked up", "name", i.VirtualMachine.GetName())
+	} else {
+		i.Log.V(2).Info("virtualmachine already waked up", "name", i.VirtualMachine.GetName())
+	}
+	return nil, nil
+}
+
+// HasDrifted returns true if the halted virtualmachine has been started by someone else
+func (i *VirtualMachineIdler) HasDrifted() bool {
+	expected, found := k8s.GetAnnotation(i.VirtualMachine, kidlev1beta1.MetadataExpectedState)
+	return found && expected == "true" && getRunStrategy(i.VirtualMachine) != RunStrategyHalted
+}
+
+// AcceptDrift saves the current state of the virtualmachine as its expected state.
+// A virtualmachine has no replicas to adopt.
+func (i *VirtualMachineIdler) AcceptDrift(ctx context.Context, _ bool) (*int32, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := i.Get(ctx, types.NamespacedName{Namespace: i.VirtualMachine.GetNamespace(), Name: i.VirtualMachine.GetName()}, i.VirtualMachine); err != nil {
+			return err
+		}
+		k8s.AddAnnotation(i.VirtualMachine, kidlev1beta1.MetadataExpectedState, strconv.FormatBool(getRunStrategy(i.VirtualMachine) == RunStrategyHalted))
+		return i.Update(ctx, i.VirtualMachine)
+	})
+	if err != nil {
+		i.Log.Error(err, "unable to accept the drift of virtualmachine", "name", i.VirtualMachine.GetName())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// IsReady returns true if the virtualmachine is not halted and reported ready by KubeVirt
+func (i *VirtualMachineIdler) IsReady() bool {
+	ready, _, _ := unstructured.NestedBool(i.VirtualMachine.Object, "status", "ready")
+	return getRunStrategy(i.VirtualMachine) != RunStrategyHalted && ready
+}
+
+// IsIdled returns true if the virtualmachine is halted and its instance is gone
+func (i *VirtualMachineIdler) IsIdled() bool {
+	created, _, _ := unstructured.NestedBool(i.VirtualMachine.Object, "status", "created")
+	return getRunStrategy(i.VirtualMachine) == RunStrategyHalted && !created
+}
+
+// setRunStrategy updates the runStrategy of the virtualmachine.
+// The deprecated running field is removed, it cannot be set together with the runStrategy.
+func (i *VirtualMachineIdler) setRunStrategy(ctx context.Context, runStrategy string) error {
+	unstructured.RemoveNestedField(i.VirtualMachine.Object, "spec", "running")
+	if err := unstructured.SetNestedField(i.VirtualMachine.Object, runStrategy, "spec", "runStrategy"); err != nil {
+		return err
+	}
+	return i.Update(ctx, i.VirtualMachine)
+}
+
+// getRunStrategy returns the runStrategy of a virtualmachine.
+// The deprecated running field is translated to the Always or Halted runStrategy.
+func getRunStrategy(vm *unstructured.Unstructured) string {
+	if runStrategy, found, _ := unstructured.NestedString(vm.Object, "spec", "runStrategy"); found {
+		return runStrategy
+	}
+	if running, _, _ := unstructured.NestedBool(vm.Object, "spec", "running"); running {
+		return RunStrategyAlways
+	}
+	return RunStrategyHalted
+}
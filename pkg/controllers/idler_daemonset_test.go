@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("idling/wakeup DaemonSets", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx   = context.Background()
+		irKey = types.NamespacedName{Name: "ir-idler-daemonset", Namespace: "default"}
+		dsKey = types.NamespacedName{Name: "log-shipper", Namespace: "default"}
+	)
+
+	getNodeSelector := func() (map[string]string, error) {
+		ds := &appsv1.DaemonSet{}
+		if err := k8sClient.Get(ctx, dsKey, ds); err != nil {
+			return nil, err
+		}
+		return ds.Spec.Template.Spec.NodeSelector, nil
+	}
+
+	It("Should idle the DaemonSet with a nodeSelector matching no node", func() {
+		labels := map[string]string{"app": dsKey.Name}
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: dsKey.Name, Namespace: dsKey.Namespace},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+						Containers:   []corev1.Container{{Name: "fluent-bit", Image: "fluent/fluent-bit"}},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, ds)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "DaemonSet",
+			Name:       dsKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking that the daemonset is idled")
+		Eventually(getNodeSelector, timeout, interval).Should(Equal(map[string]string{kidlev1beta1.IdledNodeSelector: "true"}))
+		Expect(k8sClient.Get(ctx, dsKey, ds)).Should(Succeed())
+		Expect(ds.Annotations).Should(HaveKeyWithValue(kidlev1beta1.MetadataPreviousNodeSelector, `{"kubernetes.io/os":"linux"}`))
+
+		By("Checking that the nodeSelector is restored on wakeup")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(getNodeSelector, timeout, interval).Should(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+		Expect(k8sClient.Get(ctx, dsKey, ds)).Should(Succeed())
+		Expect(ds.Annotations).ShouldNot(HaveKey(kidlev1beta1.MetadataPreviousNodeSelector))
+	})
+})
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -133,6 +134,28 @@ var _ = Describe("idling/wakeup Deployments", func() {
 			}, timeout, interval).Should(Equal(pointer.Int32(0)))
 		})
 
+		It("Should report the idling state in the IdlingResource status", func() {
+			By("Checking the phase")
+			ir := &kidlev1beta1.IdlingResource{}
+			Eventually(func() (kidlev1beta1.IdlingResourcePhase, error) {
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return "", err
+				}
+				return ir.Status.Phase, nil
+			}, timeout, interval).Should(Equal(kidlev1beta1.PhaseIdle))
+
+			By("Checking the conditions")
+			Expect(meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionReady)).Should(BeTrue())
+			Expect(meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionIdled)).Should(BeTrue())
+			Expect(meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionReferenceFound)).Should(BeTrue())
+			Expect(meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionSchedulesConfigured)).Should(BeTrue())
+
+			By("Checking the saved replicas and timestamps")
+			Expect(ir.Status.ObservedGeneration).Should(Equal(ir.Generation))
+			Expect(ir.Status.PreviousReplicas).Should(Equal(pointer.Int32(1)))
+			Expect(ir.Status.LastIdleTime).ShouldNot(BeNil())
+		})
+
 		It("Should watch the Deployment", func() {
 			By("Trying to update replicas on a idled object")
 			Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+var _ = Describe("idling/wakeup Jobs", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx    = context.Background()
+		irKey  = types.NamespacedName{Name: "ir-idler-job", Namespace: "default"}
+		jobKey = types.NamespacedName{Name: "reprocessing", Namespace: "default"}
+	)
+
+	getSuspend := func() (*bool, error) {
+		job := &batchv1.Job{}
+		if err := k8sClient.Get(ctx, jobKey, job); err != nil {
+			return nil, err
+		}
+		return job.Spec.Suspend, nil
+	}
+
+	It("Should suspend and resume the Job", func() {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: jobKey.Name, Namespace: jobKey.Namespace},
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers:    []corev1.Container{{Name: "reprocessing", Image: "busybox"}},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, job)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Job",
+			Name:       jobKey.Name,
+			APIVersion: "batch/v1",
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking that the job is suspended")
+		Eventually(getSuspend, timeout, interval).Should(Equal(pointer.Bool(true)))
+		Expect(k8sClient.Get(ctx, jobKey, job)).Should(Succeed())
+		Expect(job.Annotations).Should(HaveKeyWithValue(kidlev1beta1.MetadataExpectedState, "true"))
+
+		By("Checking that the drift of a resumed job is reverted")
+		Expect(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := k8sClient.Get(ctx, jobKey, job); err != nil {
+				return err
+			}
+			job.Spec.Suspend = pointer.Bool(false)
+			return k8sClient.Update(ctx, job)
+		})).Should(Succeed())
+		Eventually(getSuspend, timeout, interval).Should(Equal(pointer.Bool(true)))
+
+		By("Checking that the job is resumed on wakeup")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(getSuspend, timeout, interval).Should(Equal(pointer.Bool(false)))
+		Expect(k8sClient.Get(ctx, jobKey, job)).Should(Succeed())
+		Expect(job.Annotations).Should(HaveKeyWithValue(kidlev1beta1.MetadataExpectedState, "false"))
+	})
+})
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+var _ = Describe("idling/wakeup KEDA ScaledObjects", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx   = context.Background()
+		irKey = types.NamespacedName{Name: "ir-idler-scaledobject", Namespace: "default"}
+		soKey = types.NamespacedName{Name: "worker", Namespace: "default"}
+	)
+
+	annotationsOf := func(key types.NamespacedName) func() (map[string]string, error) {
+		return func() (map[string]string, error) {
+			so := &unstructured.Unstructured{}
+			so.SetGroupVersionKind(idler.ScaledObjectGroupVersionKind)
+			if err := k8sClient.Get(ctx, key, so); err != nil {
+				return nil, err
+			}
+			return so.GetAnnotations(), nil
+		}
+	}
+	getAnnotations := annotationsOf(soKey)
+
+	It("Should pause and resume the ScaledObject", func() {
+		so := &unstructured.Unstructured{}
+		so.SetGroupVersionKind(idler.ScaledObjectGroupVersionKind)
+		so.SetName(soKey.Name)
+		so.SetNamespace(soKey.Namespace)
+		Expect(unstructured.SetNestedField(so.Object, "worker", "spec", "scaleTargetRef", "name")).Should(Succeed())
+		Expect(k8sClient.Create(ctx, so)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       idler.ScaledObjectGroupVersionKind.Kind,
+			Name:       soKey.Name,
+			APIVersion: idler.ScaledObjectGroupVersionKind.GroupVersion().String(),
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking that the scaledobject is paused")
+		Eventually(getAnnotations, timeout, interval).Should(HaveKeyWithValue(idler.KedaPausedReplicas, "0"))
+		Expect(getAnnotations()).Should(HaveKeyWithValue(kidlev1beta1.MetadataExpectedState, "true"))
+
+		By("Checking that the drift of a resumed scaledobject is reverted")
+		Expect(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := k8sClient.Get(ctx, soKey, so); err != nil {
+				return err
+			}
+			annotations := so.GetAnnotations()
+			delete(annotations, idler.KedaPausedReplicas)
+			so.SetAnnotations(annotations)
+			return k8sClient.Update(ctx, so)
+		})).Should(Succeed())
+		Eventually(getAnnotations, timeout, interval).Should(HaveKeyWithValue(idler.KedaPausedReplicas, "0"))
+
+		By("Checking that the scaledobject is resumed on wakeup")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(getAnnotations, timeout, interval).ShouldNot(HaveKey(idler.KedaPausedReplicas))
+		Expect(getAnnotations()).Should(HaveKeyWithValue(kidlev1beta1.MetadataExpectedState, "false"))
+	})
+
+	It("Should restore the pause of a ScaledObject paused by its user", func() {
+		pausedIRKey := types.NamespacedName{Name: "ir-idler-scaledobject-paused", Namespace: "default"}
+		pausedKey := types.NamespacedName{Name: "paused-worker", Namespace: "default"}
+		getPausedAnnotations := annotationsOf(pausedKey)
+
+		so := &unstructured.Unstructured{}
+		so.SetGroupVersionKind(idler.ScaledObjectGroupVersionKind)
+		so.SetName(pausedKey.Name)
+		so.SetNamespace(pausedKey.Namespace)
+		so.SetAnnotations(map[string]string{idler.KedaPausedReplicas: "3"})
+		Expect(unstructured.SetNestedField(so.Object, "paused-worker", "spec", "scaleTargetRef", "name")).Should(Succeed())
+		Expect(k8sClient.Create(ctx, so)).Should(Succeed())
+
+		ir := newIdlingResource(pausedIRKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       idler.ScaledObjectGroupVersionKind.Kind,
+			Name:       pausedKey.Name,
+			APIVersion: idler.ScaledObjectGroupVersionKind.GroupVersion().String(),
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking that the scaledobject is paused at 0 and its pause is saved")
+		Eventually(getPausedAnnotations, timeout, interval).Should(HaveKeyWithValue(idler.KedaPausedReplicas, "0"))
+		Expect(getPausedAnnotations()).Should(HaveKeyWithValue(kidlev1beta1.MetadataPreviousPausedReplicas, "3"))
+
+		By("Checking that the pause of the user is restored on wakeup")
+		Expect(setIdleFlag(ctx, pausedIRKey, false)).Should(Succeed())
+		Eventually(getPausedAnnotations, timeout, interval).Should(HaveKeyWithValue(idler.KedaPausedReplicas, "3"))
+		Expect(getPausedAnnotations()).ShouldNot(HaveKey(kidlev1beta1.MetadataPreviousPausedReplicas))
+	})
+})
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("idling/wakeup KubeVirt VirtualMachines", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx   = context.Background()
+		irKey = types.NamespacedName{Name: "ir-idler-vm", Namespace: "default"}
+		vmKey = types.NamespacedName{Name: "windows-dev", Namespace: "default"}
+	)
+
+	getRunStrategy := func() (string, error) {
+		vm := &unstructured.Unstructured{}
+		vm.SetGroupVersionKind(idler.VirtualMachineGroupVersionKind)
+		if err := k8sClient.Get(ctx, vmKey, vm); err != nil {
+			return "", err
+		}
+		runStrategy, _, err := unstructured.NestedString(vm.Object, "spec", "runStrategy")
+		return runStrategy, err
+	}
+
+	It("Should halt the VirtualMachine and restore its runStrategy", func() {
+		vm := &unstructured.Unstructured{}
+		vm.SetGroupVersionKind(idler.VirtualMachineGroupVersionKind)
+		vm.SetName(vmKey.Name)
+		vm.SetNamespace(vmKey.Namespace)
+		Expect(unstructured.SetNestedField(vm.Object, "RerunOnFailure", "spec", "runStrategy")).Should(Succeed())
+		Expect(k8sClient.Create(ctx, vm)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       idler.VirtualMachineGroupVersionKind.Kind,
+			Name:       vmKey.Name,
+			APIVersion: idler.VirtualMachineGroupVersionKind.GroupVersion().String(),
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking that the virtualmachine is halted")
+		Eventually(getRunStrategy, timeout, interval).Should(Equal(idler.RunStrategyHalted))
+		Expect(k8sClient.Get(ctx, vmKey, vm)).Should(Succeed())
+		Expect(vm.GetAnnotations()).Should(HaveKeyWithValue(kidlev1beta1.MetadataPreviousRunStrategy, "RerunOnFailure"))
+
+		By("Checking that the runStrategy is restored on wakeup")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(getRunStrategy, timeout, interval).Should(Equal("RerunOnFailure"))
+		Expect(k8sClient.Get(ctx, vmKey, vm)).Should(Succeed())
+		Expect(vm.GetAnnotations()).ShouldNot(HaveKey(kidlev1beta1.MetadataPreviousRunStrategy))
+	})
+})
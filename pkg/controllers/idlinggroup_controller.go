@@ -0,0 +1,560 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// IdlingGroupReconciler reconciles an IdlingGroup object
+type IdlingGroupReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	record.EventRecorder
+	KidlectlImage   string
+	CronJobVersion  schema.GroupVersion
+	CronJobTimeZone bool
+}
+
+// +kubebuilder:rbac:groups=kidle.kidle.dev,resources=idlinggroups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kidle.kidle.dev,resources=idlinggroups/status,verbs=get;update;patch
+
+func (r *IdlingGroupReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.Log.WithValues("idlinggroup", req.NamespacedName)
+
+	log.V(1).Info("Starting reconcile loop")
+	defer log.V(1).Info("Finish reconcile loop")
+
+	// Retrieve IdlingGroup instance
+	var instance kidlev1beta1.IdlingGroup
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if errors.IsNotFound(err) {
+			forgetIdlingGroup(req.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	original := instance.DeepCopy()
+
+	result, err := r.reconcileInstance(ctx, log, &instance)
+
+	// Report the result of the reconciliation in the status
+	if statusErr := r.updateStatus(ctx, original, &instance, err); statusErr != nil {
+		log.Error(statusErr, "unable to update status")
+		if err == nil {
+			return result, statusErr
+		}
+	}
+	recordIdlingGroupState(original, &instance)
+	return result, err
+}
+
+func (r *IdlingGroupReconciler) reconcileInstance(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingGroup) (reconcile.Result, error) {
+	// Deal with the idling group deletion
+	if instance.IsBeingDeleted() {
+		if err := r.releaseWorkloads(ctx, log, instance, labels.Nothing()); err != nil {
+			return reconcile.Result{}, err
+		}
+		if instance.HasFinalizer(kidlev1beta1.IdlingGroupFinalizerName) {
+			controllerutil.RemoveFinalizer(instance, kidlev1beta1.IdlingGroupFinalizerName)
+			if err := r.Update(ctx, instance); err != nil {
+				return reconcile.Result{}, fmt.Errorf("error when removing idling group finalizer: %v", err)
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	// Add finalizer to release the workloads on deletion
+	if !instance.HasFinalizer(kidlev1beta1.IdlingGroupFinalizerName) {
+		controllerutil.AddFinalizer(instance, kidlev1beta1.IdlingGroupFinalizerName)
+		if err := r.Update(ctx, instance); err != nil {
+			r.Event(instance, corev1.EventTypeWarning, "Adding finalizer", fmt.Sprintf("Failed to add finalizer: %s", err))
+			return reconcile.Result{}, fmt.Errorf("error when adding finalizer: %v", err)
+		}
+		r.Event(instance, corev1.EventTypeNormal, "Added", "Object finalizer is added")
+	}
+
+	expirationResult, err := reconcileIdleExpiration(ctx, r.Client, r.EventRecorder, instance,
+		&instance.Spec.Idle, &instance.Spec.IdleExpirationTime, instance.Spec.IdlingStrategy, instance.Spec.WakeupStrategy)
+	if err != nil {
+		return expirationResult, err
+	}
+
+	cronResult, err := r.ReconcileCronStrategies(ctx, instance)
+	if err != nil {
+		setGroupCondition(instance, kidlev1beta1.ConditionSchedulesConfigured, metav1.ConditionFalse, ReasonReconcileFailed, err.Error())
+		return cronResult, err
+	}
+	setGroupCondition(instance, kidlev1beta1.ConditionSchedulesConfigured, metav1.ConditionTrue, ReasonReconciled, "The cron strategies are up to date")
+
+	selector, err := groupSelector(instance)
+	if err != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Selecting workloads", fmt.Sprintf("Invalid selector: %s", err))
+		return reconcile.Result{}, fmt.Errorf("invalid selector: %v", err)
+	}
+
+	// Release the workloads which are not selected anymore
+	if err := r.releaseWorkloads(ctx, log, instance, selector); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	workloads, err := listWorkloads(ctx, r.Client, instance.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The members of the group are idled after the members depending on them
+	var members []client.Object
+	for _, workload := range workloads {
+		if selector.Matches(labels.Set(workload.GetLabels())) && !kidlev1beta1.IsExcluded(workload) && !isManagedByOther(workload, instance.Name) {
+			members = append(members, workload)
+		}
+	}
+
+	var managed int32
+	var idled, wokeUp, waking, waiting, failed, conflicts int
+	var statuses []kidlev1beta1.IdlingGroupWorkloadStatus
+	for _, workload := range workloads {
+		if !selector.Matches(labels.Set(workload.GetLabels())) {
+			continue
+		}
+		status := kidlev1beta1.IdlingGroupWorkloadStatus{Ref: workloadReference(workload)}
+
+		switch {
+		case kidlev1beta1.IsExcluded(workload):
+			status.State = kidlev1beta1.WorkloadExcluded
+			status.Message = fmt.Sprintf("Excluded by the %s annotation", kidlev1beta1.MetadataExclude)
+		case isManagedByOther(workload, instance.Name):
+			conflicts++
+			status.State = kidlev1beta1.WorkloadConflict
+			status.Message = managedByMessage(workload)
+		default:
+			managed++
+			state, previousReplicas, blockedBy, err := r.reconcileWorkload(ctx, log, instance, workload, workloads, members)
+			if err != nil {
+				failed++
+				r.Event(instance, corev1.EventTypeWarning, fmt.Sprintf("Scaling%s", status.Ref.Kind), fmt.Sprintf("Failed to reconcile %s %s: %s", status.Ref.Kind, status.Ref.Name, err))
+				status.State = kidlev1beta1.WorkloadError
+				status.Message = err.Error()
+				break
+			}
+			status.State = state
+			status.PreviousReplicas = previousReplicas
+			status.BlockedBy = blockedBy
+			switch state {
+			case kidlev1beta1.WorkloadIdling:
+				idled++
+			case kidlev1beta1.WorkloadWakingUp:
+				if previousWorkloadState(instance, status.Ref) != kidlev1beta1.WorkloadWakingUp {
+					wokeUp++
+					break
+				}
+				waking++
+				if wakeupTimedOut(instance.Status.LastWakeupTime, instance.GetWakeupTimeout()) {
+					status.Message = fmt.Sprintf("Not ready %s after its wakeup", instance.GetWakeupTimeout())
+				}
+			case kidlev1beta1.WorkloadWaiting:
+				waiting++
+				if instance.Spec.Idle {
+					status.Message = fmt.Sprintf("Waiting for the dependents to be idled: %s", strings.Join(blockedBy, ", "))
+				} else {
+					status.Message = fmt.Sprintf("Waiting for the dependencies to be ready: %s", strings.Join(blockedBy, ", "))
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	if conflicts > countWorkloadStates(instance.Status.WorkloadStatuses, kidlev1beta1.WorkloadConflict) {
+		r.Event(instance, corev1.EventTypeWarning, "Conflict", fmt.Sprintf("%d selected workloads are already managed by another IdlingResource or IdlingGroup", conflicts))
+	}
+	instance.Status.Workloads = managed
+	instance.Status.WorkloadStatuses = statuses
+
+	// A failing workload does not prevent the others to be reconciled
+	if failed > 0 {
+		return mergeResults(expirationResult, cronResult), fmt.Errorf("unable to reconcile %d workloads", failed)
+	}
+
+	// Aggregate the state of the workloads
+	switch {
+	case idled > 0:
+		r.Event(instance, corev1.EventTypeNormal, "Idling", fmt.Sprintf("Idled %d workloads", idled))
+		instance.Status.LastIdleTime = &metav1.Time{Time: time.Now()}
+		setGroupIdlingPhase(instance, kidlev1beta1.PhaseIdling)
+	case wokeUp > 0:
+		r.Event(instance, corev1.EventTypeNormal, "WakingUp", fmt.Sprintf("Waked up %d workloads", wokeUp))
+		instance.Status.LastWakeupTime = &metav1.Time{Time: time.Now()}
+		setGroupIdlingPhase(instance, kidlev1beta1.PhaseWakingUp)
+		resetWakeupFailed(&instance.Status.Conditions, instance.Generation)
+	case waiting > 0 && instance.Spec.Idle:
+		setGroupIdlingPhase(instance, kidlev1beta1.PhaseIdling)
+		setGroupCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonWaitingForDependents, fmt.Sprintf("%d workloads are waiting for their dependents to be idled", waiting))
+	case waiting > 0:
+		setGroupIdlingPhase(instance, kidlev1beta1.PhaseWakingUp)
+		setGroupCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonWaitingForDependencies, fmt.Sprintf("%d workloads are waiting for their dependencies to be ready", waiting))
+	case waking > 0:
+		setGroupIdlingPhase(instance, kidlev1beta1.PhaseWakingUp)
+		if wakeupTimedOut(instance.Status.LastWakeupTime, instance.GetWakeupTimeout()) {
+			message := fmt.Sprintf("%d workloads are not ready %s after their wakeup", waking, instance.GetWakeupTimeout())
+			if !meta.IsStatusConditionTrue(instance.Status.Conditions, kidlev1beta1.ConditionWakeupFailed) {
+				r.Event(instance, corev1.EventTypeWarning, ReasonWakeupFailed, message)
+			}
+			setGroupCondition(instance, kidlev1beta1.ConditionWakeupFailed, metav1.ConditionTrue, ReasonWakeupFailed, message)
+			setGroupCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonWakeupFailed, message)
+		}
+	case instance.Spec.Idle:
+		setGroupIdlingPhase(instance, kidlev1beta1.PhaseIdle)
+	default:
+		if instance.Status.Phase == kidlev1beta1.PhaseWakingUp {
+			r.Event(instance, corev1.EventTypeNormal, ReasonReady, "The workloads are ready")
+			setGroupCondition(instance, kidlev1beta1.ConditionWakeupFailed, metav1.ConditionFalse, ReasonReady, "The workloads are ready")
+		}
+		setGroupIdlingPhase(instance, kidlev1beta1.PhaseActive)
+	}
+
+	// The waiting and the waking up workloads are checked again until they are ready
+	result := mergeResults(expirationResult, cronResult)
+	if waiting > 0 {
+		result = mergeResults(result, reconcile.Result{RequeueAfter: DependencyRequeueInterval})
+	}
+	if wokeUp > 0 || waking > 0 {
+		result = mergeResults(result, reconcile.Result{RequeueAfter: ReadinessCheckInterval})
+	}
+	return result, nil
+}
+
+// reconcileWorkload applies the desired idling state of the group on a workload.
+// The wakeup waits for the dependencies of the workload to be ready, the idling waits for the members depending on it to be idled.
+// It returns the state of the workload, its replicas saved before idling and the workloads it is waiting for.
+func (r *IdlingGroupReconciler) reconcileWorkload(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingGroup, workload client.Object, workloads []client.Object, members []client.Object) (kidlev1beta1.IdlingGroupWorkloadState, *int32, []string, error) {
+	if err := claimWorkload(ctx, r.Client, workload, instance.Name); err != nil {
+		return "", nil, nil, fmt.Errorf("unable to label workload: %v", err)
+	}
+
+	i, err := newWorkloadIdler(r.Client, log, workload)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if err := i.SetReference(ctx, instance.Name); err != nil {
+		return "", nil, nil, fmt.Errorf("error during adding annotation: %v", err)
+	}
+
+	desired := instance.IdlingResourceFor(workloadReference(workload))
+	if i.NeedWakeup(desired) || i.NeedIdle(desired) {
+		// A misconfigured dependency is an error rather than a wait which would never end
+		if err := checkDependencies(workload, workloads); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	if i.NeedWakeup(desired) {
+		blockedBy, err := waitingDependencies(r.Client, log, workload, workloads)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if len(blockedBy) > 0 {
+			previousReplicas, err := i.GetPreviousReplicas()
+			return kidlev1beta1.WorkloadWaiting, previousReplicas, blockedBy, err
+		}
+		replicas, err := i.Wakeup(ctx)
+		recordTransition(TransitionWakeup, workloadReference(workload).Kind, instance.Namespace, err)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("error during waking up: %v", err)
+		}
+		return kidlev1beta1.WorkloadWakingUp, replicas, nil, nil
+	}
+	if i.NeedIdle(desired) {
+		blockedBy, err := runningDependents(r.Client, log, workload, members)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if len(blockedBy) > 0 {
+			previousReplicas, err := i.GetPreviousReplicas()
+			return kidlev1beta1.WorkloadWaiting, previousReplicas, blockedBy, err
+		}
+		err = i.Idle(ctx)
+		recordTransition(TransitionIdle, workloadReference(workload).Kind, instance.Namespace, err)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("error during idling: %v", err)
+		}
+		previousReplicas, err := i.GetPreviousReplicas()
+		return kidlev1beta1.WorkloadIdling, previousReplicas, nil, err
+	}
+
+	previousReplicas, err := i.GetPreviousReplicas()
+	if instance.Spec.Idle {
+		return kidlev1beta1.WorkloadIdle, previousReplicas, nil, err
+	}
+	// The waked up workload is active once its replicas are ready
+	if previousWorkloadState(instance, workloadReference(workload)) == kidlev1beta1.WorkloadWakingUp && !i.IsReady() {
+		return kidlev1beta1.WorkloadWakingUp, previousReplicas, nil, err
+	}
+	return kidlev1beta1.WorkloadActive, previousReplicas, nil, err
+}
+
+// releaseWorkloads wakes up the workloads of the group which don't match the selector anymore or are excluded,
+// then removes the kidle annotations and label.
+func (r *IdlingGroupReconciler) releaseWorkloads(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingGroup, selector labels.Selector) error {
+	workloads, err := listWorkloads(ctx, r.Client, instance.Namespace, client.MatchingLabels{kidlev1beta1.LabelIdlingGroup: instance.Name})
+	if err != nil {
+		return err
+	}
+
+	for _, workload := range workloads {
+		if selector.Matches(labels.Set(workload.GetLabels())) && !kidlev1beta1.IsExcluded(workload) {
+			continue
+		}
+		ref := workloadReference(workload)
+
+		i, err := newWorkloadIdler(r.Client, log, workload)
+		if err != nil {
+			return err
+		}
+		awake := instance.IdlingResourceFor(ref)
+		awake.Spec.Idle = false
+		if i.NeedWakeup(awake) {
+			_, err := i.Wakeup(ctx)
+			recordTransition(TransitionWakeup, ref.Kind, instance.Namespace, err)
+			if err != nil {
+				r.Event(instance, corev1.EventTypeWarning, fmt.Sprintf("Restoring%s", ref.Kind), fmt.Sprintf("Failed to restore %s %s: %s", ref.Kind, ref.Name, err))
+				return fmt.Errorf("error during restoring: %v", err)
+			}
+		}
+		if err := i.RemoveAnnotations(ctx); err != nil {
+			return fmt.Errorf("error when removing annotations: %v", err)
+		}
+		if err := unclaimWorkload(ctx, r.Client, workload); err != nil {
+			return fmt.Errorf("error when removing label: %v", err)
+		}
+		r.Event(instance, corev1.EventTypeNormal, "Released", fmt.Sprintf("%s %s is released", ref.Kind, ref.Name))
+	}
+	return nil
+}
+
+func (r *IdlingGroupReconciler) ReconcileCronStrategies(ctx context.Context, instance *kidlev1beta1.IdlingGroup) (ctrl.Result, error) {
+	owner := &CronOwner{
+		Object:   instance,
+		Resource: kidlev1beta1.IdlingGroups,
+		Prefix:   "kidle-group",
+		Args:     []string{"--group", instance.Name},
+	}
+	if instance.Spec.IdlingStrategy != nil {
+		owner.IdlingStrategy = instance.Spec.IdlingStrategy.CronStrategy
+		if instance.Spec.IdlingStrategy.InactiveStrategy != nil {
+			r.Event(instance, corev1.EventTypeWarning, "Unsupported strategy", "The inactive strategy is not supported by an IdlingGroup")
+		}
+	}
+	if instance.Spec.WakeupStrategy != nil {
+		owner.WakeupStrategy = instance.Spec.WakeupStrategy.CronStrategy
+		owner.HolidayCalendar = instance.Spec.WakeupStrategy.HolidayCalendar
+		if instance.Spec.WakeupStrategy.OnCallStrategy != nil {
+			r.Event(instance, corev1.EventTypeWarning, "Unsupported strategy", "The on call strategy is not supported by an IdlingGroup")
+		}
+	}
+
+	cronStrategies := &CronStrategiesReconciler{
+		Client:          r.Client,
+		Scheme:          r.Scheme,
+		EventRecorder:   r.EventRecorder,
+		KidlectlImage:   r.KidlectlImage,
+		CronJobVersion:  r.CronJobVersion,
+		CronJobTimeZone: r.CronJobTimeZone,
+	}
+	return cronStrategies.Reconcile(ctx, owner)
+}
+
+func (r *IdlingGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	workloadPredicates := builder.WithPredicates(predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.LabelChangedPredicate{},
+		predicate.AnnotationChangedPredicate{},
+	))
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&kidlev1beta1.IdlingGroup{}).
+		Owns(newCronJobObject(r.CronJobVersion)).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{})
+
+	// The registered kinds of workloads are watched to select the workloads of the groups
+	for _, registration := range idler.DefaultRegistry.Registrations() {
+		b = b.Watches(
+			&source.Kind{Type: registration.NewObject()},
+			handler.EnqueueRequestsFromMapFunc(r.workloadForIdlingGroupsMapper),
+			workloadPredicates,
+		)
+	}
+	return b.Complete(r)
+}
+
+// workloadForIdlingGroupsMapper requests the reconciliation of the groups selecting or managing a workload
+func (r *IdlingGroupReconciler) workloadForIdlingGroupsMapper(object client.Object) []reconcile.Request {
+	groups := &kidlev1beta1.IdlingGroupList{}
+	if err := r.List(context.Background(), groups, client.InNamespace(object.GetNamespace())); err != nil {
+		r.Log.Error(err, "unable to list idling groups")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+	for _, group := range groups.Items {
+		selector, err := groupSelector(&group)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(object.GetLabels())) || object.GetLabels()[kidlev1beta1.LabelIdlingGroup] == group.Name {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: group.Namespace, Name: group.Name}})
+		}
+	}
+	return reqs
+}
+
+// groupSelector returns the selector of the group, selecting all the workloads if the selector is omitted
+func groupSelector(instance *kidlev1beta1.IdlingGroup) (labels.Selector, error) {
+	if instance.Spec.Selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(instance.Spec.Selector)
+}
+
+// countWorkloadStates returns the number of workloads in the given state
+func countWorkloadStates(statuses []kidlev1beta1.IdlingGroupWorkloadStatus, state kidlev1beta1.IdlingGroupWorkloadState) int {
+	count := 0
+	for _, status := range statuses {
+		if status.State == state {
+			count++
+		}
+	}
+	return count
+}
+
+// listWorkloads returns the workloads of the registered kinds of a namespace.
+// The CronJobs created by kidle for the cron strategies and the workloads controlled by
+// another registered workload, e.g. the jobs of a cronjob, are ignored.
+func listWorkloads(ctx context.Context, c client.Client, namespace string, opts ...client.ListOption) ([]client.Object, error) {
+	opts = append(opts, client.InNamespace(namespace))
+	var workloads []client.Object
+
+	for _, registration := range idler.DefaultRegistry.Registrations() {
+		list := registration.NewList()
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("unable to list %s: %v", registration.GroupVersionKind.Kind, err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the list of %s: %v", registration.GroupVersionKind.Kind, err)
+		}
+		for _, item := range items {
+			workload, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+			if owner := metav1.GetControllerOf(workload); owner != nil {
+				if gv, err := schema.ParseGroupVersion(owner.APIVersion); err == nil && gv.Group == kidlev1beta1.GroupVersion.Group {
+					continue
+				}
+				if idler.DefaultRegistry.Lookup(schema.FromAPIVersionAndKind(owner.APIVersion, owner.Kind)) != nil {
+					continue
+				}
+			}
+			workloads = append(workloads, workload)
+		}
+	}
+	return workloads, nil
+}
+
+// newWorkloadIdler returns the Idler of a workload
+func newWorkloadIdler(c client.Client, log logr.Logger, workload client.Object) (idler.Idler, error) {
+	registration := idler.DefaultRegistry.For(workload)
+	if registration == nil {
+		return nil, fmt.Errorf("unsupported workload %T", workload)
+	}
+	return registration.NewIdler(c, log, workload), nil
+}
+
+// workloadReference returns the reference of a workload
+func workloadReference(workload client.Object) kidlev1beta1.CrossVersionObjectReference {
+	ref := kidlev1beta1.CrossVersionObjectReference{Name: workload.GetName()}
+	if registration := idler.DefaultRegistry.For(workload); registration != nil {
+		ref.Kind, ref.APIVersion = registration.GroupVersionKind.Kind, registration.GroupVersionKind.GroupVersion().String()
+	}
+	return ref
+}
+
+// isManagedByOther returns true if the workload is managed by an IdlingResource or by another IdlingGroup
+func isManagedByOther(workload client.Object, group string) bool {
+	if owner, found := workload.GetLabels()[kidlev1beta1.LabelIdlingGroup]; found {
+		return owner != group
+	}
+	return k8s.HasAnnotation(workload, kidlev1beta1.MetadataIdlingResourceReference)
+}
+
+// managedByMessage describes the IdlingResource or IdlingGroup managing a workload
+func managedByMessage(workload client.Object) string {
+	if group, found := workload.GetLabels()[kidlev1beta1.LabelIdlingGroup]; found {
+		return fmt.Sprintf("Managed by the IdlingGroup %s", group)
+	}
+	ref, _ := k8s.GetAnnotation(workload, kidlev1beta1.MetadataIdlingResourceReference)
+	return fmt.Sprintf("Managed by the IdlingResource %s", ref)
+}
+
+// claimWorkload labels the workload as managed by the group
+func claimWorkload(ctx context.Context, c client.Client, workload client.Object, group string) error {
+	if workload.GetLabels()[kidlev1beta1.LabelIdlingGroup] == group {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(workload), workload); err != nil {
+			return err
+		}
+		l := workload.GetLabels()
+		if l == nil {
+			l = map[string]string{}
+		}
+		l[kidlev1beta1.LabelIdlingGroup] = group
+		workload.SetLabels(l)
+		return c.Update(ctx, workload)
+	})
+}
+
+// unclaimWorkload removes the group label of the workload
+func unclaimWorkload(ctx context.Context, c client.Client, workload client.Object) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(workload), workload); err != nil {
+			return err
+		}
+		l := workload.GetLabels()
+		if _, found := l[kidlev1beta1.LabelIdlingGroup]; !found {
+			return nil
+		}
+		delete(l, kidlev1beta1.LabelIdlingGroup)
+		workload.SetLabels(l)
+		return c.Update(ctx, workload)
+	})
+}
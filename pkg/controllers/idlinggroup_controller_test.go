@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+func newIdlingGroup(key types.NamespacedName, selector *metav1.LabelSelector) *kidlev1beta1.IdlingGroup {
+	return &kidlev1beta1.IdlingGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Spec: kidlev1beta1.IdlingGroupSpec{
+			Selector: selector,
+			Idle:     false,
+		},
+	}
+}
+
+func setGroupIdleFlag(ctx context.Context, key types.NamespacedName, idle bool) error {
+	group := &kidlev1beta1.IdlingGroup{}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := k8sClient.Get(ctx, key, group); err != nil {
+			return err
+		}
+		group.Spec.Idle = idle
+		return k8sClient.Update(ctx, group)
+	})
+}
+
+var _ = Describe("IdlingGroup", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx        = context.Background()
+		groupKey   = types.NamespacedName{Name: "ig-env", Namespace: "default"}
+		frontKey   = types.NamespacedName{Name: "ig-front", Namespace: "default"}
+		backKey    = types.NamespacedName{Name: "ig-back", Namespace: "default"}
+		otherKey   = types.NamespacedName{Name: "ig-other", Namespace: "default"}
+		replicasOf = func(key types.NamespacedName) func() (*int32, error) {
+			return func() (*int32, error) {
+				d := &appsv1.Deployment{}
+				if err := k8sClient.Get(ctx, key, d); err != nil {
+					return nil, err
+				}
+				return d.Spec.Replicas, nil
+			}
+		}
+	)
+
+	It("Should idle and wakeup the selected workloads together", func() {
+		for _, key := range []types.NamespacedName{frontKey, backKey} {
+			d := newDeployment(key, 2)
+			d.Labels = map[string]string{"env": "review"}
+			Expect(k8sClient.Create(ctx, d)).Should(Succeed())
+		}
+		Expect(k8sClient.Create(ctx, newDeployment(otherKey, 1))).Should(Succeed())
+
+		Expect(k8sClient.Create(ctx, newIdlingGroup(groupKey, &metav1.LabelSelector{
+			MatchLabels: map[string]string{"env": "review"},
+		}))).Should(Succeed())
+
+		By("Checking that the selected workloads are managed")
+		Eventually(func() (int32, error) {
+			group := &kidlev1beta1.IdlingGroup{}
+			if err := k8sClient.Get(ctx, groupKey, group); err != nil {
+				return 0, err
+			}
+			return group.Status.Workloads, nil
+		}, timeout, interval).Should(Equal(int32(2)))
+
+		By("Idling the group")
+		Expect(setGroupIdleFlag(ctx, groupKey, true)).Should(Succeed())
+
+		Eventually(replicasOf(frontKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+		Eventually(replicasOf(backKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+		Consistently(replicasOf(otherKey), time.Second, interval).Should(Equal(pointer.Int32(1)))
+
+		By("Checking the annotations of the workloads")
+		d := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, frontKey, d)).Should(Succeed())
+		Expect(d.Labels).To(HaveKeyWithValue(kidlev1beta1.LabelIdlingGroup, groupKey.Name))
+		Expect(d.Annotations).To(HaveKeyWithValue(kidlev1beta1.MetadataIdlingResourceReference, groupKey.Name))
+		Expect(d.Annotations).To(HaveKeyWithValue(kidlev1beta1.MetadataPreviousReplicas, "2"))
+
+		By("Checking the aggregated status")
+		Eventually(func() (kidlev1beta1.IdlingResourcePhase, error) {
+			group := &kidlev1beta1.IdlingGroup{}
+			if err := k8sClient.Get(ctx, groupKey, group); err != nil {
+				return "", err
+			}
+			return group.Status.Phase, nil
+		}, timeout, interval).Should(Equal(kidlev1beta1.PhaseIdle))
+		group := &kidlev1beta1.IdlingGroup{}
+		Expect(k8sClient.Get(ctx, groupKey, group)).Should(Succeed())
+		Expect(meta.IsStatusConditionTrue(group.Status.Conditions, kidlev1beta1.ConditionIdled)).To(BeTrue())
+		Expect(group.Status.LastIdleTime).NotTo(BeNil())
+
+		By("Waking up the group")
+		Expect(setGroupIdleFlag(ctx, groupKey, false)).Should(Succeed())
+
+		Eventually(replicasOf(frontKey), timeout, interval).Should(Equal(pointer.Int32(2)))
+		Eventually(replicasOf(backKey), timeout, interval).Should(Equal(pointer.Int32(2)))
+
+		By("Deleting the group")
+		Expect(k8sClient.Delete(ctx, group)).Should(Succeed())
+		Eventually(func() (map[string]string, error) {
+			d := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, frontKey, d); err != nil {
+				return nil, err
+			}
+			return d.Labels, nil
+		}, timeout, interval).ShouldNot(HaveKey(kidlev1beta1.LabelIdlingGroup))
+	})
+
+	It("Should idle all the workloads of the namespace but the excluded ones", func() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ig-namespace"}}
+		Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+		var (
+			nsGroupKey  = types.NamespacedName{Name: "all", Namespace: ns.Name}
+			webKey      = types.NamespacedName{Name: "web", Namespace: ns.Name}
+			databaseKey = types.NamespacedName{Name: "database", Namespace: ns.Name}
+			lateKey     = types.NamespacedName{Name: "late", Namespace: ns.Name}
+		)
+		Expect(k8sClient.Create(ctx, newDeployment(webKey, 2))).Should(Succeed())
+		database := newDeployment(databaseKey, 1)
+		database.Annotations = map[string]string{kidlev1beta1.MetadataExclude: "true"}
+		Expect(k8sClient.Create(ctx, database)).Should(Succeed())
+
+		group := newIdlingGroup(nsGroupKey, nil)
+		group.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, group)).Should(Succeed())
+
+		Eventually(replicasOf(webKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+		Consistently(replicasOf(databaseKey), time.Second, interval).Should(Equal(pointer.Int32(1)))
+
+		By("Idling a workload created later")
+		Expect(k8sClient.Create(ctx, newDeployment(lateKey, 3))).Should(Succeed())
+		Eventually(replicasOf(lateKey), timeout, interval).Should(Equal(pointer.Int32(0)))
+
+		By("Checking the status of each workload")
+		Eventually(func() ([]kidlev1beta1.IdlingGroupWorkloadStatus, error) {
+			group := &kidlev1beta1.IdlingGroup{}
+			if err := k8sClient.Get(ctx, nsGroupKey, group); err != nil {
+				return nil, err
+			}
+			return group.Status.WorkloadStatuses, nil
+		}, timeout, interval).Should(ConsistOf(
+			kidlev1beta1.IdlingGroupWorkloadStatus{
+				Ref:              kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: webKey.Name, APIVersion: "apps/v1"},
+				State:            kidlev1beta1.WorkloadIdle,
+				PreviousReplicas: pointer.Int32(2),
+			},
+			kidlev1beta1.IdlingGroupWorkloadStatus{
+				Ref:              kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: lateKey.Name, APIVersion: "apps/v1"},
+				State:            kidlev1beta1.WorkloadIdle,
+				PreviousReplicas: pointer.Int32(3),
+			},
+			kidlev1beta1.IdlingGroupWorkloadStatus{
+				Ref:     kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: databaseKey.Name, APIVersion: "apps/v1"},
+				State:   kidlev1beta1.WorkloadExcluded,
+				Message: "Excluded by the kidle.kidle.dev/exclude annotation",
+			},
+		))
+
+		By("Excluding an idled workload")
+		Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			d := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, webKey, d); err != nil {
+				return err
+			}
+			d.Annotations[kidlev1beta1.MetadataExclude] = "true"
+			return k8sClient.Update(ctx, d)
+		})).Should(Succeed())
+		Eventually(replicasOf(webKey), timeout, interval).Should(Equal(pointer.Int32(2)))
+	})
+})
@@ -20,16 +20,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/kidle-dev/kidle/pkg/activator"
 	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
 	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"github.com/kidle-dev/kidle/pkg/notification"
 	"github.com/kidle-dev/kidle/pkg/utils/array"
-	appsv1 "k8s.io/api/apps/v1"
-	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,7 +42,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
-	"strconv"
 	"time"
 )
 
@@ -49,17 +51,45 @@ type IdlingResourceReconciler struct {
 	Log    logr.Logger
 	Scheme *runtime.Scheme
 	record.EventRecorder
-	KidlectlImage string
+	KidlectlImage     string
+	PrometheusAddress string
+	ActivatorService  types.NamespacedName
+	ActivatorPorts    activator.PortRange
+	PricingConfigMap  types.NamespacedName
+	ScaleClient       scale.ScalesGetter
+	CronJobVersion    schema.GroupVersion
+	CronJobTimeZone   bool
+
+	// NotificationNamespace is the namespace of the notification sinks of all the namespaces
+	NotificationNamespace string
+	// NotificationSender sends the notifications, a default sender is used if nil
+	NotificationSender *notification.Sender
+	// APIReader reads the Secrets of the notification sinks and the allocated activator ports without caching them,
+	// the client is used if nil
+	APIReader client.Reader
 }
 
 // +kubebuilder:rbac:groups=kidle.kidle.dev,resources=idlingresources,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kidle.kidle.dev,resources=idlingresources/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;update
-// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=*,resources=*/scale,verbs=get;update
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=kidle.kidle.dev,resources=notificationsinks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=list;deletecollection
 //+kubebuilder:rbac:groups="",resources=events,verbs=create
 
 func (r *IdlingResourceReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
@@ -74,80 +104,119 @@ func (r *IdlingResourceReconciler) Reconcile(ctx context.Context, req reconcile.
 
 	if err != nil {
 		if errors.IsNotFound(err) {
+			forgetIdlingResource(req.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
 	}
+	original := instance.DeepCopy()
 
+	result, err := r.reconcileInstance(ctx, log, &instance)
+
+	// Report the result of the reconciliation in the status
+	if statusErr := r.updateStatus(ctx, original, &instance, err); statusErr != nil {
+		log.Error(statusErr, "unable to update status")
+		if err == nil {
+			return result, statusErr
+		}
+	}
+	r.recordMetrics(ctx, log, &instance)
+
+	// The savings metrics of an idled workload grow with the idle duration
+	if savings := instance.Status.Savings; savings != nil && savings.IdledCPU != nil {
+		result = mergeResults(result, reconcile.Result{RequeueAfter: SavingsRefreshInterval})
+	}
+	return result, err
+}
+
+func (r *IdlingResourceReconciler) reconcileInstance(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingResource) (reconcile.Result, error) {
 	// Add finalizer for any kidleable kind
 	if !instance.HasFinalizer(kidlev1beta1.IdlingResourceFinalizerName) {
-		r.Log.Info(fmt.Sprintf("AddFinalizer for %v", req.NamespacedName))
-		err := r.addFinalizer(ctx, &instance)
+		r.Log.Info(fmt.Sprintf("AddFinalizer for %v", client.ObjectKeyFromObject(instance)))
+		err := r.addFinalizer(ctx, instance)
 		if err != nil {
-			r.Event(&instance, corev1.EventTypeWarning, "Adding finalizer", fmt.Sprintf("Failed to add finalizer: %s", err))
+			r.Event(instance, corev1.EventTypeWarning, "Adding finalizer", fmt.Sprintf("Failed to add finalizer: %s", err))
 			return reconcile.Result{}, fmt.Errorf("error when adding finalizer: %v", err)
 		}
-		r.Event(&instance, corev1.EventTypeNormal, "Added", "Object finalizer is added")
+		r.Event(instance, corev1.EventTypeNormal, "Added", "Object finalizer is added")
 	}
 
-	if result, err := r.ReconcileCronStrategies(ctx, &instance); err != nil {
-		return result, err
+	expirationResult, err := reconcileIdleExpiration(ctx, r.Client, r.EventRecorder, instance,
+		&instance.Spec.Idle, &instance.Spec.IdleExpirationTime, instance.Spec.IdlingStrategy, instance.Spec.WakeupStrategy)
+	if err != nil {
+		return expirationResult, err
 	}
 
-	// Reconcile
-	ref := instance.Spec.IdlingResourceRef
-	key := types.NamespacedName{Namespace: instance.Namespace, Name: ref.Name}
-	switch ref.Kind {
-	case "Deployment":
-
-		var deploy appsv1.Deployment
-		if err := r.Get(ctx, key, &deploy); err != nil {
-			return r.reconcileResourceNotFound(ctx, instance, err)
-		}
-
-		idler := idler.NewDeploymentIdler(r.Client, log, &deploy)
-		return r.ReconcileWithIdler(ctx, &instance, idler)
+	cronResult, err := r.ReconcileCronStrategies(ctx, instance)
+	if err != nil {
+		setCondition(instance, kidlev1beta1.ConditionSchedulesConfigured, metav1.ConditionFalse, ReasonReconcileFailed, err.Error())
+		return cronResult, err
+	}
+	setCondition(instance, kidlev1beta1.ConditionSchedulesConfigured, metav1.ConditionTrue, ReasonReconciled, "The cron strategies are up to date")
 
-	case "StatefulSet":
+	inactiveResult, err := r.ReconcileInactiveStrategy(ctx, instance)
+	if err != nil {
+		return inactiveResult, err
+	}
 
-		var sts appsv1.StatefulSet
-		if err := r.Get(ctx, key, &sts); err != nil {
-			return r.reconcileResourceNotFound(ctx, instance, err)
+	// The services must be routed to the workload again before the finalizer is removed
+	if instance.IsBeingDeleted() {
+		if err := r.restoreServices(ctx, instance, ""); err != nil {
+			return reconcile.Result{}, err
 		}
+		return r.reconcileReference(ctx, log, instance)
+	}
 
-		idler := idler.NewStatefulSetIdler(r.Client, log, &sts)
-		return r.ReconcileWithIdler(ctx, &instance, idler)
+	result, err := r.reconcileReference(ctx, log, instance)
+	if err != nil {
+		return result, err
+	}
 
-	case "CronJob":
+	onCallResult, err := r.ReconcileOnCallStrategy(ctx, instance)
+	return mergeResults(result, expirationResult, cronResult, inactiveResult, onCallResult), err
+}
 
-		var cronJob batchv1beta1.CronJob
-		if err := r.Get(ctx, key, &cronJob); err != nil {
+func (r *IdlingResourceReconciler) reconcileReference(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingResource) (reconcile.Result, error) {
+	ref := instance.Spec.IdlingResourceRef
+	if registration := idler.DefaultRegistry.Lookup(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)); registration != nil {
+		workload := registration.NewObject()
+		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: ref.Name}, workload); err != nil {
 			return r.reconcileResourceNotFound(ctx, instance, err)
 		}
-
-		idler := idler.NewCronJobIdler(r.Client, log, &cronJob)
-		return r.ReconcileWithIdler(ctx, &instance, idler)
+		return r.ReconcileWithIdler(ctx, log, instance, registration.NewIdler(r.Client, log, workload))
 	}
 
-	return ctrl.Result{}, nil
+	// Any other kind is idled through its scale subresource
+	i, err := r.getScaleIdler(ctx, log, instance.Namespace, ref)
+	if err != nil {
+		if isUnsupportedKind(err) {
+			setReferenceNotFound(instance, ReasonUnsupportedKind, err.Error())
+			return ctrl.Result{}, nil
+		}
+		return r.reconcileResourceNotFound(ctx, instance, err)
+	}
+	return r.ReconcileWithIdler(ctx, log, instance, i)
 }
 
-func (r *IdlingResourceReconciler) reconcileResourceNotFound(ctx context.Context, instance kidlev1beta1.IdlingResource, err error) (reconcile.Result, error) {
+func (r *IdlingResourceReconciler) reconcileResourceNotFound(ctx context.Context, instance *kidlev1beta1.IdlingResource, err error) (reconcile.Result, error) {
 	if errors.IsNotFound(err) {
 		if instance.IsBeingDeleted() {
-			if err := r.removeFinalizer(ctx, &instance); err != nil {
+			if err := r.removeFinalizer(ctx, instance); err != nil {
 				return ctrl.Result{}, fmt.Errorf("error when deleting finalizer: %v", err)
 			}
 			return ctrl.Result{}, nil
 		}
+		ref := instance.Spec.IdlingResourceRef
+		setReferenceNotFound(instance, ReasonNotFound, fmt.Sprintf("%s %s not found", ref.Kind, ref.Name))
 		return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
 	}
 	return ctrl.Result{}, fmt.Errorf("unable to read %s: %v", instance.Spec.IdlingResourceRef.Kind, err)
 }
 
-func (r *IdlingResourceReconciler) ReconcileWithIdler(ctx context.Context, instance *kidlev1beta1.IdlingResource, idler idler.Idler) (ctrl.Result, error) {
+func (r *IdlingResourceReconciler) ReconcileWithIdler(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingResource, idler idler.Idler) (ctrl.Result, error) {
 
 	ref := instance.Spec.IdlingResourceRef
+	setCondition(instance, kidlev1beta1.ConditionReferenceFound, metav1.ConditionTrue, ReasonFound, fmt.Sprintf("%s %s found", ref.Kind, ref.Name))
 
 	// Add a reference on the object
 	err := idler.SetReference(ctx, instance.Name)
@@ -158,15 +227,22 @@ func (r *IdlingResourceReconciler) ReconcileWithIdler(ctx context.Context, insta
 	// Deal with the idling resource deletion
 	if instance.IsBeingDeleted() {
 
-		// Wakeup object
+		// Wakeup object, the wakeup of an idled workload is notified
+		idled := instance.Status.Phase == kidlev1beta1.PhaseIdle || instance.Status.Phase == kidlev1beta1.PhaseIdling
 		replicas, err := idler.Wakeup(ctx)
+		recordTransition(TransitionWakeup, ref.Kind, instance.Namespace, err)
 		if err != nil {
 			r.Event(instance,
 				corev1.EventTypeWarning,
 				fmt.Sprintf("Restoring%s", ref.Kind),
 				fmt.Sprintf("Failed to restore %s %s: %s", ref.Kind, ref.Name, err))
+			r.notifyFailure(ctx, instance, kidlev1beta1.NotificationWakeupFailed, fmt.Sprintf("Failed to restore %s %s: %s", ref.Kind, ref.Name, err))
 			return ctrl.Result{}, fmt.Errorf("error during restoring: %v", err)
 		}
+		r.stopSavings(ctx, instance)
+		if idled {
+			r.notify(ctx, instance, kidlev1beta1.NotificationWakedUp, fmt.Sprintf("%s %s is waked up, its IdlingResource is deleted", ref.Kind, ref.Name))
+		}
 		// TODO ugly hack, needs to find better way to handle CronJob Suspend field
 		if replicas != nil {
 			r.Event(instance,
@@ -208,14 +284,30 @@ func (r *IdlingResourceReconciler) ReconcileWithIdler(ctx context.Context, insta
 		return ctrl.Result{}, nil
 	}
 
+	// Apply the drift policy if the idled object has been rescaled by someone else
+	if leave, err := r.reconcileDrift(ctx, instance, idler); err != nil || leave {
+		return ctrl.Result{}, err
+	}
+
+	// Wake up after the dependencies and idle after the dependents
+	blocked, err := r.reconcileDependencies(ctx, log, instance, idler)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if blocked {
+		return ctrl.Result{RequeueAfter: DependencyRequeueInterval}, nil
+	}
+
 	// Wakeup object
 	if idler.NeedWakeup(instance) {
 		replicas, err := idler.Wakeup(ctx)
+		recordTransition(TransitionWakeup, ref.Kind, instance.Namespace, err)
 		if err != nil {
 			r.Event(instance,
 				corev1.EventTypeWarning,
 				fmt.Sprintf("Scaling%s", ref.Kind),
 				fmt.Sprintf("Failed to wake up %s %s: %s", ref.Kind, ref.Name, err))
+			r.notifyFailure(ctx, instance, kidlev1beta1.NotificationWakeupFailed, fmt.Sprintf("Failed to wake up %s %s: %s", ref.Kind, ref.Name, err))
 			return ctrl.Result{}, fmt.Errorf("error during waking up: %v", err)
 		}
 		// TODO ugly hack, needs to find better way to handle CronJob Suspend field
@@ -230,24 +322,54 @@ func (r *IdlingResourceReconciler) ReconcileWithIdler(ctx context.Context, insta
 				fmt.Sprintf("Scaling%s", ref.Kind),
 				"WakedUp")
 		}
-		return ctrl.Result{}, nil
+		r.stopSavings(ctx, instance)
+		r.notify(ctx, instance, kidlev1beta1.NotificationWakedUp, fmt.Sprintf("%s %s is waked up", ref.Kind, ref.Name))
+		instance.Status.LastWakeupTime = &metav1.Time{Time: time.Now()}
+		instance.Status.PreviousReplicas = replicas
+		setIdlingPhase(instance, kidlev1beta1.PhaseWakingUp)
+		resetWakeupFailed(&instance.Status.Conditions, instance.Generation)
+		return ctrl.Result{RequeueAfter: ReadinessCheckInterval}, nil
 	}
 
 	// Idle object
 	if idler.NeedIdle(instance) {
-		if err := idler.Idle(ctx); err != nil {
+		err := idler.Idle(ctx)
+		recordTransition(TransitionIdle, ref.Kind, instance.Namespace, err)
+		if err != nil {
 			r.Event(instance,
 				corev1.EventTypeWarning,
 				fmt.Sprintf("Scaling%s", ref.Kind),
 				fmt.Sprintf("Failed to idle %s %s: %s", ref.Kind, ref.Name, err))
+			r.notifyFailure(ctx, instance, kidlev1beta1.NotificationIdleFailed, fmt.Sprintf("Failed to idle %s %s: %s", ref.Kind, ref.Name, err))
 			return ctrl.Result{}, fmt.Errorf("error during idling: %v", err)
 		}
+		r.notify(ctx, instance, kidlev1beta1.NotificationIdled, fmt.Sprintf("%s %s is idled", ref.Kind, ref.Name))
 		r.Event(instance,
 			corev1.EventTypeNormal,
 			fmt.Sprintf("Scaling%s", ref.Kind),
 			"Scaled to 0")
+		previousReplicas, err := idler.GetPreviousReplicas()
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		instance.Status.LastIdleTime = &metav1.Time{Time: time.Now()}
+		instance.Status.PreviousReplicas = previousReplicas
+		startSavings(instance, idler, previousReplicas)
+		setIdlingPhase(instance, kidlev1beta1.PhaseIdling)
+		return ctrl.Result{}, nil
+	}
+
+	// Nothing to do, the workload is in the desired state
+	if instance.Spec.Idle {
+		setIdlingPhase(instance, kidlev1beta1.PhaseIdle)
 		return ctrl.Result{}, nil
 	}
+
+	// The waked up workload is active once its replicas are ready
+	if instance.Status.Phase == kidlev1beta1.PhaseWakingUp {
+		return r.reconcileReadiness(instance, idler), nil
+	}
+	setIdlingPhase(instance, kidlev1beta1.PhaseActive)
 	return ctrl.Result{}, nil
 }
 
@@ -291,26 +413,22 @@ func (r *IdlingResourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	//	return err
 	//}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&kidlev1beta1.IdlingResource{}).
-		Owns(&batchv1beta1.CronJob{}).
+		Owns(newCronJobObject(r.CronJobVersion)).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
-		WithEventFilter(&KidleChangedPredicate{}).
-		Watches(
-			&source.Kind{Type: &appsv1.Deployment{}},
-			handler.EnqueueRequestsFromMapFunc(r.objectForIdlingResourceMapper),
-		).
-		Watches(
-			&source.Kind{Type: &appsv1.StatefulSet{}},
-			handler.EnqueueRequestsFromMapFunc(r.objectForIdlingResourceMapper),
-		).
-		Watches(
-			&source.Kind{Type: &batchv1beta1.CronJob{}},
+		WithEventFilter(&KidleChangedPredicate{Registry: idler.DefaultRegistry})
+
+	// The registered kinds of workloads are watched to enforce their expected state
+	for _, registration := range idler.DefaultRegistry.Registrations() {
+		b = b.Watches(
+			&source.Kind{Type: registration.NewObject()},
 			handler.EnqueueRequestsFromMapFunc(r.objectForIdlingResourceMapper),
-		).
-		Complete(r)
+		)
+	}
+	return b.Complete(r)
 }
 
 func (r *IdlingResourceReconciler) objectForIdlingResourceMapper(object client.Object) []reconcile.Request {
@@ -319,6 +437,11 @@ func (r *IdlingResourceReconciler) objectForIdlingResourceMapper(object client.O
 		return nil
 	}
 
+	// The workloads of an IdlingGroup are reconciled by the IdlingGroup controller
+	if _, found := object.GetLabels()[kidlev1beta1.LabelIdlingGroup]; found {
+		return nil
+	}
+
 	reqs := make([]reconcile.Request, 1)
 	reqs[0].NamespacedName.Name = ref
 	reqs[0].NamespacedName.Namespace = object.GetNamespace()
@@ -326,54 +449,34 @@ func (r *IdlingResourceReconciler) objectForIdlingResourceMapper(object client.O
 	return reqs
 }
 
+// mergeResults merges reconcile results by requeuing at the earliest requested time
+func mergeResults(results ...reconcile.Result) reconcile.Result {
+	var merged reconcile.Result
+	for _, result := range results {
+		merged.Requeue = merged.Requeue || result.Requeue
+		if result.RequeueAfter > 0 && (merged.RequeueAfter == 0 || result.RequeueAfter < merged.RequeueAfter) {
+			merged.RequeueAfter = result.RequeueAfter
+		}
+	}
+	return merged
+}
+
+// KidleChangedPredicate filters the updates of the workloads, keeping those whose state is not the expected one.
+// The state of a workload is given by the registration of its kind, the updates of the other objects are kept.
 type KidleChangedPredicate struct {
 	predicate.Funcs
+	Registry *idler.Registry
 }
 
 func (rl *KidleChangedPredicate) Update(e event.UpdateEvent) bool {
-	oldDeploy, ok1 := e.ObjectOld.(*appsv1.Deployment)
-	newDeploy, ok2 := e.ObjectNew.(*appsv1.Deployment)
-	if ok1 && ok2 {
-		if _, found := oldDeploy.GetAnnotations()[kidlev1beta1.MetadataIdlingResourceReference]; !found {
-			return false
-		}
-
-		expected, found := newDeploy.GetAnnotations()[kidlev1beta1.MetadataExpectedState]
-		if found && strconv.Itoa(int(*newDeploy.Spec.Replicas)) != expected {
-			return true
-		} else {
-			return false
-		}
+	registration := rl.Registry.For(e.ObjectNew)
+	if registration == nil {
+		return true
 	}
-
-	oldSts, ok1 := e.ObjectOld.(*appsv1.StatefulSet)
-	newSts, ok2 := e.ObjectNew.(*appsv1.StatefulSet)
-	if ok1 && ok2 {
-		if _, found := oldSts.GetAnnotations()[kidlev1beta1.MetadataIdlingResourceReference]; !found {
-			return false
-		}
-
-		expected, found := newSts.GetAnnotations()[kidlev1beta1.MetadataExpectedState]
-		if found && strconv.Itoa(int(*newSts.Spec.Replicas)) != expected {
-			return true
-		} else {
-			return false
-		}
+	if _, found := e.ObjectOld.GetAnnotations()[kidlev1beta1.MetadataIdlingResourceReference]; !found {
+		return false
 	}
 
-	oldCj, ok1 := e.ObjectOld.(*batchv1beta1.CronJob)
-	newCj, ok2 := e.ObjectNew.(*batchv1beta1.CronJob)
-	if ok1 && ok2 {
-		if _, found := oldCj.GetAnnotations()[kidlev1beta1.MetadataIdlingResourceReference]; !found {
-			return false
-		}
-
-		expected, found := newCj.GetAnnotations()[kidlev1beta1.MetadataExpectedState]
-		if found && strconv.FormatBool(*newCj.Spec.Suspend) != expected {
-			return true
-		} else {
-			return false
-		}
-	}
-	return true
+	expected, found := e.ObjectNew.GetAnnotations()[kidlev1beta1.MetadataExpectedState]
+	return found && registration.State(e.ObjectNew) != expected
 }
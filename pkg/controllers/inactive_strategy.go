@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// DefaultInactivityCheckInterval is the default interval between two inactivity queries
+	DefaultInactivityCheckInterval = time.Minute
+)
+
+// ReconcileInactiveStrategy queries Prometheus and idles the workload
+// once the activity stays under the threshold for the configured duration.
+func (r *IdlingResourceReconciler) ReconcileInactiveStrategy(ctx context.Context, instance *kidlev1beta1.IdlingResource) (ctrl.Result, error) {
+	if instance.Spec.IdlingStrategy == nil || instance.Spec.IdlingStrategy.InactiveStrategy == nil {
+		instance.Status.InactiveSince = nil
+		return ctrl.Result{}, nil
+	}
+
+	// Nothing to monitor on an idled workload, the inactivity restarts on wakeup
+	if instance.Spec.Idle {
+		instance.Status.InactiveSince = nil
+		return ctrl.Result{}, nil
+	}
+
+	// The workload stays awake until the expiration of its idle flag, e.g. after a kidlectl wakeup --for
+	if expiration := instance.Spec.IdleExpirationTime; expiration != nil && time.Now().Before(expiration.Time) {
+		instance.Status.InactiveSince = nil
+		return ctrl.Result{RequeueAfter: time.Until(expiration.Time)}, nil
+	}
+
+	strategy := instance.Spec.IdlingStrategy.InactiveStrategy
+	interval := DefaultInactivityCheckInterval
+	if strategy.Interval != nil && strategy.Interval.Duration > 0 {
+		interval = strategy.Interval.Duration
+	}
+
+	address := strategy.PrometheusAddress
+	if address == "" {
+		address = r.PrometheusAddress
+	}
+	if address == "" {
+		r.Event(instance, corev1.EventTypeWarning, "Querying activity", "No prometheus address configured")
+		return ctrl.Result{}, nil
+	}
+
+	querier, err := prometheus.NewClient(address)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// A failing query must not idle the workload, it is retried on next interval
+	value, err := querier.Query(ctx, strategy.Query)
+	if err != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Querying activity", fmt.Sprintf("Failed to query activity: %s", err))
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	if value >= strategy.Threshold.AsApproximateFloat64() {
+		instance.Status.InactiveSince = nil
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	now := time.Now()
+	if instance.Status.InactiveSince == nil {
+		instance.Status.InactiveSince = &metav1.Time{Time: now}
+	}
+
+	inactivity := now.Sub(instance.Status.InactiveSince.Time)
+	if inactivity < strategy.Duration.Duration {
+		remaining := strategy.Duration.Duration - inactivity
+		if remaining < interval {
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	// The workload is inactive for long enough, idle it
+	status := instance.Status.DeepCopy()
+	instance.Spec.Idle = true
+	if err := r.Update(ctx, instance); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to set idle flag: %v", err)
+	}
+	instance.Status = *status
+	instance.Status.InactiveSince = nil
+
+	r.Event(instance, corev1.EventTypeNormal, "Inactive", fmt.Sprintf("Inactive for %s, idling", inactivity.Round(time.Second)))
+	return ctrl.Result{}, nil
+}
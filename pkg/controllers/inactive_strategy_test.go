@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newFakePrometheus starts a fake Prometheus server answering every query with the given value
+func newFakePrometheus(value string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[%d,"%s"]}]}}`, time.Now().Unix(), value)
+	}))
+}
+
+func newDeployment(key types.NamespacedName, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(replicas),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": key.Name},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": key.Name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "nginx",
+							Image: "nginx",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("inactive strategy", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx = context.Background()
+	)
+
+	newInactiveIdlingResource := func(irKey types.NamespacedName, deployKey types.NamespacedName, address string) *kidlev1beta1.IdlingResource {
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.IdlingStrategy = &kidlev1beta1.IdlingStrategy{
+			InactiveStrategy: &kidlev1beta1.InactiveStrategy{
+				PrometheusAddress: address,
+				Query:             "sum(rate(http_requests_total[5m]))",
+				Threshold:         resource.MustParse("1"),
+				Duration:          metav1.Duration{Duration: 2 * time.Second},
+				Interval:          &metav1.Duration{Duration: time.Second},
+			},
+		}
+		return ir
+	}
+
+	Context("Inactive workload", func() {
+		var (
+			irKey     = types.NamespacedName{Name: "ir-inactive", Namespace: "default"}
+			deployKey = types.NamespacedName{Name: "inactive", Namespace: "default"}
+			server    *httptest.Server
+		)
+
+		BeforeEach(func() {
+			server = newFakePrometheus("0")
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should idle the Deployment after the inactivity duration", func() {
+			Expect(k8sClient.Create(ctx, newDeployment(deployKey, 1))).Should(Succeed())
+			Expect(k8sClient.Create(ctx, newInactiveIdlingResource(irKey, deployKey, server.URL))).Should(Succeed())
+
+			By("Checking that the idle flag is set")
+			Eventually(func() (bool, error) {
+				ir := &kidlev1beta1.IdlingResource{}
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return false, err
+				}
+				return ir.Spec.Idle, nil
+			}, timeout, interval).Should(BeTrue())
+
+			By("Checking that Replicas == 0")
+			Eventually(func() (*int32, error) {
+				d := &appsv1.Deployment{}
+				if err := k8sClient.Get(ctx, deployKey, d); err != nil {
+					return nil, err
+				}
+				return d.Spec.Replicas, nil
+			}, timeout, interval).Should(Equal(pointer.Int32(0)))
+		})
+	})
+
+	Context("Workload waked up until an expiration", func() {
+		var (
+			irKey     = types.NamespacedName{Name: "ir-inactive-expiration", Namespace: "default"}
+			deployKey = types.NamespacedName{Name: "inactive-expiration", Namespace: "default"}
+			server    *httptest.Server
+		)
+
+		BeforeEach(func() {
+			server = newFakePrometheus("0")
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should not idle the Deployment before the expiration", func() {
+			Expect(k8sClient.Create(ctx, newDeployment(deployKey, 1))).Should(Succeed())
+			ir := newInactiveIdlingResource(irKey, deployKey, server.URL)
+			ir.Spec.IdleExpirationTime = &metav1.Time{Time: time.Now().Add(time.Hour)}
+			Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+			By("Checking that the idle flag is not set")
+			Consistently(func() (bool, error) {
+				ir := &kidlev1beta1.IdlingResource{}
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return false, err
+				}
+				return ir.Spec.Idle || ir.Status.InactiveSince != nil, nil
+			}, 5*time.Second, interval).Should(BeFalse())
+		})
+	})
+
+	Context("Active workload", func() {
+		var (
+			irKey     = types.NamespacedName{Name: "ir-active", Namespace: "default"}
+			deployKey = types.NamespacedName{Name: "active", Namespace: "default"}
+			server    *httptest.Server
+		)
+
+		BeforeEach(func() {
+			server = newFakePrometheus("5")
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should not idle the Deployment", func() {
+			Expect(k8sClient.Create(ctx, newDeployment(deployKey, 1))).Should(Succeed())
+			Expect(k8sClient.Create(ctx, newInactiveIdlingResource(irKey, deployKey, server.URL))).Should(Succeed())
+
+			By("Checking that the idle flag is not set")
+			Consistently(func() (bool, error) {
+				ir := &kidlev1beta1.IdlingResource{}
+				if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+					return false, err
+				}
+				return ir.Spec.Idle || ir.Status.InactiveSince != nil, nil
+			}, 5*time.Second, interval).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// TransitionIdle labels the metrics of the idlings
+	TransitionIdle = "idle"
+	// TransitionWakeup labels the metrics of the wakeups
+	TransitionWakeup = "wakeup"
+)
+
+var (
+	// idlingResourceIdle is 1 if the workload of an IdlingResource is idled, 0 otherwise
+	idlingResourceIdle = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kidle_idlingresource_idle",
+		Help: "Whether the workload of the IdlingResource is idled (1) or not (0)",
+	}, []string{"namespace", "name"})
+
+	// idledReplicas is the number of replicas held at zero by an IdlingResource
+	idledReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kidle_idled_replicas",
+		Help: "Number of replicas held at zero by the IdlingResource",
+	}, []string{"namespace", "name"})
+
+	// idlingGroupIdledReplicas is the number of replicas of a member of an IdlingGroup held at zero
+	idlingGroupIdledReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kidle_idlinggroup_idled_replicas",
+		Help: "Number of replicas of a workload held at zero by the IdlingGroup",
+	}, []string{"namespace", "name", "kind", "workload"})
+
+	// savedCPU is the CPU saved by the idlings of an IdlingResource, in core-hours
+	savedCPU = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kidle_saved_cpu_core_hours",
+		Help: "CPU requests saved by the idlings of the IdlingResource, in core-hours",
+	}, []string{"namespace", "name"})
+
+	// savedMemory is the memory saved by the idlings of an IdlingResource, in GB-hours
+	savedMemory = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kidle_saved_memory_gb_hours",
+		Help: "Memory requests saved by the idlings of the IdlingResource, in GB-hours",
+	}, []string{"namespace", "name"})
+
+	// savedCost is the estimated cost saved by the idlings of an IdlingResource
+	savedCost = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kidle_saved_cost",
+		Help: "Estimated cost saved by the idlings of the IdlingResource, with the prices of the pricing ConfigMap",
+	}, []string{"namespace", "name"})
+
+	// transitions counts the idlings and the wakeups of the workloads
+	transitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kidle_transitions_total",
+		Help: "Number of idlings and wakeups of the workloads",
+	}, []string{"transition", "kind", "namespace"})
+
+	// transitionFailures counts the idlings and the wakeups which have failed
+	transitionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kidle_transition_failures_total",
+		Help: "Number of failed idlings and wakeups of the workloads",
+	}, []string{"transition", "kind", "namespace"})
+
+	// wakeupDuration is the duration between the wakeup of a workload and the readiness of its replicas
+	wakeupDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kidle_wakeup_duration_seconds",
+		Help:    "Duration between the wakeup of a workload and the readiness of its replicas",
+		Buckets: []float64{5, 10, 20, 30, 60, 120, 300, 600, 1200},
+	}, []string{"kind", "namespace"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(idlingResourceIdle, idledReplicas, idlingGroupIdledReplicas, savedCPU, savedMemory, savedCost,
+		transitions, transitionFailures, wakeupDuration)
+}
+
+// idlingGroupMembers are the workloads whose metrics are recorded for each IdlingGroup,
+// to remove the metrics of the workloads leaving the group
+var idlingGroupMembers = struct {
+	sync.Mutex
+	refs map[types.NamespacedName][]kidlev1beta1.CrossVersionObjectReference
+}{refs: map[types.NamespacedName][]kidlev1beta1.CrossVersionObjectReference{}}
+
+// recordTransition counts an idling or a wakeup of a workload, or its failure
+func recordTransition(transition string, kind string, namespace string, err error) {
+	if err != nil {
+		transitionFailures.WithLabelValues(transition, kind, namespace).Inc()
+		return
+	}
+	transitions.WithLabelValues(transition, kind, namespace).Inc()
+}
+
+// recordWakeupDuration observes the duration of a wakeup once the replicas of the workload are ready
+func recordWakeupDuration(kind string, namespace string, wakeup time.Time) {
+	wakeupDuration.WithLabelValues(kind, namespace).Observe(time.Since(wakeup).Seconds())
+}
+
+// recordMetrics updates the metrics of an IdlingResource, the cost is only estimated with a pricing ConfigMap
+func (r *IdlingResourceReconciler) recordMetrics(ctx context.Context, log logr.Logger, instance *kidlev1beta1.IdlingResource) {
+	pricing, err := r.getPricing(ctx)
+	if err != nil {
+		log.Error(err, "unable to read the prices")
+	}
+	recordIdlingResourceState(instance)
+
+	totals := getSavingsTotals(instance, pricing, time.Now())
+	savedCPU.WithLabelValues(instance.Namespace, instance.Name).Set(totals.cpuCoreHours)
+	savedMemory.WithLabelValues(instance.Namespace, instance.Name).Set(totals.memoryGBHours)
+	if pricing != nil {
+		savedCost.WithLabelValues(instance.Namespace, instance.Name).Set(totals.cost)
+	}
+}
+
+// recordIdlingResourceState updates the idle state and the replicas held at zero of an IdlingResource.
+// They are derived from the idle flag and the saved replicas rather than from the phase, e.g. a failed wakeup is still idled.
+func recordIdlingResourceState(instance *kidlev1beta1.IdlingResource) {
+	idle, replicas := 0.0, 0.0
+	if instance.Spec.Idle {
+		idle = 1
+		if instance.Status.PreviousReplicas != nil {
+			replicas = float64(*instance.Status.PreviousReplicas)
+		}
+	}
+	idlingResourceIdle.WithLabelValues(instance.Namespace, instance.Name).Set(idle)
+	idledReplicas.WithLabelValues(instance.Namespace, instance.Name).Set(replicas)
+}
+
+// recordIdlingGroupState updates the replicas held at zero of the members of an IdlingGroup.
+// A failed member keeps the replicas saved before the failure.
+func recordIdlingGroupState(original *kidlev1beta1.IdlingGroup, instance *kidlev1beta1.IdlingGroup) {
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+	var refs []kidlev1beta1.CrossVersionObjectReference
+	for _, status := range instance.Status.WorkloadStatuses {
+		previousReplicas := status.PreviousReplicas
+		if status.State == kidlev1beta1.WorkloadError {
+			previousReplicas = previousWorkloadReplicas(original, status.Ref)
+		}
+		replicas := 0.0
+		if instance.Spec.Idle && previousReplicas != nil {
+			replicas = float64(*previousReplicas)
+		}
+		idlingGroupIdledReplicas.WithLabelValues(instance.Namespace, instance.Name, status.Ref.Kind, status.Ref.Name).Set(replicas)
+		refs = append(refs, status.Ref)
+	}
+
+	idlingGroupMembers.Lock()
+	defer idlingGroupMembers.Unlock()
+	for _, ref := range idlingGroupMembers.refs[key] {
+		if !containsReference(refs, ref) {
+			idlingGroupIdledReplicas.DeleteLabelValues(instance.Namespace, instance.Name, ref.Kind, ref.Name)
+		}
+	}
+	idlingGroupMembers.refs[key] = refs
+}
+
+// previousWorkloadReplicas returns the replicas saved for a workload in the status of a group, if any
+func previousWorkloadReplicas(instance *kidlev1beta1.IdlingGroup, ref kidlev1beta1.CrossVersionObjectReference) *int32 {
+	for _, status := range instance.Status.WorkloadStatuses {
+		if status.Ref == ref {
+			return status.PreviousReplicas
+		}
+	}
+	return nil
+}
+
+// containsReference returns true if the references contain the given reference
+func containsReference(refs []kidlev1beta1.CrossVersionObjectReference, ref kidlev1beta1.CrossVersionObjectReference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetIdlingGroup removes the metrics of the members of a deleted IdlingGroup
+func forgetIdlingGroup(key types.NamespacedName) {
+	idlingGroupMembers.Lock()
+	defer idlingGroupMembers.Unlock()
+	for _, ref := range idlingGroupMembers.refs[key] {
+		idlingGroupIdledReplicas.DeleteLabelValues(key.Namespace, key.Name, ref.Kind, ref.Name)
+	}
+	delete(idlingGroupMembers.refs, key)
+}
+
+// forgetIdlingResource removes the metrics of a deleted IdlingResource
+func forgetIdlingResource(key types.NamespacedName) {
+	idlingResourceIdle.DeleteLabelValues(key.Namespace, key.Name)
+	idledReplicas.DeleteLabelValues(key.Namespace, key.Name)
+	savedCPU.DeleteLabelValues(key.Namespace, key.Name)
+	savedMemory.DeleteLabelValues(key.Namespace, key.Name)
+	savedCost.DeleteLabelValues(key.Namespace, key.Name)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("metrics", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx = context.Background()
+	)
+
+	It("Should report the idle state and the transitions of an IdlingResource", func() {
+		irKey := types.NamespacedName{Name: "ir-metrics", Namespace: "default"}
+		deployKey := types.NamespacedName{Name: "metrics", Namespace: "default"}
+		idlings := testutil.ToFloat64(transitions.WithLabelValues(TransitionIdle, "Deployment", "default"))
+		wakeups := testutil.ToFloat64(transitions.WithLabelValues(TransitionWakeup, "Deployment", "default"))
+
+		Expect(k8sClient.Create(ctx, newDeployment(deployKey, 3))).Should(Succeed())
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking the metrics of the idled deployment")
+		Eventually(func() float64 {
+			return testutil.ToFloat64(idlingResourceIdle.WithLabelValues(irKey.Namespace, irKey.Name))
+		}, timeout, interval).Should(Equal(1.0))
+		Eventually(func() float64 {
+			return testutil.ToFloat64(idledReplicas.WithLabelValues(irKey.Namespace, irKey.Name))
+		}, timeout, interval).Should(Equal(3.0))
+		Expect(testutil.ToFloat64(transitions.WithLabelValues(TransitionIdle, "Deployment", "default"))).Should(Equal(idlings + 1))
+
+		By("Checking the metrics of the waked up deployment")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(func() float64 {
+			return testutil.ToFloat64(idlingResourceIdle.WithLabelValues(irKey.Namespace, irKey.Name))
+		}, timeout, interval).Should(Equal(0.0))
+		Expect(testutil.ToFloat64(idledReplicas.WithLabelValues(irKey.Namespace, irKey.Name))).Should(Equal(0.0))
+		Expect(testutil.ToFloat64(transitions.WithLabelValues(TransitionWakeup, "Deployment", "default"))).Should(Equal(wakeups + 1))
+	})
+
+	It("Should keep the replicas held at zero of a failed IdlingResource", func() {
+		ir := newIdlingResource(types.NamespacedName{Name: "ir-metrics-error", Namespace: "default"}, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       "metrics-error",
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.Idle = true
+		ir.Status.Phase = kidlev1beta1.PhaseError
+		ir.Status.PreviousReplicas = pointer.Int32(2)
+
+		recordIdlingResourceState(ir)
+		Expect(testutil.ToFloat64(idlingResourceIdle.WithLabelValues(ir.Namespace, ir.Name))).Should(Equal(1.0))
+		Expect(testutil.ToFloat64(idledReplicas.WithLabelValues(ir.Namespace, ir.Name))).Should(Equal(2.0))
+	})
+
+	It("Should report the replicas held at zero of the members of an IdlingGroup", func() {
+		groupKey := types.NamespacedName{Name: "ig-metrics", Namespace: "default"}
+		front := kidlev1beta1.CrossVersionObjectReference{Kind: "Deployment", Name: "front", APIVersion: "apps/v1"}
+		back := kidlev1beta1.CrossVersionObjectReference{Kind: "StatefulSet", Name: "back", APIVersion: "apps/v1"}
+		members := func() int {
+			return testutil.CollectAndCount(idlingGroupIdledReplicas)
+		}
+		count := members()
+
+		original := newIdlingGroup(groupKey, nil)
+		original.Spec.Idle = true
+		original.Status.WorkloadStatuses = []kidlev1beta1.IdlingGroupWorkloadStatus{
+			{Ref: front, State: kidlev1beta1.WorkloadIdle, PreviousReplicas: pointer.Int32(3)},
+			{Ref: back, State: kidlev1beta1.WorkloadIdle, PreviousReplicas: pointer.Int32(1)},
+		}
+		recordIdlingGroupState(original, original)
+		Expect(testutil.ToFloat64(idlingGroupIdledReplicas.WithLabelValues("default", groupKey.Name, "Deployment", "front"))).Should(Equal(3.0))
+		Expect(testutil.ToFloat64(idlingGroupIdledReplicas.WithLabelValues("default", groupKey.Name, "StatefulSet", "back"))).Should(Equal(1.0))
+
+		By("Keeping the replicas of a failed member and removing a released member")
+		instance := original.DeepCopy()
+		instance.Status.WorkloadStatuses = []kidlev1beta1.IdlingGroupWorkloadStatus{
+			{Ref: front, State: kidlev1beta1.WorkloadError, Message: "error"},
+		}
+		recordIdlingGroupState(original, instance)
+		Expect(testutil.ToFloat64(idlingGroupIdledReplicas.WithLabelValues("default", groupKey.Name, "Deployment", "front"))).Should(Equal(3.0))
+		Expect(members()).Should(Equal(count + 1))
+
+		By("Removing the metrics of the deleted group")
+		forgetIdlingGroup(groupKey)
+		Expect(members()).Should(Equal(count))
+	})
+})
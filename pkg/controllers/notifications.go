@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/notification"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReasonNotificationFailed is the reason of a notification which cannot be sent to a sink
+const ReasonNotificationFailed = "NotificationFailed"
+
+// notify sends a transition of the workload of an IdlingResource to the sinks of its namespace
+// and to the sinks of the notification namespace. A failed delivery is reported as an event, it does not fail the reconciliation.
+func (r *IdlingResourceReconciler) notify(ctx context.Context, instance *kidlev1beta1.IdlingResource, event kidlev1beta1.NotificationEvent, message string) {
+	sinks, err := r.listNotificationSinks(ctx, instance.Namespace)
+	if err != nil {
+		r.Event(instance, corev1.EventTypeWarning, ReasonNotificationFailed, err.Error())
+		return
+	}
+
+	ref := instance.Spec.IdlingResourceRef
+	n := &notification.Notification{
+		Event:          event,
+		Namespace:      instance.Namespace,
+		IdlingResource: instance.Name,
+		Kind:           ref.Kind,
+		Name:           ref.Name,
+		Message:        message,
+		Time:           time.Now().UTC(),
+	}
+	for _, sink := range sinks {
+		if !sink.Spec.Notifies(event) {
+			continue
+		}
+		if err := r.sendNotification(ctx, &sink, n); err != nil {
+			r.Event(instance, corev1.EventTypeWarning, ReasonNotificationFailed,
+				fmt.Sprintf("Failed to notify %s/%s: %s", sink.Namespace, sink.Name, err))
+		}
+	}
+}
+
+// notifyFailure notifies a failed transition when the IdlingResource enters the failure.
+// The retries of a failing reconciliation keep the Error phase, they are not notified again.
+func (r *IdlingResourceReconciler) notifyFailure(ctx context.Context, instance *kidlev1beta1.IdlingResource, event kidlev1beta1.NotificationEvent, message string) {
+	if instance.Status.Phase == kidlev1beta1.PhaseError {
+		return
+	}
+	r.notify(ctx, instance, event, message)
+}
+
+// listNotificationSinks returns the sinks of a namespace and the sinks of the notification namespace
+func (r *IdlingResourceReconciler) listNotificationSinks(ctx context.Context, namespace string) ([]kidlev1beta1.NotificationSink, error) {
+	namespaces := []string{namespace}
+	if r.NotificationNamespace != "" && r.NotificationNamespace != namespace {
+		namespaces = append(namespaces, r.NotificationNamespace)
+	}
+
+	var sinks []kidlev1beta1.NotificationSink
+	for _, ns := range namespaces {
+		list := &kidlev1beta1.NotificationSinkList{}
+		if err := r.List(ctx, list, client.InNamespace(ns)); err != nil {
+			return nil, fmt.Errorf("unable to list the notification sinks of %s: %v", ns, err)
+		}
+		sinks = append(sinks, list.Items...)
+	}
+	return sinks, nil
+}
+
+// sendNotification resolves the URL and the authorization of a sink from its Secrets, then sends the notification
+func (r *IdlingResourceReconciler) sendNotification(ctx context.Context, sink *kidlev1beta1.NotificationSink, n *notification.Notification) error {
+	url := sink.Spec.URL
+	if ref := sink.Spec.URLSecretRef; ref != nil {
+		value, err := r.getSecretValue(ctx, sink.Namespace, ref)
+		if err != nil {
+			return err
+		}
+		url = value
+	}
+	if url == "" {
+		return fmt.Errorf("no URL configured")
+	}
+
+	var authorization string
+	if ref := sink.Spec.AuthorizationSecretRef; ref != nil {
+		value, err := r.getSecretValue(ctx, sink.Namespace, ref)
+		if err != nil {
+			return err
+		}
+		authorization = value
+	}
+
+	sender := r.NotificationSender
+	if sender == nil {
+		sender = notification.NewSender()
+	}
+	return sender.Send(ctx, sink.Spec.Type, url, authorization, n)
+}
+
+// getSecretValue reads a key of a Secret. The Secrets are read from the API server, they are not cached by the operator.
+func (r *IdlingResourceReconciler) getSecretValue(ctx context.Context, namespace string, ref *corev1.SecretKeySelector) (string, error) {
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+	if err := reader.Get(ctx, key, secret); err != nil {
+		return "", fmt.Errorf("unable to get secret %s: %v", key, err)
+	}
+	value, found := secret.Data[ref.Key]
+	if !found {
+		return "", fmt.Errorf("key %s not found in secret %s", ref.Key, key)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/notification"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("notifications of the transitions", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx       = context.Background()
+		namespace = "notifications"
+		irKey     = types.NamespacedName{Name: "ir-notifications", Namespace: namespace}
+		deployKey = types.NamespacedName{Name: "notified", Namespace: namespace}
+	)
+
+	It("Should notify the idling and the wakeup to the sinks of the namespace", func() {
+		var (
+			lock          sync.Mutex
+			notifications []notification.Notification
+			authorization string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := notification.Notification{}
+			if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			notifications = append(notifications, n)
+			authorization = r.Header.Get("Authorization")
+		}))
+		defer server.Close()
+		events := func() []kidlev1beta1.NotificationEvent {
+			lock.Lock()
+			defer lock.Unlock()
+			var events []kidlev1beta1.NotificationEvent
+			for _, n := range notifications {
+				events = append(events, n.Event)
+			}
+			return events
+		}
+
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).Should(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: namespace},
+			StringData: map[string]string{"url": server.URL, "authorization": "Bearer s3cr3t"},
+		})).Should(Succeed())
+		Expect(k8sClient.Create(ctx, &kidlev1beta1.NotificationSink{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: namespace},
+			Spec: kidlev1beta1.NotificationSinkSpec{
+				Type: kidlev1beta1.NotificationSinkWebhook,
+				URLSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "webhook"},
+					Key:                  "url",
+				},
+				AuthorizationSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "webhook"},
+					Key:                  "authorization",
+				},
+				Events: []kidlev1beta1.NotificationEvent{kidlev1beta1.NotificationIdled, kidlev1beta1.NotificationWakedUp},
+			},
+		})).Should(Succeed())
+
+		Expect(k8sClient.Create(ctx, newDeployment(deployKey, 2))).Should(Succeed())
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Checking that the idling is notified")
+		Eventually(events, timeout, interval).Should(ContainElement(kidlev1beta1.NotificationIdled))
+
+		lock.Lock()
+		Expect(notifications[0].Namespace).Should(Equal(namespace))
+		Expect(notifications[0].IdlingResource).Should(Equal(irKey.Name))
+		Expect(notifications[0].Kind).Should(Equal("Deployment"))
+		Expect(notifications[0].Name).Should(Equal(deployKey.Name))
+		Expect(authorization).Should(Equal("Bearer s3cr3t"))
+		lock.Unlock()
+
+		By("Checking that the wakeup is notified")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(events, timeout, interval).Should(ContainElement(kidlev1beta1.NotificationWakedUp))
+	})
+
+	It("Should notify a failure once, not on each retry", func() {
+		var (
+			lock     sync.Mutex
+			failures int
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			failures++
+		}))
+		defer server.Close()
+		Expect(k8sClient.Create(ctx, &kidlev1beta1.NotificationSink{
+			ObjectMeta: metav1.ObjectMeta{Name: "failures", Namespace: namespace},
+			Spec: kidlev1beta1.NotificationSinkSpec{
+				Type:   kidlev1beta1.NotificationSinkWebhook,
+				URL:    server.URL,
+				Events: []kidlev1beta1.NotificationEvent{kidlev1beta1.NotificationWakeupFailed},
+			},
+		})).Should(Succeed())
+
+		r := &IdlingResourceReconciler{Client: k8sClient, EventRecorder: record.NewFakeRecorder(10)}
+		ir := newIdlingResource(types.NamespacedName{Name: "ir-failures", Namespace: namespace}, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Status.Phase = kidlev1beta1.PhaseIdle
+		r.notifyFailure(ctx, ir, kidlev1beta1.NotificationWakeupFailed, "Failed to wake up")
+
+		By("Retrying the failed wakeup")
+		ir.Status.Phase = kidlev1beta1.PhaseError
+		r.notifyFailure(ctx, ir, kidlev1beta1.NotificationWakeupFailed, "Failed to wake up")
+
+		lock.Lock()
+		defer lock.Unlock()
+		Expect(failures).Should(Equal(1))
+	})
+})
@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kidle-dev/kidle/pkg/activator"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ActivatorRefreshInterval is the interval between two refreshes of the activator endpoints on routed services
+	ActivatorRefreshInterval = 30 * time.Second
+
+	// WakeupPollInterval is the interval between two readiness checks of a waking up workload
+	WakeupPollInterval = time.Second
+
+	// endpointSliceControllerName is the name of the controller managing the endpoint slices of services with selectors
+	endpointSliceControllerName = "endpointslice-controller.k8s.io"
+)
+
+// ReconcileOnCallStrategy routes the Service of an idled workload to the activator,
+// then restores the Service once the workload has ready pods again.
+func (r *IdlingResourceReconciler) ReconcileOnCallStrategy(ctx context.Context, instance *kidlev1beta1.IdlingResource) (ctrl.Result, error) {
+	var strategy *kidlev1beta1.OnCallStrategy
+	if instance.Spec.WakeupStrategy != nil {
+		strategy = instance.Spec.WakeupStrategy.OnCallStrategy
+	}
+
+	// Restore the services routed by a previous on call strategy
+	keep := ""
+	if strategy != nil {
+		keep = strategy.ServiceName
+	}
+	if err := r.restoreServices(ctx, instance, keep); err != nil {
+		return ctrl.Result{}, err
+	}
+	if strategy == nil {
+		return ctrl.Result{}, nil
+	}
+
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: strategy.ServiceName}, svc); err != nil {
+		if errors.IsNotFound(err) {
+			r.Event(instance, corev1.EventTypeWarning, "Routing Service", fmt.Sprintf("Service %s not found", strategy.ServiceName))
+			return ctrl.Result{RequeueAfter: ActivatorRefreshInterval}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("unable to get service: %v", err)
+	}
+
+	if instance.Spec.Idle {
+		return r.routeToActivator(ctx, instance, svc, strategy)
+	}
+
+	// The workload is waking up, wait for a ready pod before restoring the service
+	if !k8s.HasAnnotation(svc, kidlev1beta1.MetadataPreviousSelector) {
+		return ctrl.Result{}, nil
+	}
+	ready, err := r.hasReadyPods(ctx, svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ready {
+		return ctrl.Result{RequeueAfter: WakeupPollInterval}, nil
+	}
+	if err := r.restoreService(ctx, svc); err != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Restoring Service", fmt.Sprintf("Failed to restore Service %s: %s", svc.Name, err))
+		return ctrl.Result{}, err
+	}
+	r.Event(instance, corev1.EventTypeNormal, "Restoring Service", fmt.Sprintf("Service %s routed to the workload", svc.Name))
+	return ctrl.Result{}, nil
+}
+
+// routeToActivator removes the selector of the Service and points its endpoints to the port of the activator allocated to the Service
+func (r *IdlingResourceReconciler) routeToActivator(ctx context.Context, instance *kidlev1beta1.IdlingResource, svc *corev1.Service, strategy *kidlev1beta1.OnCallStrategy) (ctrl.Result, error) {
+	svcPort, err := k8s.FindServicePort(svc, strategy.Port)
+	if err != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Routing Service", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	addresses, err := r.getActivatorAddresses(ctx)
+	if err != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Routing Service", fmt.Sprintf("Activator unavailable: %s", err))
+		return ctrl.Result{RequeueAfter: ActivatorRefreshInterval}, nil
+	}
+
+	// Allocate a port of the activator to the Service, then save its selector and remove it,
+	// so that the endpoints are not managed by kubernetes anymore
+	if !k8s.HasAnnotation(svc, kidlev1beta1.MetadataPreviousSelector) || !k8s.HasAnnotation(svc, kidlev1beta1.MetadataActivatorPort) {
+		port, err := r.allocateActivatorPort(ctx)
+		if err != nil {
+			r.Event(instance, corev1.EventTypeWarning, "Routing Service", err.Error())
+			return ctrl.Result{RequeueAfter: ActivatorRefreshInterval}, nil
+		}
+		routed := k8s.HasAnnotation(svc, kidlev1beta1.MetadataPreviousSelector)
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := r.Get(ctx, client.ObjectKeyFromObject(svc), svc); err != nil {
+				return err
+			}
+			if !k8s.HasAnnotation(svc, kidlev1beta1.MetadataPreviousSelector) {
+				selector, err := json.Marshal(svc.Spec.Selector)
+				if err != nil {
+					return fmt.Errorf("unable to save service selector: %v", err)
+				}
+				k8s.AddAnnotation(svc, kidlev1beta1.MetadataIdlingResourceReference, instance.Name)
+				k8s.AddAnnotation(svc, kidlev1beta1.MetadataPreviousSelector, string(selector))
+				svc.Spec.Selector = nil
+			}
+			k8s.AddAnnotation(svc, kidlev1beta1.MetadataActivatorPort, strconv.Itoa(int(port)))
+			return r.Update(ctx, svc)
+		})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to remove service selector: %v", err)
+		}
+
+		if !routed {
+			// The endpoint slices of the selector are not deleted by kubernetes
+			if err := r.DeleteAllOf(ctx, &discoveryv1.EndpointSlice{},
+				client.InNamespace(svc.Namespace),
+				client.MatchingLabels{
+					discoveryv1.LabelServiceName: svc.Name,
+					discoveryv1.LabelManagedBy:   endpointSliceControllerName,
+				}); err != nil {
+				return ctrl.Result{}, fmt.Errorf("unable to delete endpoint slices: %v", err)
+			}
+			r.Event(instance, corev1.EventTypeNormal, "Routing Service", fmt.Sprintf("Service %s routed to the activator", svc.Name))
+		}
+	}
+
+	port, err := getActivatorPort(svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	subset := corev1.EndpointSubset{
+		Addresses: addresses,
+		Ports: []corev1.EndpointPort{{
+			Name:     svcPort.Name,
+			Port:     port,
+			Protocol: corev1.ProtocolTCP,
+		}},
+	}
+
+	endpoints := &corev1.Endpoints{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(svc), endpoints); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("unable to get endpoints: %v", err)
+		}
+		endpoints = &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: svc.Namespace,
+				Name:      svc.Name,
+			},
+			Subsets: []corev1.EndpointSubset{subset},
+		}
+		if err := r.Create(ctx, endpoints); err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to create endpoints: %v", err)
+		}
+	} else if !equality.Semantic.DeepEqual(endpoints.Subsets, []corev1.EndpointSubset{subset}) {
+		endpoints.Subsets = []corev1.EndpointSubset{subset}
+		if err := r.Update(ctx, endpoints); err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to update endpoints: %v", err)
+		}
+	}
+
+	// The activator pods may change over time
+	return ctrl.Result{RequeueAfter: ActivatorRefreshInterval}, nil
+}
+
+// getActivatorAddresses returns the addresses of the ready activator pods
+func (r *IdlingResourceReconciler) getActivatorAddresses(ctx context.Context) ([]corev1.EndpointAddress, error) {
+	if r.ActivatorService.Name == "" {
+		return nil, fmt.Errorf("no activator service configured")
+	}
+
+	activator := &corev1.Endpoints{}
+	if err := r.Get(ctx, r.ActivatorService, activator); err != nil {
+		return nil, fmt.Errorf("unable to get activator endpoints: %v", err)
+	}
+
+	var addresses []corev1.EndpointAddress
+	for _, s := range activator.Subsets {
+		for _, address := range s.Addresses {
+			addresses = append(addresses, corev1.EndpointAddress{IP: address.IP})
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no ready activator")
+	}
+	return addresses, nil
+}
+
+// allocateActivatorPort returns the first port of the activator port range which is not allocated to a Service.
+// The Services are read from the API server, the cache may not contain the latest allocations yet.
+func (r *IdlingResourceReconciler) allocateActivatorPort(ctx context.Context) (int32, error) {
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	services := &corev1.ServiceList{}
+	if err := reader.List(ctx, services); err != nil {
+		return 0, fmt.Errorf("unable to list services: %v", err)
+	}
+	allocated := map[int32]bool{}
+	for i := range services.Items {
+		if port, err := getActivatorPort(&services.Items[i]); err == nil {
+			allocated[port] = true
+		}
+	}
+
+	ports := r.ActivatorPorts
+	if ports.First == 0 {
+		ports = activator.DefaultPortRange
+	}
+	for port := ports.First; port <= ports.Last; port++ {
+		if !allocated[port] {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free port in the activator port range %s", ports)
+}
+
+// getActivatorPort returns the port of the activator allocated to the service
+func getActivatorPort(svc *corev1.Service) (int32, error) {
+	value, found := k8s.GetAnnotation(svc, kidlev1beta1.MetadataActivatorPort)
+	if !found {
+		return 0, fmt.Errorf("no activator port allocated to service %s", svc.Name)
+	}
+	port, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %v", kidlev1beta1.MetadataActivatorPort, err)
+	}
+	return int32(port), nil
+}
+
+// hasReadyPods returns true if a pod selected by the saved selector of the service is ready
+func (r *IdlingResourceReconciler) hasReadyPods(ctx context.Context, svc *corev1.Service) (bool, error) {
+	selector, err := getPreviousSelector(svc)
+	if err != nil {
+		return false, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(svc.Namespace), client.MatchingLabels(selector)); err != nil {
+		return false, fmt.Errorf("unable to list pods: %v", err)
+	}
+	for i := range pods.Items {
+		if k8s.IsPodReady(&pods.Items[i]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// restoreServices restores the services routed to the activator for the instance, except the kept one
+func (r *IdlingResourceReconciler) restoreServices(ctx context.Context, instance *kidlev1beta1.IdlingResource, keep string) error {
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, client.InNamespace(instance.Namespace)); err != nil {
+		return fmt.Errorf("unable to list services: %v", err)
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if svc.Name == keep || !k8s.HasAnnotation(svc, kidlev1beta1.MetadataPreviousSelector) {
+			continue
+		}
+		if ref, _ := k8s.GetAnnotation(svc, kidlev1beta1.MetadataIdlingResourceReference); ref != instance.Name {
+			continue
+		}
+		if err := r.restoreService(ctx, svc); err != nil {
+			return err
+		}
+		r.Event(instance, corev1.EventTypeNormal, "Restoring Service", fmt.Sprintf("Service %s routed to the workload", svc.Name))
+	}
+	return nil
+}
+
+// restoreService sets back the saved selector of the service.
+// The endpoints are then managed by kubernetes again.
+func (r *IdlingResourceReconciler) restoreService(ctx context.Context, svc *corev1.Service) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(svc), svc); err != nil {
+			return err
+		}
+		selector, err := getPreviousSelector(svc)
+		if err != nil {
+			return err
+		}
+		svc.Spec.Selector = selector
+		k8s.RemoveAnnotation(svc, kidlev1beta1.MetadataPreviousSelector)
+		k8s.RemoveAnnotation(svc, kidlev1beta1.MetadataIdlingResourceReference)
+		k8s.RemoveAnnotation(svc, kidlev1beta1.MetadataActivatorPort)
+		return r.Update(ctx, svc)
+	})
+}
+
+// getPreviousSelector decodes the selector saved in the service annotations
+func getPreviousSelector(svc *corev1.Service) (map[string]string, error) {
+	value, _ := k8s.GetAnnotation(svc, kidlev1beta1.MetadataPreviousSelector)
+	var selector map[string]string
+	if err := json.Unmarshal([]byte(value), &selector); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", kidlev1beta1.MetadataPreviousSelector, err)
+	}
+	return selector, nil
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("on call strategy", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx       = context.Background()
+		irKey     = types.NamespacedName{Name: "ir-oncall", Namespace: "default"}
+		deployKey = types.NamespacedName{Name: "oncall", Namespace: "default"}
+	)
+
+	It("Should route the Service to the activator when idled", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: activatorService.Name, Namespace: activatorService.Namespace},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+				Ports:     []corev1.EndpointPort{{Name: "http", Port: 8000}},
+			}},
+		})).Should(Succeed())
+
+		Expect(k8sClient.Create(ctx, newDeployment(deployKey, 1))).Should(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: deployKey.Name, Namespace: deployKey.Namespace},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": deployKey.Name},
+				Ports:    []corev1.ServicePort{{Name: "web", Port: 80, TargetPort: intstr.FromInt(8080)}},
+			},
+		})).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.WakeupStrategy = &kidlev1beta1.WakeupStrategy{
+			OnCallStrategy: &kidlev1beta1.OnCallStrategy{
+				ServiceName: deployKey.Name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		By("Idling the workload")
+		Expect(setIdleFlag(ctx, irKey, true)).Should(Succeed())
+
+		By("Checking that the Service selector is removed")
+		Eventually(func() (map[string]string, error) {
+			svc := &corev1.Service{}
+			if err := k8sClient.Get(ctx, deployKey, svc); err != nil {
+				return nil, err
+			}
+			if !k8s.HasAnnotation(svc, kidlev1beta1.MetadataPreviousSelector) {
+				return map[string]string{"not": "routed"}, nil
+			}
+			return svc.Spec.Selector, nil
+		}, timeout, interval).Should(BeEmpty())
+
+		By("Checking that a port of the activator is allocated to the Service")
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, deployKey, svc)).Should(Succeed())
+		Expect(svc.Annotations).Should(HaveKeyWithValue(kidlev1beta1.MetadataActivatorPort, "8100"))
+
+		By("Checking that the Endpoints target the activator")
+		Eventually(func() ([]corev1.EndpointSubset, error) {
+			endpoints := &corev1.Endpoints{}
+			if err := k8sClient.Get(ctx, deployKey, endpoints); err != nil {
+				return nil, err
+			}
+			return endpoints.Subsets, nil
+		}, timeout, interval).Should(ConsistOf(corev1.EndpointSubset{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "web", Port: 8100, Protocol: corev1.ProtocolTCP}},
+		}))
+
+		By("Deleting the IdlingResource")
+		Expect(k8sClient.Delete(ctx, ir)).Should(Succeed())
+
+		By("Checking that the Service selector is restored")
+		Eventually(func() (map[string]string, error) {
+			svc := &corev1.Service{}
+			if err := k8sClient.Get(ctx, deployKey, svc); err != nil {
+				return nil, err
+			}
+			return svc.Spec.Selector, nil
+		}, timeout, interval).Should(Equal(map[string]string{"app": deployKey.Name}))
+		Expect(k8sClient.Get(ctx, deployKey, svc)).Should(Succeed())
+		Expect(svc.Annotations).ShouldNot(HaveKey(kidlev1beta1.MetadataActivatorPort))
+	})
+})
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReadinessCheckInterval is the interval between two checks of the readiness of a waking up workload
+const ReadinessCheckInterval = 5 * time.Second
+
+const (
+	// ReasonWakeupFailed is the reason of a workload not ready before the wakeup timeout
+	ReasonWakeupFailed = "WakeupFailed"
+	// ReasonReady is the reason of a waked up workload whose replicas are ready
+	ReasonReady = "Ready"
+)
+
+// reconcileReadiness keeps the WakingUp phase until all the replicas of the waked up workload are ready.
+// The WakeupFailed condition is raised once the wakeup timeout is exceeded.
+func (r *IdlingResourceReconciler) reconcileReadiness(instance *kidlev1beta1.IdlingResource, i idler.Idler) reconcile.Result {
+	ref := instance.Spec.IdlingResourceRef
+	if i.IsReady() {
+		message := fmt.Sprintf("%s %s is ready", ref.Kind, ref.Name)
+		if wakeup := instance.Status.LastWakeupTime; wakeup != nil {
+			message = fmt.Sprintf("%s %s is ready %s after its wakeup", ref.Kind, ref.Name, time.Since(wakeup.Time).Round(time.Second))
+			recordWakeupDuration(ref.Kind, instance.Namespace, wakeup.Time)
+		}
+		r.Event(instance, corev1.EventTypeNormal, ReasonReady, message)
+		setIdlingPhase(instance, kidlev1beta1.PhaseActive)
+		setCondition(instance, kidlev1beta1.ConditionWakeupFailed, metav1.ConditionFalse, ReasonReady, message)
+		return reconcile.Result{}
+	}
+
+	setIdlingPhase(instance, kidlev1beta1.PhaseWakingUp)
+	if wakeupTimedOut(instance.Status.LastWakeupTime, instance.GetWakeupTimeout()) {
+		message := fmt.Sprintf("%s %s is not ready %s after its wakeup", ref.Kind, ref.Name, instance.GetWakeupTimeout())
+		if !meta.IsStatusConditionTrue(instance.Status.Conditions, kidlev1beta1.ConditionWakeupFailed) {
+			r.Event(instance, corev1.EventTypeWarning, ReasonWakeupFailed, message)
+		}
+		setCondition(instance, kidlev1beta1.ConditionWakeupFailed, metav1.ConditionTrue, ReasonWakeupFailed, message)
+		setCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonWakeupFailed, message)
+	}
+	return reconcile.Result{RequeueAfter: ReadinessCheckInterval}
+}
+
+// resetWakeupFailed clears the WakeupFailed condition of a previous wakeup, if any
+func resetWakeupFailed(conditions *[]metav1.Condition, generation int64) {
+	if meta.FindStatusCondition(*conditions, kidlev1beta1.ConditionWakeupFailed) != nil {
+		setStatusCondition(conditions, generation, kidlev1beta1.ConditionWakeupFailed, metav1.ConditionFalse, ReasonWakingUp, "Waiting for the replicas to be ready")
+	}
+}
+
+// wakeupTimedOut returns true if the wakeup started at the given time has exceeded the timeout
+func wakeupTimedOut(wakeup *metav1.Time, timeout time.Duration) bool {
+	return wakeup != nil && time.Since(wakeup.Time) > timeout
+}
+
+// previousWorkloadState returns the state of a workload reported by the last reconciliation of the group
+func previousWorkloadState(instance *kidlev1beta1.IdlingGroup, ref kidlev1beta1.CrossVersionObjectReference) kidlev1beta1.IdlingGroupWorkloadState {
+	for _, status := range instance.Status.WorkloadStatuses {
+		if status.Ref.Kind == ref.Kind && status.Ref.Name == ref.Name {
+			return status.State
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("readiness", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx = context.Background()
+	)
+
+	It("Should report the wakeup until the replicas are ready", func() {
+		irKey := types.NamespacedName{Name: "ir-readiness", Namespace: "default"}
+		deployKey := types.NamespacedName{Name: "readiness", Namespace: "default"}
+
+		Expect(k8sClient.Create(ctx, newDeployment(deployKey, 2))).Should(Succeed())
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       deployKey.Name,
+			APIVersion: "apps/v1",
+		})
+		ir.Spec.Idle = true
+		ir.Spec.WakeupTimeout = &metav1.Duration{Duration: 2 * time.Second}
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		getPhase := func() (kidlev1beta1.IdlingResourcePhase, error) {
+			if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+				return "", err
+			}
+			return ir.Status.Phase, nil
+		}
+		Eventually(getPhase, timeout, interval).Should(Equal(kidlev1beta1.PhaseIdle))
+
+		By("Checking that the phase is WakingUp while the replicas are not ready")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(getPhase, timeout, interval).Should(Equal(kidlev1beta1.PhaseWakingUp))
+		Expect(meta.IsStatusConditionFalse(ir.Status.Conditions, kidlev1beta1.ConditionReady)).Should(BeTrue())
+
+		By("Checking that the WakeupFailed condition is raised after the timeout")
+		Eventually(func() (bool, error) {
+			if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+				return false, err
+			}
+			return meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionWakeupFailed), nil
+		}, timeout, interval).Should(BeTrue())
+		Expect(ir.Status.Phase).Should(Equal(kidlev1beta1.PhaseWakingUp))
+		Expect(meta.FindStatusCondition(ir.Status.Conditions, kidlev1beta1.ConditionReady).Reason).Should(Equal(ReasonWakeupFailed))
+
+		By("Checking that the IdlingResource is active once the replicas are ready")
+		Expect(setDeploymentReady(ctx, deployKey)).Should(Succeed())
+		Eventually(getPhase, timeout, interval).Should(Equal(kidlev1beta1.PhaseActive))
+		Expect(meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionReady)).Should(BeTrue())
+		Expect(meta.IsStatusConditionFalse(ir.Status.Conditions, kidlev1beta1.ConditionWakeupFailed)).Should(BeTrue())
+	})
+})
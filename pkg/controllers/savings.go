@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PricingCPUHourKey is the key of the price of a CPU core per hour in the pricing ConfigMap
+	PricingCPUHourKey = "cpu-hour"
+	// PricingMemoryGBHourKey is the key of the price of a GB of memory per hour in the pricing ConfigMap
+	PricingMemoryGBHourKey = "memory-gb-hour"
+
+	// SavingsRefreshInterval is the interval between two updates of the savings metrics of an idled workload
+	SavingsRefreshInterval = 5 * time.Minute
+
+	// bytesPerGB converts the memory requests to GB
+	bytesPerGB = 1e9
+)
+
+// Pricing holds the prices used to estimate the cost saved by the idlings
+type Pricing struct {
+	CPUHour      float64
+	MemoryGBHour float64
+}
+
+// savingsTotals are the resources and the cost saved by the idlings
+type savingsTotals struct {
+	cpuCoreHours  float64
+	memoryGBHours float64
+	cost          float64
+}
+
+// getPricing reads the prices of the pricing ConfigMap, nil if the operator has no pricing ConfigMap
+func (r *IdlingResourceReconciler) getPricing(ctx context.Context) (*Pricing, error) {
+	if r.PricingConfigMap.Name == "" {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, r.PricingConfigMap, cm); err != nil {
+		return nil, fmt.Errorf("unable to get configmap %s: %v", r.PricingConfigMap, err)
+	}
+	pricing := &Pricing{}
+	for key, price := range map[string]*float64{PricingCPUHourKey: &pricing.CPUHour, PricingMemoryGBHourKey: &pricing.MemoryGBHour} {
+		value, found := cm.Data[key]
+		if !found {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s price in configmap %s: %v", key, r.PricingConfigMap, err)
+		}
+		*price = v
+	}
+	return pricing, nil
+}
+
+// startSavings records the resource requests of the pods removed by an idling
+func startSavings(instance *kidlev1beta1.IdlingResource, i idler.Idler, replicas *int32) {
+	w, ok := i.(idler.WorkloadIdler)
+	if !ok || replicas == nil || *replicas == 0 {
+		return
+	}
+	podCPU, podMemory := podRequests(w.Workload())
+	cpu := resource.NewMilliQuantity(podCPU.MilliValue()*int64(*replicas), resource.DecimalSI)
+	memory := resource.NewQuantity(podMemory.Value()*int64(*replicas), resource.BinarySI)
+
+	if instance.Status.Savings == nil {
+		instance.Status.Savings = &kidlev1beta1.Savings{}
+	}
+	instance.Status.Savings.IdledCPU = cpu
+	instance.Status.Savings.IdledMemory = memory
+}
+
+// stopSavings accrues the savings of the current idling once the workload runs again, e.g. on wakeup or after a drift
+func (r *IdlingResourceReconciler) stopSavings(ctx context.Context, instance *kidlev1beta1.IdlingResource) {
+	pricing, err := r.getPricing(ctx)
+	if err != nil {
+		r.Event(instance, corev1.EventTypeWarning, "Pricing", fmt.Sprintf("Unable to estimate the cost saved: %s", err))
+	}
+	accrueSavings(instance, pricing, time.Now())
+}
+
+// accrueSavings adds the resources saved by the current idling to the totals of the status, on wakeup
+func accrueSavings(instance *kidlev1beta1.IdlingResource, pricing *Pricing, now time.Time) {
+	savings := instance.Status.Savings
+	if savings == nil || savings.IdledCPU == nil {
+		return
+	}
+	totals := getSavingsTotals(instance, pricing, now)
+	savings.CPUCoreHours = strconv.FormatFloat(totals.cpuCoreHours, 'f', 3, 64)
+	savings.MemoryGBHours = strconv.FormatFloat(totals.memoryGBHours, 'f', 3, 64)
+	if pricing != nil {
+		savings.Cost = strconv.FormatFloat(totals.cost, 'f', 2, 64)
+	}
+	savings.IdledCPU, savings.IdledMemory = nil, nil
+}
+
+// getSavingsTotals returns the totals of the status and the resources saved by the current idling until now
+func getSavingsTotals(instance *kidlev1beta1.IdlingResource, pricing *Pricing, now time.Time) savingsTotals {
+	var totals savingsTotals
+	savings := instance.Status.Savings
+	if savings == nil {
+		return totals
+	}
+	totals.cpuCoreHours, _ = strconv.ParseFloat(savings.CPUCoreHours, 64)
+	totals.memoryGBHours, _ = strconv.ParseFloat(savings.MemoryGBHours, 64)
+	totals.cost, _ = strconv.ParseFloat(savings.Cost, 64)
+
+	if savings.IdledCPU != nil && instance.Status.LastIdleTime != nil {
+		hours := now.Sub(instance.Status.LastIdleTime.Time).Hours()
+		cpuCoreHours := float64(savings.IdledCPU.MilliValue()) / 1000 * hours
+		memoryGBHours := 0.0
+		if savings.IdledMemory != nil {
+			memoryGBHours = float64(savings.IdledMemory.Value()) / bytesPerGB * hours
+		}
+		totals.cpuCoreHours += cpuCoreHours
+		totals.memoryGBHours += memoryGBHours
+		if pricing != nil {
+			totals.cost += cpuCoreHours*pricing.CPUHour + memoryGBHours*pricing.MemoryGBHour
+		}
+	}
+	return totals
+}
+
+// podRequests returns the CPU and memory requests of the containers of the pod template of a workload.
+// The pod template is read at spec.template, as for the Deployments, StatefulSets, Jobs and most custom resources.
+func podRequests(workload client.Object) (resource.Quantity, resource.Quantity) {
+	var cpu, memory resource.Quantity
+	u, ok := workload.(*unstructured.Unstructured)
+	if !ok {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workload)
+		if err != nil {
+			return cpu, memory
+		}
+		u = &unstructured.Unstructured{Object: content}
+	}
+	template, found, err := unstructured.NestedMap(u.Object, "spec", "template", "spec")
+	if !found || err != nil {
+		return cpu, memory
+	}
+	podSpec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, podSpec); err != nil {
+		return cpu, memory
+	}
+	for _, container := range podSpec.Containers {
+		cpu.Add(*container.Resources.Requests.Cpu())
+		memory.Add(*container.Resources.Requests.Memory())
+	}
+	return cpu, memory
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"github.com/kidle-dev/kidle/pkg/utils/pointer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("savings", func() {
+	var (
+		deployKey = types.NamespacedName{Name: "savings", Namespace: "default"}
+		idleTime  = time.Date(2021, 9, 20, 20, 0, 0, 0, time.UTC)
+		pricing   = &Pricing{CPUHour: 0.04, MemoryGBHour: 0.005}
+	)
+
+	newIdledInstance := func() *kidlev1beta1.IdlingResource {
+		deployment := newDeployment(deployKey, 3)
+		deployment.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("1G"),
+		}
+		instance := &kidlev1beta1.IdlingResource{}
+		instance.Status.LastIdleTime = &metav1.Time{Time: idleTime}
+		startSavings(instance, idler.NewDeploymentIdler(nil, ctrl.Log, deployment), pointer.Int32(3))
+		return instance
+	}
+
+	It("Should record the requests of the removed pods", func() {
+		instance := newIdledInstance()
+		Expect(instance.Status.Savings.IdledCPU.String()).To(Equal("1500m"))
+		Expect(instance.Status.Savings.IdledMemory.Value()).To(Equal(int64(3e9)))
+	})
+
+	It("Should add the current idling to the totals", func() {
+		instance := newIdledInstance()
+		totals := getSavingsTotals(instance, pricing, idleTime.Add(10*time.Hour))
+		Expect(totals.cpuCoreHours).To(BeNumerically("~", 15, 1e-9))
+		Expect(totals.memoryGBHours).To(BeNumerically("~", 30, 1e-9))
+		Expect(totals.cost).To(BeNumerically("~", 0.75, 1e-9))
+	})
+
+	It("Should accrue the savings on wakeup", func() {
+		instance := newIdledInstance()
+		instance.Status.Savings.CPUCoreHours = "5.000"
+		accrueSavings(instance, pricing, idleTime.Add(10*time.Hour))
+		Expect(instance.Status.Savings).To(Equal(&kidlev1beta1.Savings{
+			CPUCoreHours:  "20.000",
+			MemoryGBHours: "30.000",
+			Cost:          "0.75",
+		}))
+	})
+
+	It("Should not estimate the cost without prices", func() {
+		instance := newIdledInstance()
+		accrueSavings(instance, nil, idleTime.Add(time.Hour))
+		Expect(instance.Status.Savings.CPUCoreHours).To(Equal("1.500"))
+		Expect(instance.Status.Savings.Cost).To(BeEmpty())
+	})
+})
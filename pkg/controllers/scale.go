@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// unsupportedKindError is returned for a kind unknown to the API server or without the scale subresource
+type unsupportedKindError struct {
+	message string
+}
+
+func (e *unsupportedKindError) Error() string {
+	return e.message
+}
+
+// isUnsupportedKind returns true if the workload cannot be idled by a ScaleIdler
+func isUnsupportedKind(err error) bool {
+	_, ok := err.(*unsupportedKindError)
+	return ok
+}
+
+// getScaleIdler returns the ScaleIdler of a resource referenced by an IdlingResource.
+// The resource of the kind is resolved by the RESTMapper, its replicas are read from the scale subresource.
+func (r *IdlingResourceReconciler) getScaleIdler(ctx context.Context, log logr.Logger, namespace string, ref kidlev1beta1.CrossVersionObjectReference) (idler.Idler, error) {
+	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+	mapping, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, &unsupportedKindError{message: fmt.Sprintf("Kind %s is not supported", ref.Kind)}
+		}
+		return nil, fmt.Errorf("unable to resolve the resource of %s: %v", ref.Kind, err)
+	}
+
+	workload := &unstructured.Unstructured{}
+	workload.SetGroupVersionKind(gvk)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, workload); err != nil {
+		return nil, err
+	}
+
+	resource := mapping.Resource.GroupResource()
+	s, err := r.ScaleClient.Scales(namespace).Get(ctx, resource, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, &unsupportedKindError{message: fmt.Sprintf("Kind %s does not expose the scale subresource", ref.Kind)}
+		}
+		return nil, fmt.Errorf("unable to read the scale of %s %s: %v", ref.Kind, ref.Name, err)
+	}
+	return idler.NewScaleIdler(r.Client, r.ScaleClient, log, resource, workload, s), nil
+}
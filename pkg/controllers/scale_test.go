@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("scale subresource", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+	var (
+		ctx         = context.Background()
+		rolloutKind = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+	)
+
+	It("Should idle and wake up a custom resource through its scale subresource", func() {
+		irKey := types.NamespacedName{Name: "ir-rollout", Namespace: "default"}
+		rolloutKey := types.NamespacedName{Name: "rollout", Namespace: "default"}
+
+		rollout := &unstructured.Unstructured{}
+		rollout.SetGroupVersionKind(rolloutKind)
+		rollout.SetName(rolloutKey.Name)
+		rollout.SetNamespace(rolloutKey.Namespace)
+		Expect(unstructured.SetNestedField(rollout.Object, int64(3), "spec", "replicas")).Should(Succeed())
+		Expect(k8sClient.Create(ctx, rollout)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       rolloutKind.Kind,
+			Name:       rolloutKey.Name,
+			APIVersion: rolloutKind.GroupVersion().String(),
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		getReplicas := func() (int64, error) {
+			if err := k8sClient.Get(ctx, rolloutKey, rollout); err != nil {
+				return 0, err
+			}
+			replicas, _, err := unstructured.NestedInt64(rollout.Object, "spec", "replicas")
+			return replicas, err
+		}
+
+		By("Checking that the rollout is idled")
+		Eventually(getReplicas, timeout, interval).Should(Equal(int64(0)))
+		Expect(rollout.GetAnnotations()).Should(HaveKeyWithValue(kidlev1beta1.MetadataPreviousReplicas, "3"))
+		Expect(rollout.GetAnnotations()).Should(HaveKeyWithValue(kidlev1beta1.MetadataIdlingResourceReference, irKey.Name))
+
+		By("Checking that the rollout is waked up with its previous replicas")
+		Expect(setIdleFlag(ctx, irKey, false)).Should(Succeed())
+		Eventually(getReplicas, timeout, interval).Should(Equal(int64(3)))
+		Expect(k8sClient.Get(ctx, irKey, ir)).Should(Succeed())
+		Expect(meta.IsStatusConditionTrue(ir.Status.Conditions, kidlev1beta1.ConditionReferenceFound)).Should(BeTrue())
+	})
+
+	It("Should record the savings of a custom resource idled through its scale subresource", func() {
+		irKey := types.NamespacedName{Name: "ir-rollout-savings", Namespace: "default"}
+		rolloutKey := types.NamespacedName{Name: "rollout-savings", Namespace: "default"}
+
+		rollout := &unstructured.Unstructured{}
+		rollout.SetGroupVersionKind(rolloutKind)
+		rollout.SetName(rolloutKey.Name)
+		rollout.SetNamespace(rolloutKey.Namespace)
+		Expect(unstructured.SetNestedField(rollout.Object, int64(2), "spec", "replicas")).Should(Succeed())
+		Expect(unstructured.SetNestedSlice(rollout.Object, []interface{}{map[string]interface{}{
+			"name":      "app",
+			"image":     "app",
+			"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "250m", "memory": "128Mi"}},
+		}}, "spec", "template", "spec", "containers")).Should(Succeed())
+		Expect(k8sClient.Create(ctx, rollout)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       rolloutKind.Kind,
+			Name:       rolloutKey.Name,
+			APIVersion: rolloutKind.GroupVersion().String(),
+		})
+		ir.Spec.Idle = true
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		Eventually(func() (string, error) {
+			if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+				return "", err
+			}
+			if ir.Status.Savings == nil || ir.Status.Savings.IdledCPU == nil {
+				return "", nil
+			}
+			return ir.Status.Savings.IdledCPU.String(), nil
+		}, timeout, interval).Should(Equal("500m"))
+		Expect(ir.Status.Savings.IdledMemory.String()).Should(Equal("256Mi"))
+	})
+
+	It("Should report a kind without the scale subresource as unsupported", func() {
+		irKey := types.NamespacedName{Name: "ir-unscalable", Namespace: "default"}
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unscalable", Namespace: "default"}}
+		Expect(k8sClient.Create(ctx, cm)).Should(Succeed())
+
+		ir := newIdlingResource(irKey, &kidlev1beta1.CrossVersionObjectReference{
+			Kind:       "ConfigMap",
+			Name:       "unscalable",
+			APIVersion: "v1",
+		})
+		Expect(k8sClient.Create(ctx, ir)).Should(Succeed())
+
+		Eventually(func() (string, error) {
+			if err := k8sClient.Get(ctx, irKey, ir); err != nil {
+				return "", err
+			}
+			condition := meta.FindStatusCondition(ir.Status.Conditions, kidlev1beta1.ConditionReferenceFound)
+			if condition == nil {
+				return "", nil
+			}
+			return condition.Reason, nil
+		}, timeout, interval).Should(Equal(ReasonUnsupportedKind))
+	})
+})
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ReasonReconciled      = "Reconciled"
+	ReasonReconcileFailed = "ReconcileFailed"
+	ReasonFound           = "Found"
+	ReasonNotFound        = "NotFound"
+	ReasonUnsupportedKind = "UnsupportedKind"
+	ReasonIdled           = "Idled"
+	ReasonWakedUp         = "WakedUp"
+	ReasonIdling          = "Idling"
+	ReasonWakingUp        = "WakingUp"
+)
+
+// setCondition adds or updates a condition of the IdlingResource status
+func setCondition(instance *kidlev1beta1.IdlingResource, conditionType string, status metav1.ConditionStatus, reason string, message string) {
+	setStatusCondition(&instance.Status.Conditions, instance.Generation, conditionType, status, reason, message)
+}
+
+// setStatusCondition adds or updates a condition observed for the given generation
+func setStatusCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// setIdlingPhase updates the phase, the Idled and the Ready conditions after an idling or a wakeup.
+// A transitional phase (Idling, WakingUp) is not ready yet.
+func setIdlingPhase(instance *kidlev1beta1.IdlingResource, phase kidlev1beta1.IdlingResourcePhase) {
+	instance.Status.Phase = phase
+	setPhaseConditions(&instance.Status.Conditions, instance.Generation, phase, "The referenced workload is")
+}
+
+// setPhaseConditions sets the Idled and Ready conditions matching a phase.
+// The subject describes the workloads in the condition messages.
+func setPhaseConditions(conditions *[]metav1.Condition, generation int64, phase kidlev1beta1.IdlingResourcePhase, subject string) {
+	switch phase {
+	case kidlev1beta1.PhaseIdle, kidlev1beta1.PhaseIdling:
+		setStatusCondition(conditions, generation, kidlev1beta1.ConditionIdled, metav1.ConditionTrue, ReasonIdled, fmt.Sprintf("%s idled", subject))
+	case kidlev1beta1.PhaseActive, kidlev1beta1.PhaseWakingUp:
+		setStatusCondition(conditions, generation, kidlev1beta1.ConditionIdled, metav1.ConditionFalse, ReasonWakedUp, fmt.Sprintf("%s waked up", subject))
+	}
+
+	switch phase {
+	case kidlev1beta1.PhaseIdle, kidlev1beta1.PhaseActive:
+		setStatusCondition(conditions, generation, kidlev1beta1.ConditionReady, metav1.ConditionTrue, ReasonReconciled, fmt.Sprintf("%s %s", subject, phase))
+	case kidlev1beta1.PhaseIdling:
+		setStatusCondition(conditions, generation, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonIdling, fmt.Sprintf("%s being idled", subject))
+	case kidlev1beta1.PhaseWakingUp:
+		setStatusCondition(conditions, generation, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonWakingUp, fmt.Sprintf("%s being waked up", subject))
+	}
+}
+
+// setReferenceNotFound flags the IdlingResource as unable to find its referenced workload
+func setReferenceNotFound(instance *kidlev1beta1.IdlingResource, reason string, message string) {
+	instance.Status.Phase = kidlev1beta1.PhaseError
+	setCondition(instance, kidlev1beta1.ConditionReferenceFound, metav1.ConditionFalse, reason, message)
+	setCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, reason, message)
+}
+
+// updateStatus patches the IdlingResource status if it has changed since the beginning of the reconciliation.
+// The reconciliation error, if any, is reported in the status.
+func (r *IdlingResourceReconciler) updateStatus(ctx context.Context, original *kidlev1beta1.IdlingResource, instance *kidlev1beta1.IdlingResource, reconcileErr error) error {
+	instance.Status.ObservedGeneration = instance.Generation
+	if reconcileErr != nil {
+		instance.Status.Phase = kidlev1beta1.PhaseError
+		setCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonReconcileFailed, reconcileErr.Error())
+	}
+
+	if equality.Semantic.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	// the idling resource may have been deleted once its finalizer has been removed
+	if err := r.Status().Patch(ctx, instance, client.MergeFrom(original)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("unable to update idlingresource status: %v", err)
+	}
+	return nil
+}
+
+// setGroupCondition adds or updates a condition of the IdlingGroup status
+func setGroupCondition(instance *kidlev1beta1.IdlingGroup, conditionType string, status metav1.ConditionStatus, reason string, message string) {
+	setStatusCondition(&instance.Status.Conditions, instance.Generation, conditionType, status, reason, message)
+}
+
+// setGroupIdlingPhase updates the phase, the Idled and the Ready conditions of an IdlingGroup
+func setGroupIdlingPhase(instance *kidlev1beta1.IdlingGroup, phase kidlev1beta1.IdlingResourcePhase) {
+	instance.Status.Phase = phase
+	setPhaseConditions(&instance.Status.Conditions, instance.Generation, phase, "The workloads are")
+}
+
+// updateStatus patches the IdlingGroup status if it has changed since the beginning of the reconciliation.
+// The reconciliation error, if any, is reported in the status.
+func (r *IdlingGroupReconciler) updateStatus(ctx context.Context, original *kidlev1beta1.IdlingGroup, instance *kidlev1beta1.IdlingGroup, reconcileErr error) error {
+	instance.Status.ObservedGeneration = instance.Generation
+	if reconcileErr != nil {
+		instance.Status.Phase = kidlev1beta1.PhaseError
+		setGroupCondition(instance, kidlev1beta1.ConditionReady, metav1.ConditionFalse, ReasonReconcileFailed, reconcileErr.Error())
+	}
+
+	if equality.Semantic.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	// the idling group may have been deleted once its finalizer has been removed
+	if err := r.Status().Patch(ctx, instance, client.MergeFrom(original)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("unable to update idlinggroup status: %v", err)
+	}
+	return nil
+}
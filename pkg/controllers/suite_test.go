@@ -18,7 +18,10 @@ package controllers
 
 import (
 	"context"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/util/retry"
 	"path/filepath"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,6 +37,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"github.com/kidle-dev/kidle/pkg/utils/k8s"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -44,9 +49,13 @@ const (
 	DefaultKidlectlImage = "kidledev/kidlectl:main"
 )
 
+var activatorService = types.NamespacedName{Namespace: "default", Name: "kidle-activator"}
+
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
+var cronJobVersion schema.GroupVersion
+var cronJobTimeZone bool
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -59,7 +68,7 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("../..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{filepath.Join("../..", "config", "crd", "bases"), filepath.Join("testdata", "crds")},
 	}
 
 	var err error
@@ -77,12 +86,53 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	Expect(err).ToNot(HaveOccurred())
+	cronJobVersion, err = k8s.CronJobGroupVersion(discoveryClient)
+	Expect(err).ToNot(HaveOccurred())
+	cronJobTimeZone, err = k8s.CronJobTimeZoneSupported(discoveryClient)
+	Expect(err).ToNot(HaveOccurred())
+	idler.MustRegister(idler.CronJobRegistration(cronJobVersion))
+	idler.AutoscalerGroupVersion, err = k8s.HorizontalPodAutoscalerGroupVersion(discoveryClient)
+	Expect(err).ToNot(HaveOccurred())
+	// The ScaledObject and VirtualMachine CRDs of the testdata are installed
+	idler.MustRegister(idler.ScaledObjectRegistration())
+	idler.MustRegister(idler.VirtualMachineRegistration())
+
+	scaleClient, err := idler.NewScalesGetter(cfg, k8sManager.GetRESTMapper())
+	Expect(err).ToNot(HaveOccurred())
+
 	err = (&IdlingResourceReconciler{
-		Client:        k8sManager.GetClient(),
-		Scheme:        k8sManager.GetScheme(),
-		Log:           ctrl.Log.WithName("controllers").WithName("IdlingResource"),
-		EventRecorder: k8sManager.GetEventRecorderFor("secretscope-controller"),
-		KidlectlImage: DefaultKidlectlImage,
+		Client:           k8sManager.GetClient(),
+		Scheme:           k8sManager.GetScheme(),
+		Log:              ctrl.Log.WithName("controllers").WithName("IdlingResource"),
+		EventRecorder:    k8sManager.GetEventRecorderFor("secretscope-controller"),
+		KidlectlImage:    DefaultKidlectlImage,
+		ActivatorService: activatorService,
+		ScaleClient:      scaleClient,
+		CronJobVersion:   cronJobVersion,
+		CronJobTimeZone:  cronJobTimeZone,
+		APIReader:        k8sManager.GetAPIReader(),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&IdlingGroupReconciler{
+		Client:          k8sManager.GetClient(),
+		Scheme:          k8sManager.GetScheme(),
+		Log:             ctrl.Log.WithName("controllers").WithName("IdlingGroup"),
+		EventRecorder:   k8sManager.GetEventRecorderFor("idlinggroup-controller"),
+		KidlectlImage:   DefaultKidlectlImage,
+		CronJobVersion:  cronJobVersion,
+		CronJobTimeZone: cronJobTimeZone,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&ClusterIdlingPolicyReconciler{
+		Client:            k8sManager.GetClient(),
+		Scheme:            k8sManager.GetScheme(),
+		Log:               ctrl.Log.WithName("controllers").WithName("ClusterIdlingPolicy"),
+		EventRecorder:     k8sManager.GetEventRecorderFor("clusteridlingpolicy-controller"),
+		OperatorNamespace: "kidle-system",
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -112,3 +162,19 @@ func setIdleFlag(ctx context.Context, irKey types.NamespacedName, idle bool) err
 		return k8sClient.Update(ctx, ir)
 	})
 }
+
+// setDeploymentReady reports all the replicas of a Deployment as ready, there is no Deployment controller in envtest
+func setDeploymentReady(ctx context.Context, deployKey types.NamespacedName) error {
+	d := &appsv1.Deployment{}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := k8sClient.Get(ctx, deployKey, d); err != nil {
+			return err
+		}
+		d.Status.ObservedGeneration = d.Generation
+		d.Status.Replicas = *d.Spec.Replicas
+		d.Status.UpdatedReplicas = *d.Spec.Replicas
+		d.Status.ReadyReplicas = *d.Spec.Replicas
+		d.Status.AvailableReplicas = *d.Spec.Replicas
+		return k8sClient.Status().Update(ctx, d)
+	})
+}
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+)
+
+// DefaultTimeout is the maximum duration of the delivery of a notification
+const DefaultTimeout = 5 * time.Second
+
+// Notification describes a transition of a workload, it is the payload of the generic webhooks
+type Notification struct {
+	Event          kidlev1beta1.NotificationEvent `json:"event"`
+	Namespace      string                         `json:"namespace"`
+	IdlingResource string                         `json:"idlingResource"`
+	Kind           string                         `json:"kind"`
+	Name           string                         `json:"name"`
+	Message        string                         `json:"message"`
+	Time           time.Time                      `json:"time"`
+}
+
+// Title returns a one line summary of the notification
+func (n *Notification) Title() string {
+	return fmt.Sprintf("%s %s/%s %s", n.Kind, n.Namespace, n.Name, n.Event)
+}
+
+// failed returns true if the notification reports a failure
+func (n *Notification) failed() bool {
+	return n.Event == kidlev1beta1.NotificationIdleFailed || n.Event == kidlev1beta1.NotificationWakeupFailed
+}
+
+// slackMessage is the payload of a Slack incoming webhook
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// teamsMessage is the payload of a Microsoft Teams incoming webhook, a legacy actionable message card
+type teamsMessage struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+// Payload encodes a notification in the format of a sink
+func Payload(sinkType kidlev1beta1.NotificationSinkType, n *Notification) ([]byte, error) {
+	switch sinkType {
+	case kidlev1beta1.NotificationSinkWebhook:
+		return json.Marshal(n)
+	case kidlev1beta1.NotificationSinkSlack:
+		icon := ":zzz:"
+		if n.Event == kidlev1beta1.NotificationWakedUp {
+			icon = ":sunny:"
+		} else if n.failed() {
+			icon = ":warning:"
+		}
+		return json.Marshal(slackMessage{Text: fmt.Sprintf("%s *%s*\n%s", icon, n.Title(), n.Message)})
+	case kidlev1beta1.NotificationSinkTeams:
+		color := "0076D7"
+		if n.failed() {
+			color = "D70000"
+		}
+		return json.Marshal(teamsMessage{
+			Type:       "MessageCard",
+			Context:    "https://schema.org/extensions",
+			Summary:    n.Title(),
+			ThemeColor: color,
+			Title:      n.Title(),
+			Text:       n.Message,
+		})
+	}
+	return nil, fmt.Errorf("unsupported sink type %q", sinkType)
+}
+
+// Sender posts the notifications to the sinks
+type Sender struct {
+	Client *http.Client
+}
+
+// NewSender creates a Sender whose deliveries time out after the DefaultTimeout
+func NewSender() *Sender {
+	return &Sender{Client: &http.Client{Timeout: DefaultTimeout}}
+}
+
+// Send posts a notification to a sink, the authorization header is only set if not empty
+func (s *Sender) Send(ctx context.Context, sinkType kidlev1beta1.NotificationSinkType, url string, authorization string, n *Notification) error {
+	payload, err := Payload(sinkType, n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid notification request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send notification: %v", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification rejected with status %s", resp.Status)
+	}
+	return nil
+}
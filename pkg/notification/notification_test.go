@@ -0,0 +1,77 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/notification"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notification", func() {
+	n := &notification.Notification{
+		Event:          kidlev1beta1.NotificationIdled,
+		Namespace:      "kidle-demo",
+		IdlingResource: "podinfo",
+		Kind:           "Deployment",
+		Name:           "podinfo",
+		Message:        "Scaled to 0",
+		Time:           time.Date(2021, 9, 20, 20, 0, 0, 0, time.UTC),
+	}
+
+	DescribeTable("encodes the payloads",
+		func(sinkType kidlev1beta1.NotificationSinkType, expected map[string]interface{}) {
+			payload, err := notification.Payload(sinkType, n)
+			Expect(err).ToNot(HaveOccurred())
+			var decoded map[string]interface{}
+			Expect(json.Unmarshal(payload, &decoded)).To(Succeed())
+			for key, value := range expected {
+				Expect(decoded).To(HaveKeyWithValue(key, value))
+			}
+		},
+		Entry("generic webhook", kidlev1beta1.NotificationSinkWebhook, map[string]interface{}{
+			"event": "Idled", "namespace": "kidle-demo", "idlingResource": "podinfo", "kind": "Deployment", "time": "2021-09-20T20:00:00Z",
+		}),
+		Entry("Slack", kidlev1beta1.NotificationSinkSlack, map[string]interface{}{
+			"text": ":zzz: *Deployment kidle-demo/podinfo Idled*\nScaled to 0",
+		}),
+		Entry("Teams", kidlev1beta1.NotificationSinkTeams, map[string]interface{}{
+			"@type": "MessageCard", "title": "Deployment kidle-demo/podinfo Idled", "text": "Scaled to 0",
+		}),
+	)
+
+	It("rejects an unknown sink type", func() {
+		_, err := notification.Payload("Discord", n)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("posts the notification with the authorization header", func() {
+		var authorization, contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization, contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+			_, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		Expect(notification.NewSender().Send(context.Background(), kidlev1beta1.NotificationSinkWebhook, server.URL, "Bearer secret", n)).To(Succeed())
+		Expect(authorization).To(Equal("Bearer secret"))
+		Expect(contentType).To(Equal("application/json"))
+	})
+
+	It("reports a rejected notification", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := notification.NewSender().Send(context.Background(), kidlev1beta1.NotificationSinkSlack, server.URL, "", n)
+		Expect(err).To(MatchError(ContainSubstring("403")))
+	})
+})
@@ -0,0 +1,52 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// Querier queries a Prometheus server for a single value
+type Querier interface {
+	Query(ctx context.Context, query string) (float64, error)
+}
+
+// Client is a Querier backed by the Prometheus HTTP API
+type Client struct {
+	API promv1.API
+}
+
+// NewClient creates a Prometheus client for the server at the given address
+func NewClient(address string) (*Client, error) {
+	c, err := promapi.NewClient(promapi.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create prometheus client: %v", err)
+	}
+	return &Client{API: promv1.NewAPI(c)}, nil
+}
+
+// Query evaluates an instant query and returns its value.
+// The samples of a vector are summed up and an empty vector is evaluated as 0.
+func (c *Client) Query(ctx context.Context, query string) (float64, error) {
+	value, _, err := c.API.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("unable to query prometheus: %v", err)
+	}
+
+	switch v := value.(type) {
+	case *model.Scalar:
+		return float64(v.Value), nil
+	case model.Vector:
+		var sum float64
+		for _, sample := range v {
+			sum += float64(sample.Value)
+		}
+		return sum, nil
+	default:
+		return 0, fmt.Errorf("unsupported result type %s, expected a scalar or a vector", value.Type())
+	}
+}
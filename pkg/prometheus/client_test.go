@@ -0,0 +1,66 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// newFakePrometheus starts a fake Prometheus server answering every query with the given data
+func newFakePrometheus(data string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":%s}`, data)
+	}))
+}
+
+var _ = Describe("Query", func() {
+	DescribeTable("evaluates the query result",
+		func(data string, expected float64) {
+			server := newFakePrometheus(data)
+			defer server.Close()
+
+			c, err := NewClient(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+
+			value, err := c.Query(context.Background(), "sum(rate(http_requests_total[5m]))")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(expected))
+		},
+		Entry("scalar", `{"resultType":"scalar","result":[1632139200,"4.5"]}`, 4.5),
+		Entry("single sample vector", `{"resultType":"vector","result":[{"metric":{},"value":[1632139200,"2"]}]}`, 2.0),
+		Entry("multiple samples vector", `{"resultType":"vector","result":[{"metric":{"pod":"a"},"value":[1632139200,"1"]},{"metric":{"pod":"b"},"value":[1632139200,"0.5"]}]}`, 1.5),
+		Entry("empty vector", `{"resultType":"vector","result":[]}`, 0.0),
+	)
+
+	It("fails on matrix results", func() {
+		server := newFakePrometheus(`{"resultType":"matrix","result":[]}`)
+		defer server.Close()
+
+		c, err := NewClient(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = c.Query(context.Background(), "http_requests_total[5m]")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails when the server is unavailable", func() {
+		server := newFakePrometheus("")
+		server.Close()
+
+		c, err := NewClient(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = c.Query(context.Background(), "up")
+		Expect(err).To(HaveOccurred())
+	})
+})
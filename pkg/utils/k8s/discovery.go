@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// CronJobGroupVersion returns the API version of the CronJobs served by the cluster.
+// batch/v1 is preferred, batch/v1beta1 is returned for the clusters older than Kubernetes 1.21.
+func CronJobGroupVersion(d discovery.DiscoveryInterface) (schema.GroupVersion, error) {
+	served, err := IsServed(d, batchv1.SchemeGroupVersion.WithResource("cronjobs"))
+	if err != nil {
+		return schema.GroupVersion{}, err
+	}
+	if served {
+		return batchv1.SchemeGroupVersion, nil
+	}
+	return batchv1beta1.SchemeGroupVersion, nil
+}
+
+// HorizontalPodAutoscalerGroupVersion returns the API version of the HorizontalPodAutoscalers served by the cluster.
+// autoscaling/v2 is preferred, autoscaling/v2beta2 is returned for the clusters older than Kubernetes 1.23.
+func HorizontalPodAutoscalerGroupVersion(d discovery.DiscoveryInterface) (schema.GroupVersion, error) {
+	v2 := schema.GroupVersion{Group: autoscalingv2beta2.GroupName, Version: "v2"}
+	served, err := IsServed(d, v2.WithResource("horizontalpodautoscalers"))
+	if err != nil {
+		return schema.GroupVersion{}, err
+	}
+	if served {
+		return v2, nil
+	}
+	return autoscalingv2beta2.SchemeGroupVersion, nil
+}
+
+// IsServed returns true if the cluster serves a resource, e.g. the resource of an optional CRD
+func IsServed(d discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (bool, error) {
+	resources, err := d.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CronJobTimeZoneSupported returns true if the cluster supports the timeZone field of the batch/v1 CronJobs,
+// enabled by default from Kubernetes 1.25. The older clusters only understand a CRON_TZ prefix of the schedule.
+func CronJobTimeZoneSupported(d discovery.DiscoveryInterface) (bool, error) {
+	version, err := d.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+	// The managed clusters report a minor version suffixed by a +, e.g. 25+
+	major, err := strconv.Atoi(strings.TrimSuffix(version.Major, "+"))
+	if err != nil {
+		return false, fmt.Errorf("invalid server major version %q: %v", version.Major, err)
+	}
+	minor, err := strconv.Atoi(strings.TrimSuffix(version.Minor, "+"))
+	if err != nil {
+		return false, fmt.Errorf("invalid server minor version %q: %v", version.Minor, err)
+	}
+	return major > 1 || (major == 1 && minor >= 25), nil
+}
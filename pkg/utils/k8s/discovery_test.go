@@ -0,0 +1,97 @@
+package k8s
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("CronJobGroupVersion", func() {
+	discoveryWith := func(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+		return &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}}
+	}
+
+	It("prefers batch/v1", func() {
+		d := discoveryWith(
+			&metav1.APIResourceList{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs"}, {Name: "cronjobs"}}},
+			&metav1.APIResourceList{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{{Name: "cronjobs"}}},
+		)
+		Expect(CronJobGroupVersion(d)).To(Equal(batchv1.SchemeGroupVersion))
+	})
+
+	It("falls back to batch/v1beta1 before Kubernetes 1.21", func() {
+		d := discoveryWith(
+			&metav1.APIResourceList{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs"}}},
+			&metav1.APIResourceList{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{{Name: "cronjobs"}}},
+		)
+		Expect(CronJobGroupVersion(d)).To(Equal(batchv1beta1.SchemeGroupVersion))
+	})
+})
+
+var _ = Describe("HorizontalPodAutoscalerGroupVersion", func() {
+	discoveryWith := func(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+		return &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}}
+	}
+
+	It("prefers autoscaling/v2", func() {
+		d := discoveryWith(
+			&metav1.APIResourceList{GroupVersion: "autoscaling/v2", APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers"}}},
+			&metav1.APIResourceList{GroupVersion: "autoscaling/v2beta2", APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers"}}},
+		)
+		Expect(HorizontalPodAutoscalerGroupVersion(d)).To(Equal(schema.GroupVersion{Group: "autoscaling", Version: "v2"}))
+	})
+
+	It("falls back to autoscaling/v2beta2 before Kubernetes 1.23", func() {
+		// The fake discovery does not return a NotFound error for an unknown group version
+		d := discoveryWith(
+			&metav1.APIResourceList{GroupVersion: "autoscaling/v2"},
+			&metav1.APIResourceList{GroupVersion: "autoscaling/v2beta2", APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers"}}},
+		)
+		Expect(HorizontalPodAutoscalerGroupVersion(d)).To(Equal(autoscalingv2beta2.SchemeGroupVersion))
+	})
+})
+
+var _ = Describe("IsServed", func() {
+	d := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "keda.sh/v1alpha1", APIResources: []metav1.APIResource{{Name: "scaledobjects"}}},
+	}}}
+
+	It("finds a served resource", func() {
+		Expect(IsServed(d, schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"})).To(BeTrue())
+	})
+
+	It("does not find an unknown resource", func() {
+		Expect(IsServed(d, schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledjobs"})).To(BeFalse())
+	})
+})
+
+var _ = Describe("CronJobTimeZoneSupported", func() {
+	discoveryWith := func(major string, minor string) *fakediscovery.FakeDiscovery {
+		return &fakediscovery.FakeDiscovery{
+			Fake:               &k8stesting.Fake{},
+			FakedServerVersion: &version.Info{Major: major, Minor: minor},
+		}
+	}
+
+	It("supports the timeZone field from Kubernetes 1.25", func() {
+		Expect(CronJobTimeZoneSupported(discoveryWith("1", "25"))).To(BeTrue())
+		Expect(CronJobTimeZoneSupported(discoveryWith("1", "29+"))).To(BeTrue())
+	})
+
+	It("does not support the timeZone field before Kubernetes 1.25", func() {
+		Expect(CronJobTimeZoneSupported(discoveryWith("1", "24"))).To(BeFalse())
+		Expect(CronJobTimeZoneSupported(discoveryWith("1", "21+"))).To(BeFalse())
+	})
+
+	It("fails with an invalid version", func() {
+		_, err := CronJobTimeZoneSupported(discoveryWith("1", "latest"))
+		Expect(err).To(HaveOccurred())
+	})
+})
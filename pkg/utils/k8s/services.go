@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FindServicePort returns the service port matching a port name or number.
+// The first port is returned if port is empty.
+func FindServicePort(svc *corev1.Service, port string) (*corev1.ServicePort, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("service %s has no port", svc.Name)
+	}
+	if port == "" {
+		return &svc.Spec.Ports[0], nil
+	}
+	for i, p := range svc.Spec.Ports {
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			return &svc.Spec.Ports[i], nil
+		}
+	}
+	return nil, fmt.Errorf("service %s has no port %s", svc.Name, port)
+}
+
+// IsPodReady returns true if the pod is running and ready
+func IsPodReady(pod *corev1.Pod) bool {
+	if !pod.DeletionTimestamp.IsZero() {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
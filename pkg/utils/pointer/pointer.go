@@ -14,3 +14,8 @@ func Int64(i int64) *int64 {
 func Bool(b bool) *bool {
 	return &b
 }
+
+// String returns a pointer to a string
+func String(s string) *string {
+	return &s
+}
@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// timeZonePrefixes are the prefixes setting the time zone of a schedule, which is set by the timeZone field instead
+var timeZonePrefixes = []string{"TZ=", "CRON_TZ="}
+
+// Parse parses a cron schedule evaluated in the given IANA time zone.
+// The schedule is evaluated in UTC if the time zone is empty.
+func Parse(schedule string, timeZone string) (cron.Schedule, error) {
+	for _, prefix := range timeZonePrefixes {
+		if strings.HasPrefix(schedule, prefix) {
+			return nil, fmt.Errorf("invalid schedule %q: use the timeZone field to set the time zone", schedule)
+		}
+	}
+	if _, err := LoadLocation(timeZone); err != nil {
+		return nil, err
+	}
+	s, err := cron.ParseStandard(CronJobSchedule(schedule, timeZone))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %v", schedule, err)
+	}
+	return s, nil
+}
+
+// LoadLocation returns the location of an IANA time zone, UTC if the time zone is empty
+func LoadLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %v", timeZone, err)
+	}
+	return location, nil
+}
+
+// CronJobSchedule returns the schedule of a CronJob evaluated in the given time zone.
+// The CRON_TZ prefix is not supported by Kubernetes and rejected from 1.29: it is only used for the clusters
+// older than 1.25, without the timeZone field of the CronJobs.
+func CronJobSchedule(schedule string, timeZone string) string {
+	if timeZone == "" {
+		return schedule
+	}
+	return fmt.Sprintf("CRON_TZ=%s %s", timeZone, schedule)
+}
+
+// Next returns the next activation time of a schedule after the given time.
+// The daylight saving time transitions of the time zone are taken into account.
+func Next(schedule string, timeZone string, from time.Time) (time.Time, error) {
+	s, err := Parse(schedule, timeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.Next(from), nil
+}
+
+// Prev returns the last activation time of a schedule before the given time, searched within the given period.
+// It returns the zero time if the schedule is not activated during the period.
+func Prev(schedule string, timeZone string, before time.Time, period time.Duration) (time.Time, error) {
+	s, err := Parse(schedule, timeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var prev time.Time
+	for t := s.Next(before.Add(-period)); !t.IsZero() && !t.After(before); t = s.Next(t) {
+		prev = t
+	}
+	return prev, nil
+}
@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func utc(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	Expect(err).ToNot(HaveOccurred())
+	return t.UTC()
+}
+
+var _ = Describe("Parse", func() {
+	DescribeTable("validates the schedule and the time zone",
+		func(schedule string, timeZone string, valid bool) {
+			_, err := Parse(schedule, timeZone)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("without time zone", "0 20 * * 1-5", "", true),
+		Entry("with an IANA time zone", "0 20 * * 1-5", "Europe/Paris", true),
+		Entry("with UTC", "@daily", "UTC", true),
+		Entry("with an unknown time zone", "0 20 * * 1-5", "Europe/Lyon", false),
+		Entry("with an abbreviation", "0 20 * * 1-5", "CEST", false),
+		Entry("with an invalid schedule", "0 25 * * *", "Europe/Paris", false),
+		Entry("with a CRON_TZ prefix", "CRON_TZ=Europe/Paris 0 20 * * 1-5", "", false),
+		Entry("with a TZ prefix", "TZ=Europe/Paris 0 20 * * 1-5", "", false),
+	)
+})
+
+var _ = Describe("CronJobSchedule", func() {
+	It("prefixes the schedule with the time zone", func() {
+		Expect(CronJobSchedule("0 20 * * 1-5", "America/New_York")).To(Equal("CRON_TZ=America/New_York 0 20 * * 1-5"))
+	})
+	It("keeps the schedule without time zone", func() {
+		Expect(CronJobSchedule("0 20 * * 1-5", "")).To(Equal("0 20 * * 1-5"))
+	})
+})
+
+var _ = Describe("Next", func() {
+	DescribeTable("handles the daylight saving time",
+		func(schedule string, timeZone string, from string, expected string) {
+			next, err := Next(schedule, timeZone, utc(from))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next.UTC()).To(Equal(utc(expected)))
+		},
+		Entry("in UTC", "0 20 * * *", "", "2021-03-27T12:00:00Z", "2021-03-27T20:00:00Z"),
+		Entry("in Paris before the DST", "0 20 * * *", "Europe/Paris", "2021-03-27T12:00:00Z", "2021-03-27T19:00:00Z"),
+		Entry("in Paris after the DST", "0 20 * * *", "Europe/Paris", "2021-03-28T12:00:00Z", "2021-03-28T18:00:00Z"),
+		Entry("in Paris back to winter time", "30 7 * * 1-5", "Europe/Paris", "2021-10-30T12:00:00Z", "2021-11-01T06:30:00Z"),
+		Entry("in New York before the DST", "30 7 * * 1-5", "America/New_York", "2021-03-12T00:00:00Z", "2021-03-12T12:30:00Z"),
+		Entry("in New York after the DST", "30 7 * * 1-5", "America/New_York", "2021-03-13T00:00:00Z", "2021-03-15T11:30:00Z"),
+	)
+})
+
+var _ = Describe("Prev", func() {
+	DescribeTable("returns the last activation within the period",
+		func(schedule string, timeZone string, before string, expected string) {
+			prev, err := Prev(schedule, timeZone, utc(before), 8*24*time.Hour)
+			Expect(err).ToNot(HaveOccurred())
+			if expected == "" {
+				Expect(prev.IsZero()).To(BeTrue())
+			} else {
+				Expect(prev.UTC()).To(Equal(utc(expected)))
+			}
+		},
+		Entry("on the same day", "0 20 * * *", "", "2021-10-04T22:00:00Z", "2021-10-04T20:00:00Z"),
+		Entry("on the activation time", "0 20 * * *", "", "2021-10-04T20:00:00Z", "2021-10-04T20:00:00Z"),
+		Entry("during the weekend", "0 20 * * 1-5", "Europe/Paris", "2021-10-10T12:00:00Z", "2021-10-08T18:00:00Z"),
+		Entry("out of the period", "0 0 1 1 *", "", "2021-10-04T00:00:00Z", ""),
+	)
+})
@@ -0,0 +1,193 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	"github.com/kidle-dev/kidle/pkg/controllers/idler"
+	"github.com/kidle-dev/kidle/pkg/utils/array"
+	"github.com/kidle-dev/kidle/pkg/utils/schedule"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ValidateIdlingResourcePath is the path of the IdlingResource validating webhook
+const ValidateIdlingResourcePath = "/validate-kidle-kidle-dev-v1beta1-idlingresource"
+
+// BuiltinGroups are the API groups whose kinds must be idled by a registered idler.
+// The kinds of the other groups are custom resources idled through their scale subresource.
+var BuiltinGroups = []string{"", "apps", "batch"}
+
+// SupportedKinds returns the kinds of workloads idled by the registered idlers
+func SupportedKinds() []string {
+	var kinds []string
+	for _, registration := range idler.DefaultRegistry.Registrations() {
+		if !array.ContainsString(kinds, registration.GroupVersionKind.Kind) {
+			kinds = append(kinds, registration.GroupVersionKind.Kind)
+		}
+	}
+	return kinds
+}
+
+// +kubebuilder:webhook:path=/validate-kidle-kidle-dev-v1beta1-idlingresource,mutating=false,failurePolicy=fail,sideEffects=None,groups=kidle.kidle.dev,resources=idlingresources,verbs=create;update,versions=v1beta1,name=vidlingresource.kidle.dev,admissionReviewVersions=v1
+
+// IdlingResourceValidator rejects the invalid IdlingResources
+type IdlingResourceValidator struct {
+	Client  client.Reader
+	decoder *admission.Decoder
+}
+
+// Handle validates the IdlingResource of an admission request
+func (v *IdlingResourceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ir := &kidlev1beta1.IdlingResource{}
+	if err := v.decoder.Decode(req, ir); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// The deletion of an IdlingResource updates its finalizers, it must not be prevented
+	if !ir.DeletionTimestamp.IsZero() {
+		return admission.Allowed("")
+	}
+
+	errs, err := v.Validate(ctx, ir)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(errs) > 0 {
+		status := apierrors.NewInvalid(kidlev1beta1.GroupVersion.WithKind("IdlingResource").GroupKind(), ir.Name, errs).ErrStatus
+		return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result:  &status,
+		}}
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder of the admission requests
+func (v *IdlingResourceValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Validate returns the validation errors of an IdlingResource
+func (v *IdlingResourceValidator) Validate(ctx context.Context, ir *kidlev1beta1.IdlingResource) (field.ErrorList, error) {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	refPath := spec.Child("idlingResourceRef")
+	ref := ir.Spec.IdlingResourceRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		errs = append(errs, field.Invalid(refPath.Child("apiVersion"), ref.APIVersion, err.Error()))
+	}
+	if ref.Kind == "" {
+		errs = append(errs, field.Required(refPath.Child("kind"), "the kind of the workload is required"))
+	} else if err == nil && array.ContainsString(BuiltinGroups, gv.Group) && idler.DefaultRegistry.Lookup(gv.WithKind(ref.Kind)) == nil {
+		errs = append(errs, field.NotSupported(refPath.Child("kind"), ref.Kind, SupportedKinds()))
+	}
+	if ref.Name == "" {
+		errs = append(errs, field.Required(refPath.Child("name"), "the name of the workload is required"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(ref.Name) {
+			errs = append(errs, field.Invalid(refPath.Child("name"), ref.Name, msg))
+		}
+	}
+
+	if s := ir.Spec.IdlingStrategy; s != nil && s.CronStrategy != nil {
+		errs = append(errs, validateCronStrategy(spec.Child("idlingStrategy", "cronStrategy"), s.CronStrategy)...)
+	}
+	if s := ir.Spec.WakeupStrategy; s != nil && s.CronStrategy != nil {
+		errs = append(errs, validateCronStrategy(spec.Child("wakeupStrategy", "cronStrategy"), s.CronStrategy)...)
+	}
+
+	irs := &kidlev1beta1.IdlingResourceList{}
+	if err := v.Client.List(ctx, irs, client.InNamespace(ir.Namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list idling resources: %v", err)
+	}
+
+	// A workload is referenced by a single IdlingResource
+	if ref.Name != "" {
+		for _, other := range irs.Items {
+			if other.Name == ir.Name || !other.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if other.Spec.IdlingResourceRef.Kind == ref.Kind && other.Spec.IdlingResourceRef.Name == ref.Name {
+				errs = append(errs, field.Duplicate(refPath, fmt.Sprintf("%s %s is already referenced by the IdlingResource %s", ref.Kind, ref.Name, other.Name)))
+			}
+		}
+	}
+
+	errs = append(errs, validateDependencies(spec.Child("dependsOn"), ir, irs.Items)...)
+	return errs, nil
+}
+
+// validateDependencies rejects the invalid, duplicated and circular dependencies of an IdlingResource
+func validateDependencies(path *field.Path, ir *kidlev1beta1.IdlingResource, irs []kidlev1beta1.IdlingResource) field.ErrorList {
+	var errs field.ErrorList
+
+	graph := map[string][]string{ir.Name: ir.Spec.DependsOn}
+	for _, other := range irs {
+		if other.Name != ir.Name {
+			graph[other.Name] = other.Spec.DependsOn
+		}
+	}
+
+	var seen []string
+	for i, name := range ir.Spec.DependsOn {
+		namePath := path.Index(i)
+		switch {
+		case len(validation.IsDNS1123Subdomain(name)) > 0:
+			for _, msg := range validation.IsDNS1123Subdomain(name) {
+				errs = append(errs, field.Invalid(namePath, name, msg))
+			}
+		case name == ir.Name:
+			errs = append(errs, field.Invalid(namePath, name, "an IdlingResource cannot depend on itself"))
+		case array.ContainsString(seen, name):
+			errs = append(errs, field.Duplicate(namePath, name))
+		default:
+			if cycle := dependencyCycle(graph, ir.Name, name, []string{ir.Name}); cycle != nil {
+				errs = append(errs, field.Invalid(namePath, name, fmt.Sprintf("circular dependency: %s", strings.Join(cycle, " -> "))))
+			}
+		}
+		seen = append(seen, name)
+	}
+	return errs
+}
+
+// dependencyCycle returns the path from the dependency back to the root IdlingResource, or nil without cycle
+func dependencyCycle(graph map[string][]string, root string, dependency string, path []string) []string {
+	path = append(path, dependency)
+	if dependency == root {
+		return path
+	}
+	if array.ContainsString(path[:len(path)-1], dependency) {
+		return nil
+	}
+	for _, next := range graph[dependency] {
+		if cycle := dependencyCycle(graph, root, next, path); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// validateCronStrategy validates the schedule and the time zone of a cron strategy
+func validateCronStrategy(path *field.Path, strategy *kidlev1beta1.CronStrategy) field.ErrorList {
+	var errs field.ErrorList
+	if _, err := schedule.LoadLocation(strategy.TimeZone); err != nil {
+		errs = append(errs, field.Invalid(path.Child("timeZone"), strategy.TimeZone, err.Error()))
+		return errs
+	}
+	if _, err := schedule.Parse(strategy.Schedule, strategy.TimeZone); err != nil {
+		errs = append(errs, field.Invalid(path.Child("schedule"), strategy.Schedule, err.Error()))
+	}
+	return errs
+}
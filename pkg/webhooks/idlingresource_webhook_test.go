@@ -0,0 +1,153 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+
+	kidlev1beta1 "github.com/kidle-dev/kidle/pkg/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// newIdlingResource returns a valid IdlingResource referencing a deployment
+func newIdlingResource(name string, workload string) *kidlev1beta1.IdlingResource {
+	return &kidlev1beta1.IdlingResource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kidlev1beta1.GroupVersion.String(),
+			Kind:       "IdlingResource",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: kidlev1beta1.IdlingResourceSpec{
+			IdlingResourceRef: kidlev1beta1.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       workload,
+				APIVersion: "apps/v1",
+			},
+			IdlingStrategy: &kidlev1beta1.IdlingStrategy{
+				CronStrategy: &kidlev1beta1.CronStrategy{Schedule: "0 20 * * 1-5"},
+			},
+			WakeupStrategy: &kidlev1beta1.WakeupStrategy{
+				CronStrategy: &kidlev1beta1.CronStrategy{Schedule: "30 7 * * 1-5", TimeZone: "Europe/Paris"},
+			},
+		},
+	}
+}
+
+var _ = Describe("IdlingResourceValidator", func() {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(kidlev1beta1.AddToScheme(scheme)).To(Succeed())
+	decoder, err := admission.NewDecoder(scheme)
+	Expect(err).ToNot(HaveOccurred())
+
+	dependent := newIdlingResource("worker", "worker")
+	dependent.Spec.DependsOn = []string{"existing", "invalid"}
+	validator := &IdlingResourceValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(newIdlingResource("existing", "front"), dependent).Build(),
+	}
+	Expect(validator.InjectDecoder(decoder)).To(Succeed())
+
+	handle := func(ir *kidlev1beta1.IdlingResource) admission.Response {
+		raw, err := json.Marshal(ir)
+		Expect(err).ToNot(HaveOccurred())
+		return validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Name:      ir.Name,
+			Namespace: ir.Namespace,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	It("allows a valid IdlingResource", func() {
+		Expect(handle(newIdlingResource("valid", "back")).Allowed).To(BeTrue())
+	})
+
+	It("allows an IdlingResource with dependencies", func() {
+		ir := newIdlingResource("valid", "back")
+		ir.Spec.DependsOn = []string{"existing", "worker"}
+		Expect(handle(ir).Allowed).To(BeTrue())
+	})
+
+	It("allows an IdlingResource referencing a custom resource", func() {
+		ir := newIdlingResource("valid", "back")
+		ir.Spec.IdlingResourceRef.APIVersion = "argoproj.io/v1alpha1"
+		ir.Spec.IdlingResourceRef.Kind = "Rollout"
+		Expect(handle(ir).Allowed).To(BeTrue())
+	})
+
+	It("allows the update of an IdlingResource", func() {
+		Expect(handle(newIdlingResource("existing", "front")).Allowed).To(BeTrue())
+	})
+
+	It("requires the kind of the workload", func() {
+		ir := newIdlingResource("kindless", "back")
+		ir.Spec.IdlingResourceRef.Kind = ""
+		response := handle(ir)
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Details.Causes).To(ContainElement(metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: "Required value: the kind of the workload is required",
+			Field:   "spec.idlingResourceRef.kind",
+		}))
+	})
+
+	DescribeTable("rejects an invalid IdlingResource",
+		func(mutate func(ir *kidlev1beta1.IdlingResource), field string) {
+			ir := newIdlingResource("invalid", "back")
+			mutate(ir)
+			response := handle(ir)
+			Expect(response.Allowed).To(BeFalse())
+			var fields []string
+			for _, cause := range response.Result.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ContainElement(field))
+		},
+		Entry("with an unsupported kind", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.IdlingResourceRef.Kind = "Deploymnet"
+		}, "spec.idlingResourceRef.kind"),
+		Entry("with a malformed apiVersion", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.IdlingResourceRef.APIVersion = "argoproj.io/v1alpha1/rollouts"
+		}, "spec.idlingResourceRef.apiVersion"),
+		Entry("with an empty name", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.IdlingResourceRef.Name = ""
+		}, "spec.idlingResourceRef.name"),
+		Entry("with an invalid name", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.IdlingResourceRef.Name = "Back_End"
+		}, "spec.idlingResourceRef.name"),
+		Entry("with a malformed idling schedule", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.IdlingStrategy.CronStrategy.Schedule = "0 20 * *"
+		}, "spec.idlingStrategy.cronStrategy.schedule"),
+		Entry("with a malformed wakeup schedule", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.WakeupStrategy.CronStrategy.Schedule = "60 7 * * 1-5"
+		}, "spec.wakeupStrategy.cronStrategy.schedule"),
+		Entry("with an unknown time zone", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.WakeupStrategy.CronStrategy.TimeZone = "Paris"
+		}, "spec.wakeupStrategy.cronStrategy.timeZone"),
+		Entry("with a workload already referenced", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.IdlingResourceRef.Name = "front"
+		}, "spec.idlingResourceRef"),
+		Entry("with an invalid dependency", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.DependsOn = []string{"Postgres_DB"}
+		}, "spec.dependsOn[0]"),
+		Entry("with a dependency on itself", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.DependsOn = []string{"invalid"}
+		}, "spec.dependsOn[0]"),
+		Entry("with a duplicated dependency", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.DependsOn = []string{"existing", "existing"}
+		}, "spec.dependsOn[1]"),
+		Entry("with a circular dependency", func(ir *kidlev1beta1.IdlingResource) {
+			ir.Spec.DependsOn = []string{"existing", "worker"}
+		}, "spec.dependsOn[1]"),
+	)
+})